@@ -5,12 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"open-sandbox/internal/api/handlers"
 	"open-sandbox/internal/browser"
 	"open-sandbox/internal/config"
 	"open-sandbox/internal/mcp"
 	"open-sandbox/internal/mcp/remote"
+	"open-sandbox/internal/mcp/tools"
 )
 
 func main() {
@@ -31,6 +34,13 @@ func main() {
 	browserConfig.ExistingWebSocketDebug = os.Getenv("SANDBOX_BROWSER_CDP")
 	browserConfig.Headless = getenvBool("SANDBOX_BROWSER_HEADLESS", browserConfig.Headless)
 	browserConfig.DownloadDir = getenv("SANDBOX_BROWSER_DOWNLOAD_DIR", filepath.Join(config.WorkspacePath(), "Downloads"))
+	browserConfig.RecordingDir = getenv("SANDBOX_BROWSER_RECORDING_DIR", filepath.Join(config.WorkspacePath(), "Recordings"))
+	browserConfig.FFmpegPath = os.Getenv("SANDBOX_FFMPEG_BIN")
+	browserConfig.DialogPolicy = getenv("SANDBOX_BROWSER_DIALOG_POLICY", browser.DialogPolicyAccept)
+	browserConfig.LogDir = config.LogsPath()
+	browserConfig.ExtraArgs = strings.Fields(os.Getenv("SANDBOX_BROWSER_ARGS"))
+	browserConfig.PoolMaxInstances = getenvInt("SANDBOX_BROWSER_POOL_MAX", browser.DefaultPoolMaxInstances)
+	browserConfig.PoolIdleTimeout = time.Duration(getenvInt("SANDBOX_BROWSER_POOL_IDLE_SEC", int(browser.DefaultPoolIdleTimeout/time.Second))) * time.Second
 
 	browserService := browser.NewService(browserConfig)
 	remoteManager, err := remote.NewManager(config.MCPServersPath())
@@ -39,6 +49,7 @@ func main() {
 	}
 	registry := handlers.NewMCPRegistry(browserService, remoteManager)
 	server := mcp.NewServer(registry, nil, nil)
+	server.SetResourceWatcher(tools.FileResourceWatcher())
 
 	if err := server.ServeStdio(os.Stdin, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "stdio server failed: %v\n", err)
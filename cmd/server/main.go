@@ -42,11 +42,22 @@ func main() {
 		ScreenshotTimeout:      getenvDurationSeconds("SANDBOX_BROWSER_SCREENSHOT_TIMEOUT_SEC", 15*time.Second),
 		Headless:               getenvBool("SANDBOX_BROWSER_HEADLESS", false),
 		DownloadDir:            getenv("SANDBOX_BROWSER_DOWNLOAD_DIR", filepath.Join(config.WorkspacePath(), "Downloads")),
+		RecordingDir:           getenv("SANDBOX_BROWSER_RECORDING_DIR", filepath.Join(config.WorkspacePath(), "Recordings")),
+		ScriptDir:              getenv("SANDBOX_BROWSER_SCRIPT_DIR", filepath.Join(config.WorkspacePath(), "Scripts")),
+		FFmpegPath:             os.Getenv("SANDBOX_FFMPEG_BIN"),
+		ContextIdleTimeout:     getenvDurationSeconds("SANDBOX_BROWSER_CONTEXT_IDLE_SEC", browser.DefaultContextIdleTimeout),
+		DialogPolicy:           getenv("SANDBOX_BROWSER_DIALOG_POLICY", browser.DialogPolicyAccept),
+		PoolMaxInstances:       getenvInt("SANDBOX_BROWSER_POOL_MAX", browser.DefaultPoolMaxInstances),
+		PoolIdleTimeout:        getenvDurationSeconds("SANDBOX_BROWSER_POOL_IDLE_SEC", browser.DefaultPoolIdleTimeout),
+		LogDir:                 config.LogsPath(),
+		ExtraArgs:              strings.Fields(os.Getenv("SANDBOX_BROWSER_ARGS")),
 	})
 	handlers.RegisterBrowserRoutes(router, browserService)
 	handlers.RegisterVNCRoutes(router, browserService)
 	handlers.RegisterShellRoutes(router)
 	handlers.RegisterFileRoutes(router)
+	handlers.RegisterWorkspaceRoutes(router)
+	handlers.RegisterGitRoutes(router)
 	handlers.RegisterCodeExecRoutes(router)
 	handlers.RegisterJupyterRoutes(router, os.Getenv("SANDBOX_JUPYTER_URL"))
 	handlers.RegisterCodeServerRoutes(router, os.Getenv("SANDBOX_CODESERVER_URL"))
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
@@ -17,17 +19,51 @@ import (
 	"open-sandbox/pkg/types"
 )
 
+// navigateRequest returns once the navigation commits unless wait_until
+// asks for domcontentloaded, load or networkidle.
 type navigateRequest struct {
-	URL string `json:"url"`
+	URL       string `json:"url"`
+	WaitUntil string `json:"wait_until"`
+	TimeoutMS int    `json:"timeout_ms"`
 }
 
+// screenshotRequest captures the viewport unless full_page, clip or an
+// element locator is given. Without a path the image is returned inline.
+// Marks numbers the interactive elements for later element actions.
 type screenshotRequest struct {
-	Path string `json:"path"`
+	browser.Locator
+	Path     string            `json:"path"`
+	Format   string            `json:"format"`
+	Quality  int               `json:"quality"`
+	FullPage bool              `json:"full_page"`
+	Clip     *browser.ClipRect `json:"clip"`
+	Scale    float64           `json:"scale"`
+	Marks    bool              `json:"marks"`
+	Inline   bool              `json:"inline"`
+}
+
+// pdfRequest lengths are in inches; format names a paper size such as a4.
+type pdfRequest struct {
+	Path              string             `json:"path"`
+	Format            string             `json:"format"`
+	Width             float64            `json:"width"`
+	Height            float64            `json:"height"`
+	Margin            *browser.PDFMargin `json:"margin"`
+	Landscape         bool               `json:"landscape"`
+	PageRanges        string             `json:"page_ranges"`
+	HeaderTemplate    string             `json:"header_template"`
+	FooterTemplate    string             `json:"footer_template"`
+	PrintBackground   bool               `json:"print_background"`
+	Scale             float64            `json:"scale"`
+	PreferCSSPageSize bool               `json:"prefer_css_page_size"`
 }
 
 type clickRequest struct {
 	X float64 `json:"x"`
 	Y float64 `json:"y"`
+	browser.Locator
+	Button     string `json:"button"`
+	ClickCount int    `json:"click_count"`
 }
 
 type formInputFillRequest struct {
@@ -53,12 +89,20 @@ type tabNewRequest struct {
 	URL string `json:"url"`
 }
 
-type tabSwitchRequest struct {
-	Index int `json:"index"`
+// tabRequest selects a tab by id, or by index for older clients.
+type tabRequest struct {
+	ID    browser.TabRef `json:"id"`
+	Index *int           `json:"index"`
 }
 
-type tabCloseRequest struct {
-	Index int `json:"index"`
+func (req tabRequest) ref() (browser.TabRef, *api.AppError) {
+	switch {
+	case req.ID != "":
+		return req.ID, nil
+	case req.Index != nil:
+		return browser.TabAt(*req.Index), nil
+	}
+	return "", api.NewAppError("bad_request", "id or index is required", http.StatusBadRequest)
 }
 
 type pressKeyRequest struct {
@@ -73,10 +117,13 @@ type actionEnvelope struct {
 	ActionType string `json:"action_type"`
 }
 
+// Element in moveToAction and dragToAction targets the center of a
+// numbered element from a marked screenshot instead of x and y.
 type moveToAction struct {
 	ActionType string  `json:"action_type"`
 	X          float64 `json:"x"`
 	Y          float64 `json:"y"`
+	Element    int     `json:"element"`
 }
 
 type moveRelAction struct {
@@ -91,6 +138,7 @@ type clickAction struct {
 	Y          *float64 `json:"y"`
 	Button     string   `json:"button"`
 	NumClicks  int      `json:"num_clicks"`
+	browser.Locator
 }
 
 type mouseButtonAction struct {
@@ -102,18 +150,21 @@ type rightClickAction struct {
 	ActionType string   `json:"action_type"`
 	X          *float64 `json:"x"`
 	Y          *float64 `json:"y"`
+	browser.Locator
 }
 
 type doubleClickAction struct {
 	ActionType string   `json:"action_type"`
 	X          *float64 `json:"x"`
 	Y          *float64 `json:"y"`
+	browser.Locator
 }
 
 type dragToAction struct {
 	ActionType string  `json:"action_type"`
 	X          float64 `json:"x"`
 	Y          float64 `json:"y"`
+	Element    int     `json:"element"`
 }
 
 type dragRelAction struct {
@@ -128,10 +179,19 @@ type scrollAction struct {
 	DY         int    `json:"dy"`
 }
 
+// typingAction's Text shadows Locator.Text, so typing into an element is
+// targeted by selector, xpath or role only.
 type typingAction struct {
 	ActionType   string `json:"action_type"`
 	Text         string `json:"text"`
 	UseClipboard *bool  `json:"use_clipboard"`
+	Clear        bool   `json:"clear"`
+	browser.Locator
+}
+
+type elementAction struct {
+	ActionType string `json:"action_type"`
+	browser.Locator
 }
 
 type pressAction struct {
@@ -155,22 +215,46 @@ type waitAction struct {
 }
 
 func RegisterBrowserRoutes(router *api.Router, service *browser.Service) {
-	router.Handle(http.MethodGet, "/v1/browser/info", BrowserInfoHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/navigate", BrowserNavigateHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/screenshot", BrowserScreenshotHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/click", BrowserClickHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/form_input_fill", BrowserFormInputFillHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/select", BrowserSelectHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/scroll", BrowserScrollHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/evaluate", BrowserEvaluateHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/new_tab", BrowserNewTabHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/switch_tab", BrowserSwitchTabHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/close_tab", BrowserCloseTabHandler(service))
-	router.Handle(http.MethodGet, "/v1/browser/tab_list", BrowserTabListHandler(service))
-	router.Handle(http.MethodGet, "/v1/browser/get_download_list", BrowserDownloadListHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/press_key", BrowserPressKeyHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/actions", BrowserActionsHandler(service))
-	router.Handle(http.MethodPost, "/v1/browser/config", BrowserConfigHandler(service))
+	router.Handle(http.MethodGet, "/v1/browser/info", inBrowserContext(service, BrowserInfoHandler))
+	router.Handle(http.MethodGet, "/v1/browser/status", inBrowser(service, BrowserStatusHandler))
+	registerBrowserPoolRoutes(router, service)
+	router.Handle(http.MethodPost, "/v1/browser/navigate", inBrowserContext(service, BrowserNavigateHandler))
+	router.Handle(http.MethodPost, "/v1/browser/screenshot", inBrowserContext(service, BrowserScreenshotHandler))
+	router.Handle(http.MethodPost, "/v1/browser/pdf", inBrowserContext(service, BrowserPDFHandler))
+	router.Handle(http.MethodPost, "/v1/browser/click", inBrowserContext(service, BrowserClickHandler))
+	router.Handle(http.MethodPost, "/v1/browser/form_input_fill", inBrowserContext(service, BrowserFormInputFillHandler))
+	router.Handle(http.MethodPost, "/v1/browser/select", inBrowserContext(service, BrowserSelectHandler))
+	router.Handle(http.MethodPost, "/v1/browser/scroll", inBrowserContext(service, BrowserScrollHandler))
+	router.Handle(http.MethodPost, "/v1/browser/evaluate", inBrowserContext(service, BrowserEvaluateHandler))
+	router.Handle(http.MethodPost, "/v1/browser/new_tab", inBrowserContext(service, BrowserNewTabHandler))
+	router.Handle(http.MethodPost, "/v1/browser/switch_tab", inBrowserContext(service, BrowserSwitchTabHandler))
+	router.Handle(http.MethodPost, "/v1/browser/close_tab", inBrowserContext(service, BrowserCloseTabHandler))
+	router.Handle(http.MethodGet, "/v1/browser/tab_list", inBrowserContext(service, BrowserTabListHandler))
+	router.Handle(http.MethodGet, "/v1/browser/events", inBrowserContext(service, BrowserEventsHandler))
+	router.Handle(http.MethodGet, "/v1/browser/get_download_list", inBrowserContext(service, BrowserDownloadListHandler))
+	router.Handle(http.MethodPost, "/v1/browser/press_key", inBrowserContext(service, BrowserPressKeyHandler))
+	router.Handle(http.MethodPost, "/v1/browser/actions", inBrowserContext(service, BrowserActionsHandler))
+	router.Handle(http.MethodPost, "/v1/browser/config", inBrowserContext(service, BrowserConfigHandler))
+	registerBrowserElementRoutes(router, service)
+	registerBrowserDevtoolsRoutes(router, service)
+	registerBrowserRouteRoutes(router, service)
+	registerBrowserStorageRoutes(router, service)
+	registerBrowserContextRoutes(router, service)
+	registerBrowserRecordingRoutes(router, service)
+	registerBrowserWaitRoutes(router, service)
+	registerBrowserDialogRoutes(router, service)
+	registerBrowserEmulationRoutes(router, service)
+	registerBrowserScriptRoutes(router, service)
+}
+
+// BrowserStatusHandler reports the browser process without starting it.
+func BrowserStatusHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(service.Status())); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
 }
 
 func BrowserInfoHandler(service *browser.Service) api.HandlerFunc {
@@ -198,6 +282,7 @@ func BrowserInfoHandler(service *browser.Service) api.HandlerFunc {
 			"vnc_url":     buildVNCURL(r),
 			"viewport":    map[string]any{"width": viewport.Width, "height": viewport.Height},
 			"cdp_address": cdpAddress,
+			"browsers":    service.Browsers(),
 		}
 		if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
@@ -224,7 +309,17 @@ func BrowserNavigateHandler(service *browser.Service) api.HandlerFunc {
 			return api.NewAppError("bad_request", "url is required", http.StatusBadRequest)
 		}
 
-		if err := service.Navigate(req.URL); err != nil {
+		// Recorded navigations wait for the page so replays do not race it.
+		action, _ := json.Marshal(map[string]string{"action_type": "NAVIGATE", "url": req.URL, "wait_until": cmp.Or(req.WaitUntil, browser.WaitUntilLoad)})
+		err := service.RecordScriptAction(action, func() error {
+			if req.WaitUntil != "" {
+				return service.NavigateWithOptions(req.URL, browser.NavigateOptions{WaitUntil: req.WaitUntil, Timeout: millis(req.TimeoutMS)})
+			}
+			return service.Navigate(req.URL)
+		})
+		if err != nil && req.WaitUntil != "" {
+			return browserError(err, "navigate_failed")
+		} else if err != nil {
 			if err == browser.ErrBrowserUnavailable {
 				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
 			}
@@ -247,6 +342,57 @@ func BrowserScreenshotHandler(service *browser.Service) api.HandlerFunc {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
+		if strings.TrimSpace(req.Path) != "" {
+			if !filepath.IsAbs(req.Path) {
+				return api.NewAppError("bad_request", "path must be absolute", http.StatusBadRequest)
+			}
+			if file.ValidateWorkspacePath(req.Path, config.WorkspacePath()) != nil &&
+				file.ValidateWorkspacePath(req.Path, config.ContainerWorkspacePath) != nil {
+				return api.NewAppError("bad_request", "path must be within workspace", http.StatusBadRequest)
+			}
+		}
+
+		result, err := service.CaptureScreenshot(browser.ScreenshotOptions{
+			Path:     req.Path,
+			Format:   req.Format,
+			Quality:  req.Quality,
+			FullPage: req.FullPage,
+			Clip:     req.Clip,
+			Element:  req.Locator,
+			Scale:    req.Scale,
+			Marks:    req.Marks,
+		})
+		if err != nil {
+			return browserError(err, "screenshot_failed")
+		}
+
+		payload := map[string]any{
+			"format":    result.Format,
+			"mime_type": result.MimeType,
+			"bytes":     result.Bytes,
+		}
+		if result.Path != "" {
+			payload["path"] = result.Path
+		}
+		if result.Marks != nil {
+			payload["marks"] = result.Marks
+		}
+		if req.Inline || result.Path == "" {
+			payload["data"] = base64.StdEncoding.EncodeToString(result.Data)
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserPDFHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req pdfRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
 		if strings.TrimSpace(req.Path) == "" {
 			return api.NewAppError("bad_request", "path is required", http.StatusBadRequest)
 		}
@@ -258,17 +404,24 @@ func BrowserScreenshotHandler(service *browser.Service) api.HandlerFunc {
 			return api.NewAppError("bad_request", "path must be within workspace", http.StatusBadRequest)
 		}
 
-		if err := service.Screenshot(req.Path); err != nil {
-			if err == browser.ErrBrowserUnavailable {
-				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
-			}
-			return api.NewAppError(api.CodeInternalError, "screenshot failed", http.StatusInternalServerError)
-		}
-
-		payload := map[string]string{
-			"path": req.Path,
+		result, err := service.PrintToPDF(browser.PDFOptions{
+			Path:              req.Path,
+			Format:            req.Format,
+			Width:             req.Width,
+			Height:            req.Height,
+			Margin:            req.Margin,
+			Landscape:         req.Landscape,
+			PageRanges:        req.PageRanges,
+			HeaderTemplate:    req.HeaderTemplate,
+			FooterTemplate:    req.FooterTemplate,
+			PrintBackground:   req.PrintBackground,
+			Scale:             req.Scale,
+			PreferCSSPageSize: req.PreferCSSPageSize,
+		})
+		if err != nil {
+			return browserError(err, "pdf_failed")
 		}
-		if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(result)); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
 		}
 		return nil
@@ -281,7 +434,12 @@ func BrowserClickHandler(service *browser.Service) api.HandlerFunc {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		if err := service.Click(req.X, req.Y); err != nil {
+		button := normalizeButton(req.Button)
+		if !req.Locator.IsZero() {
+			if err := service.ClickElement(req.Locator, browser.ClickOptions{Button: button, Count: req.ClickCount}); err != nil {
+				return browserError(err, "click_failed")
+			}
+		} else if err := service.ClickAt(req.X, req.Y, button, req.ClickCount); err != nil {
 			if err == browser.ErrBrowserUnavailable {
 				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
 			}
@@ -386,14 +544,14 @@ func BrowserNewTabHandler(service *browser.Service) api.HandlerFunc {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		index, err := service.NewTab(req.URL)
+		tab, err := service.NewTab(req.URL)
 		if err != nil {
 			if err == browser.ErrBrowserUnavailable {
 				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
 			}
 			return api.NewAppError("tab_new_failed", err.Error(), http.StatusInternalServerError)
 		}
-		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"index": index})); err != nil {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"id": tab.ID, "index": tab.Index})); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
 		}
 		return nil
@@ -402,17 +560,19 @@ func BrowserNewTabHandler(service *browser.Service) api.HandlerFunc {
 
 func BrowserSwitchTabHandler(service *browser.Service) api.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
-		var req tabSwitchRequest
+		var req tabRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		if err := service.SwitchTab(req.Index); err != nil {
-			if err == browser.ErrBrowserUnavailable {
-				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
-			}
-			return api.NewAppError("tab_switch_failed", err.Error(), http.StatusInternalServerError)
+		ref, appErr := req.ref()
+		if appErr != nil {
+			return appErr
+		}
+		tab, err := service.SwitchTab(ref)
+		if err != nil {
+			return browserError(err, "tab_switch_failed")
 		}
-		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"index": req.Index})); err != nil {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"id": tab.ID, "index": tab.Index})); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
 		}
 		return nil
@@ -421,15 +581,16 @@ func BrowserSwitchTabHandler(service *browser.Service) api.HandlerFunc {
 
 func BrowserCloseTabHandler(service *browser.Service) api.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
-		var req tabCloseRequest
+		var req tabRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		if err := service.CloseTab(req.Index); err != nil {
-			if err == browser.ErrBrowserUnavailable {
-				return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
-			}
-			return api.NewAppError("tab_close_failed", err.Error(), http.StatusInternalServerError)
+		ref, appErr := req.ref()
+		if appErr != nil {
+			return appErr
+		}
+		if err := service.CloseTab(ref); err != nil {
+			return browserError(err, "tab_close_failed")
 		}
 		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"closed": true})); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
@@ -504,7 +665,7 @@ func BrowserActionsHandler(service *browser.Service) api.HandlerFunc {
 			}
 			performed := make([]string, 0, len(items))
 			for _, item := range items {
-				actionName, err := executeAction(service, item)
+				actionName, err := recordAction(service, item)
 				if err != nil {
 					return api.NewAppError("action_failed", err.Error(), http.StatusInternalServerError)
 				}
@@ -521,7 +682,7 @@ func BrowserActionsHandler(service *browser.Service) api.HandlerFunc {
 			return nil
 		}
 
-		actionName, err := executeAction(service, body)
+		actionName, err := recordAction(service, body)
 		if err != nil {
 			return api.NewAppError("action_failed", err.Error(), http.StatusInternalServerError)
 		}
@@ -554,17 +715,55 @@ func BrowserConfigHandler(service *browser.Service) api.HandlerFunc {
 	}
 }
 
+// recordAction runs an action and adds it to the context's script
+// recording, if one is running.
+func recordAction(service *browser.Service, raw json.RawMessage) (string, error) {
+	var actionName string
+	err := service.RecordScriptAction(raw, func() error {
+		var err error
+		actionName, err = executeAction(service, raw)
+		return err
+	})
+	return actionName, err
+}
+
 func executeAction(service *browser.Service, raw json.RawMessage) (string, error) {
 	var envelope actionEnvelope
 	if err := json.Unmarshal(raw, &envelope); err != nil {
 		return "", err
 	}
 	switch envelope.ActionType {
+	case "NAVIGATE":
+		var payload navigateRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(payload.URL) == "" {
+			return "", errors.New("url is required")
+		}
+		options := browser.NavigateOptions{WaitUntil: payload.WaitUntil, Timeout: millis(payload.TimeoutMS)}
+		return envelope.ActionType, service.NavigateWithOptions(payload.URL, options)
+	case "SELECT":
+		var payload elementSelectRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(payload.Selector) == "" {
+			return "", errors.New("selector is required")
+		}
+		return envelope.ActionType, service.ElementSelect(payload.Selector, payload.Value)
 	case "MOVE_TO":
 		var payload moveToAction
 		if err := json.Unmarshal(raw, &payload); err != nil {
 			return "", err
 		}
+		if payload.Element != 0 {
+			x, y, err := service.ElementCenter(browser.Locator{Element: payload.Element})
+			if err != nil {
+				return "", err
+			}
+			payload.X, payload.Y = x, y
+		}
 		return envelope.ActionType, service.MoveTo(payload.X, payload.Y)
 	case "MOVE_REL":
 		var payload moveRelAction
@@ -579,6 +778,9 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 		}
 		button := normalizeButton(payload.Button)
 		count := payload.NumClicks
+		if !payload.Locator.IsZero() {
+			return envelope.ActionType, service.ClickElement(payload.Locator, browser.ClickOptions{Button: button, Count: count})
+		}
 		x, y, ok := actionCoords(service, payload.X, payload.Y)
 		if !ok {
 			return "", errors.New("mouse position unknown")
@@ -601,6 +803,9 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 		if err := json.Unmarshal(raw, &payload); err != nil {
 			return "", err
 		}
+		if !payload.Locator.IsZero() {
+			return envelope.ActionType, service.ClickElement(payload.Locator, browser.ClickOptions{Button: "right"})
+		}
 		x, y, ok := actionCoords(service, payload.X, payload.Y)
 		if !ok {
 			return "", errors.New("mouse position unknown")
@@ -611,6 +816,9 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 		if err := json.Unmarshal(raw, &payload); err != nil {
 			return "", err
 		}
+		if !payload.Locator.IsZero() {
+			return envelope.ActionType, service.ClickElement(payload.Locator, browser.ClickOptions{Count: 2})
+		}
 		x, y, ok := actionCoords(service, payload.X, payload.Y)
 		if !ok {
 			return "", errors.New("mouse position unknown")
@@ -621,6 +829,13 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 		if err := json.Unmarshal(raw, &payload); err != nil {
 			return "", err
 		}
+		if payload.Element != 0 {
+			x, y, err := service.ElementCenter(browser.Locator{Element: payload.Element})
+			if err != nil {
+				return "", err
+			}
+			payload.X, payload.Y = x, y
+		}
 		return envelope.ActionType, service.DragTo(payload.X, payload.Y)
 	case "DRAG_REL":
 		var payload dragRelAction
@@ -642,6 +857,9 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 		if strings.TrimSpace(payload.Text) == "" {
 			return "", errors.New("text is required")
 		}
+		if !payload.Locator.IsZero() {
+			return envelope.ActionType, service.TypeElement(payload.Locator, payload.Text, payload.Clear)
+		}
 		return envelope.ActionType, service.PressKey(payload.Text)
 	case "PRESS":
 		var payload pressAction
@@ -679,6 +897,47 @@ func executeAction(service *browser.Service, raw json.RawMessage) (string, error
 			return "", errors.New("keys are required")
 		}
 		return envelope.ActionType, service.Hotkey(payload.Keys)
+	case "HOVER", "FOCUS", "CHECK", "UNCHECK", "SCROLL_INTO_VIEW":
+		var payload elementAction
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		switch envelope.ActionType {
+		case "HOVER":
+			return envelope.ActionType, service.HoverElement(payload.Locator)
+		case "FOCUS":
+			return envelope.ActionType, service.FocusElement(payload.Locator)
+		case "CHECK", "UNCHECK":
+			return envelope.ActionType, service.CheckElement(payload.Locator, envelope.ActionType == "CHECK")
+		default:
+			return envelope.ActionType, service.ScrollIntoView(payload.Locator)
+		}
+	case "WAIT_FOR_SELECTOR":
+		var payload waitForSelectorRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		return envelope.ActionType, service.WaitForSelector(payload.Locator, payload.State)
+	case "WAIT_FOR_URL":
+		var payload waitForURLRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		_, err := service.WaitForURL(payload.URL, millis(payload.TimeoutMS))
+		return envelope.ActionType, err
+	case "WAIT_FOR_FUNCTION":
+		var payload waitForFunctionRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		_, err := service.WaitForFunction(payload.Expression, millis(payload.TimeoutMS))
+		return envelope.ActionType, err
+	case "WAIT_FOR_LOAD_STATE":
+		var payload waitForLoadStateRequest
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", err
+		}
+		return envelope.ActionType, service.WaitForLoadState(payload.State, millis(payload.TimeoutMS))
 	case "WAIT":
 		var payload waitAction
 		if err := json.Unmarshal(raw, &payload); err != nil {
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+const (
+	browserContextHeader = "X-Browser-Context"
+	browserIDHeader      = "X-Browser-Id"
+)
+
+type browserContextCreateRequest struct {
+	Name               string `json:"name"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+}
+
+func registerBrowserContextRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/contexts", inBrowser(service, BrowserContextListHandler))
+	router.Handle(http.MethodPost, "/v1/browser/contexts", inBrowser(service, BrowserContextCreateHandler))
+	router.HandlePrefix(http.MethodDelete, "/v1/browser/contexts/", inBrowser(service, BrowserContextDeleteHandler))
+}
+
+// inBrowserContext runs the handler built by factory against the browser
+// context the request names, or the default context when it names none.
+func inBrowserContext(service *browser.Service, factory func(*browser.Service) api.HandlerFunc) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		browserID, name, appErr := browserTarget(r)
+		if appErr != nil {
+			return appErr
+		}
+		instance, err := service.Browser(browserID)
+		if err != nil {
+			return browserError(err, "browser_failed")
+		}
+		scoped, err := instance.Context(name)
+		if err != nil {
+			return browserError(err, "context_failed")
+		}
+		return factory(scoped)(w, r)
+	}
+}
+
+// inBrowser is inBrowserContext for handlers that act on a whole browser,
+// such as its routes and contexts, so only the browser is resolved.
+func inBrowser(service *browser.Service, factory func(*browser.Service) api.HandlerFunc) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		browserID, _, appErr := browserTarget(r)
+		if appErr != nil {
+			return appErr
+		}
+		instance, err := service.Browser(browserID)
+		if err != nil {
+			return browserError(err, "browser_failed")
+		}
+		return factory(instance)(w, r)
+	}
+}
+
+// browserTarget reads the browser and context from the query string, the
+// X-Browser-Id and X-Browser-Context headers or "browser_id" and "context"
+// fields of a JSON body, in that order. The body is restored for the
+// handler.
+func browserTarget(r *http.Request) (string, string, *api.AppError) {
+	query := r.URL.Query()
+	browserID := query.Get("browser_id")
+	if browserID == "" {
+		browserID = r.Header.Get(browserIDHeader)
+	}
+	name := query.Get("context")
+	if name == "" {
+		name = r.Header.Get(browserContextHeader)
+	}
+	if (browserID != "" && name != "") || r.Body == nil || r.Method == http.MethodGet {
+		return browserID, name, nil
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", "", api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	var envelope struct {
+		BrowserID string `json:"browser_id"`
+		Context   string `json:"context"`
+	}
+	if json.Unmarshal(data, &envelope) != nil {
+		return browserID, name, nil
+	}
+	if browserID == "" {
+		browserID = envelope.BrowserID
+	}
+	if name == "" {
+		name = envelope.Context
+	}
+	return browserID, name, nil
+}
+
+// browserError maps the errors of the browser service to responses. Every
+// browser route uses it; code is the error code for anything unexpected.
+func browserError(err error, code string) *api.AppError {
+	switch {
+	case errors.Is(err, browser.ErrBrowserUnavailable):
+		return api.NewAppError("browser_unavailable", "browser binary not found", http.StatusServiceUnavailable)
+	case errors.Is(err, browser.ErrInvalidLocator), errors.Is(err, browser.ErrInvalidOption):
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	case errors.Is(err, browser.ErrContextNotFound), errors.Is(err, browser.ErrTabNotFound), errors.Is(err, browser.ErrNoDialog),
+		errors.Is(err, browser.ErrBrowserNotFound), errors.Is(err, browser.ErrScriptNotFound):
+		return api.NewAppError("not_found", err.Error(), http.StatusNotFound)
+	case errors.Is(err, browser.ErrWaitTimeout):
+		return api.NewAppError("wait_timeout", err.Error(), http.StatusRequestTimeout)
+	case errors.Is(err, browser.ErrDialogOpen):
+		return api.NewAppError("dialog_open", err.Error(), http.StatusConflict)
+	case errors.Is(err, browser.ErrPoolExhausted):
+		return api.NewAppError("pool_exhausted", err.Error(), http.StatusTooManyRequests)
+	}
+	return api.NewAppError(code, err.Error(), http.StatusInternalServerError)
+}
+
+func BrowserContextListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"contexts": service.Contexts()})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserContextCreateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req browserContextCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return api.NewAppError("bad_request", "name is required", http.StatusBadRequest)
+		}
+		if req.IdleTimeoutSeconds < 0 {
+			return api.NewAppError("bad_request", "invalid idle_timeout_seconds", http.StatusBadRequest)
+		}
+		info, err := service.CreateContext(browser.ContextOptions{
+			Name:        req.Name,
+			IdleTimeout: time.Duration(req.IdleTimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return browserError(err, "context_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(info)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserContextDeleteHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		name := strings.TrimPrefix(r.URL.Path, "/v1/browser/contexts/")
+		if name == "" || strings.Contains(name, "/") {
+			return api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+		}
+		if err := service.CloseContext(name); err != nil {
+			return browserError(err, "context_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"closed": name})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
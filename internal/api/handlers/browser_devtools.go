@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/pkg/types"
+)
+
+type harExportRequest struct {
+	Path          string         `json:"path"`
+	Tab           browser.TabRef `json:"tab"`
+	IncludeBodies bool           `json:"include_bodies"`
+}
+
+func registerBrowserDevtoolsRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/console", inBrowserContext(service, BrowserConsoleHandler))
+	router.Handle(http.MethodGet, "/v1/browser/network", inBrowserContext(service, BrowserNetworkHandler))
+	router.Handle(http.MethodGet, "/v1/browser/network/body", inBrowserContext(service, BrowserResponseBodyHandler))
+	router.Handle(http.MethodPost, "/v1/browser/network/har", inBrowserContext(service, BrowserHARHandler))
+}
+
+// tabQueryParam reads the optional tab id (or index) shared by the
+// devtools endpoints.
+func tabQueryParam(r *http.Request) browser.TabRef {
+	return browser.TabRef(r.URL.Query().Get("tab"))
+}
+
+// cursorQueryParams parses since and limit for cursor-paged logs.
+func cursorQueryParams(r *http.Request) (uint64, int, *api.AppError) {
+	query := r.URL.Query()
+	var since uint64
+	var limit int
+	if raw := query.Get("since"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return 0, 0, api.NewAppError("bad_request", "invalid since", http.StatusBadRequest)
+		}
+		since = value
+	}
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return 0, 0, api.NewAppError("bad_request", "invalid limit", http.StatusBadRequest)
+		}
+		limit = value
+	}
+	return since, limit, nil
+}
+
+func BrowserConsoleHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		tab := tabQueryParam(r)
+		since, limit, appErr := cursorQueryParams(r)
+		if appErr != nil {
+			return appErr
+		}
+		logs, err := service.ConsoleLogs(browser.ConsoleQuery{
+			Tab:   tab,
+			Level: r.URL.Query().Get("level"),
+			Since: since,
+			Limit: limit,
+		})
+		if err != nil {
+			return browserError(err, "console_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(logs)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserNetworkHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		tab := tabQueryParam(r)
+		since, limit, appErr := cursorQueryParams(r)
+		if appErr != nil {
+			return appErr
+		}
+		query := r.URL.Query()
+		requests, err := service.NetworkRequests(browser.NetworkQuery{
+			Tab:          tab,
+			URL:          query.Get("url"),
+			Method:       query.Get("method"),
+			ResourceType: query.Get("type"),
+			Failed:       query.Get("failed") == "true" || query.Get("failed") == "1",
+			Since:        since,
+			Limit:        limit,
+		})
+		if err != nil {
+			return browserError(err, "network_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(requests)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserResponseBodyHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		tab := tabQueryParam(r)
+		body, err := service.ResponseBody(r.URL.Query().Get("request_id"), tab)
+		if err != nil {
+			return browserError(err, "body_unavailable")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(body)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserHARHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req harExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if strings.TrimSpace(req.Path) == "" {
+			return api.NewAppError("bad_request", "path is required", http.StatusBadRequest)
+		}
+		if !filepath.IsAbs(req.Path) {
+			return api.NewAppError("bad_request", "path must be absolute", http.StatusBadRequest)
+		}
+		if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
+			return api.NewAppError("bad_request", "path must be within workspace", http.StatusBadRequest)
+		}
+		result, err := service.ExportHAR(browser.HARExportOptions{
+			Path:          req.Path,
+			Tab:           req.Tab,
+			IncludeBodies: req.IncludeBodies,
+		})
+		if err != nil {
+			return browserError(err, "har_export_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(result)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
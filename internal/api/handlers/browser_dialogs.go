@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+type dialogPolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+// dialogHandleRequest accepts the dialog unless accept is false.
+type dialogHandleRequest struct {
+	Accept     *bool          `json:"accept"`
+	PromptText string         `json:"prompt_text"`
+	Tab        browser.TabRef `json:"tab"`
+}
+
+func registerBrowserDialogRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/dialogs", inBrowserContext(service, BrowserDialogListHandler))
+	router.Handle(http.MethodPost, "/v1/browser/dialogs/policy", inBrowserContext(service, BrowserDialogPolicyHandler))
+	router.Handle(http.MethodPost, "/v1/browser/dialogs/handle", inBrowserContext(service, BrowserHandleDialogHandler))
+}
+
+func BrowserDialogListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		payload := map[string]any{"policy": service.DialogPolicy(), "dialogs": service.Dialogs()}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserDialogPolicyHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req dialogPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.SetDialogPolicy(req.Policy); err != nil {
+			return browserError(err, "dialog_policy_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"policy": req.Policy})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserHandleDialogHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req dialogHandleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		accept := req.Accept == nil || *req.Accept
+		dialog, err := service.HandleDialog(req.Tab, accept, req.PromptText)
+		if err != nil {
+			return browserError(err, "dialog_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(dialog)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
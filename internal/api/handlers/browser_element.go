@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/pkg/types"
+)
+
+type elementRequest struct {
+	browser.Locator
+}
+
+type elementTypeRequest struct {
+	browser.Locator
+	Value string `json:"value"`
+	Clear bool   `json:"clear"`
+}
+
+type elementCheckRequest struct {
+	browser.Locator
+	Checked *bool `json:"checked"`
+}
+
+type elementUploadRequest struct {
+	browser.Locator
+	Paths []string `json:"paths"`
+}
+
+func registerBrowserElementRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/snapshot", inBrowserContext(service, BrowserSnapshotHandler))
+	router.Handle(http.MethodGet, "/v1/browser/content", inBrowserContext(service, BrowserContentHandler))
+	router.Handle(http.MethodPost, "/v1/browser/hover", inBrowserContext(service, BrowserHoverHandler))
+	router.Handle(http.MethodPost, "/v1/browser/type", inBrowserContext(service, BrowserTypeHandler))
+	router.Handle(http.MethodPost, "/v1/browser/focus", inBrowserContext(service, BrowserFocusHandler))
+	router.Handle(http.MethodPost, "/v1/browser/check", inBrowserContext(service, BrowserCheckHandler))
+	router.Handle(http.MethodPost, "/v1/browser/scroll_into_view", inBrowserContext(service, BrowserScrollIntoViewHandler))
+	router.Handle(http.MethodPost, "/v1/browser/upload_file", inBrowserContext(service, BrowserUploadFileHandler))
+}
+
+func BrowserSnapshotHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		options := browser.SnapshotOptions{InteractiveOnly: r.URL.Query().Get("interactive") == "true"}
+		snapshot, err := service.Snapshot(options)
+		if err != nil {
+			return browserError(err, "snapshot_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(snapshot)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserContentHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		query := r.URL.Query()
+		options := browser.ContentOptions{
+			Format:   query.Get("format"),
+			Selector: query.Get("selector"),
+		}
+		if raw := query.Get("max_chars"); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				return api.NewAppError("bad_request", "invalid max_chars", http.StatusBadRequest)
+			}
+			options.MaxChars = value
+		}
+		content, err := service.Content(options)
+		if err != nil {
+			return browserError(err, "content_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(content)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserHoverHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.HoverElement(req.Locator); err != nil {
+			return browserError(err, "hover_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"hovered": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserTypeHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementTypeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.TypeElement(req.Locator, req.Value, req.Clear); err != nil {
+			return browserError(err, "type_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"typed": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserFocusHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.FocusElement(req.Locator); err != nil {
+			return browserError(err, "focus_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"focused": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserCheckHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementCheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		checked := req.Checked == nil || *req.Checked
+		if err := service.CheckElement(req.Locator, checked); err != nil {
+			return browserError(err, "check_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"checked": checked})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScrollIntoViewHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.ScrollIntoView(req.Locator); err != nil {
+			return browserError(err, "scroll_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"scrolled": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserUploadFileHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req elementUploadRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if len(req.Paths) == 0 {
+			return api.NewAppError("bad_request", "paths is required", http.StatusBadRequest)
+		}
+		for _, path := range req.Paths {
+			if !filepath.IsAbs(path) {
+				return api.NewAppError("bad_request", "paths must be absolute", http.StatusBadRequest)
+			}
+			if file.ValidateWorkspacePath(path, config.WorkspacePath()) != nil &&
+				file.ValidateWorkspacePath(path, config.ContainerWorkspacePath) != nil {
+				return api.NewAppError("bad_request", "paths must be within workspace", http.StatusBadRequest)
+			}
+		}
+		if err := service.UploadFiles(req.Locator, req.Paths); err != nil {
+			return browserError(err, "upload_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"uploaded": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+// emulationRequest applies to the whole context unless tab is set.
+type emulationRequest struct {
+	browser.EmulationOptions
+	Tab browser.TabRef `json:"tab"`
+}
+
+func registerBrowserEmulationRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/emulation", inBrowserContext(service, BrowserEmulationHandler))
+	router.Handle(http.MethodPost, "/v1/browser/emulation", inBrowserContext(service, BrowserEmulateHandler))
+	router.Handle(http.MethodDelete, "/v1/browser/emulation", inBrowserContext(service, BrowserResetEmulationHandler))
+	router.Handle(http.MethodGet, "/v1/browser/emulation/devices", BrowserDevicesHandler())
+}
+
+func BrowserEmulationHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		emulation, err := service.Emulation(tabQueryParam(r))
+		if err != nil {
+			return browserError(err, "emulation_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"emulation": emulation})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserEmulateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req emulationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		emulation, err := service.Emulate(req.Tab, req.EmulationOptions)
+		if err != nil {
+			return browserError(err, "emulation_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"emulation": emulation})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserResetEmulationHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := service.ResetEmulation(tabQueryParam(r)); err != nil {
+			return browserError(err, "emulation_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"reset": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserDevicesHandler() api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"devices": browser.Devices()})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+)
+
+// BrowserEventsHandler streams tab and download events of a context as
+// server-sent events. It does not start the browser, so clients can
+// subscribe before the first navigation.
+func BrowserEventsHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return api.NewAppError(api.CodeInternalError, "streaming unsupported", http.StatusInternalServerError)
+		}
+		kinds := splitQueryList(r.URL.Query()["types"])
+		events, cancel := service.Subscribe()
+		defer cancel()
+
+		// The server write timeout would otherwise cut long-lived streams.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		writeSSEEvent(w, "ready", map[string]any{"context": service.Name()})
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return nil
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return nil
+				}
+				flusher.Flush()
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+				if len(kinds) > 0 && !slices.Contains(kinds, event.Type) {
+					continue
+				}
+				writeSSEEvent(w, event.Type, event)
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+type browserLeaseRequest struct {
+	Session            string `json:"session"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+}
+
+func registerBrowserPoolRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/browsers", BrowserPoolListHandler(service))
+	router.Handle(http.MethodPost, "/v1/browser/browsers", BrowserPoolLeaseHandler(service))
+	router.HandlePrefix(http.MethodDelete, "/v1/browser/browsers/", BrowserPoolReleaseHandler(service))
+}
+
+func BrowserPoolListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"browsers": service.Browsers()})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserPoolLeaseHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req browserLeaseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if req.IdleTimeoutSeconds < 0 {
+			return api.NewAppError("bad_request", "invalid idle_timeout_seconds", http.StatusBadRequest)
+		}
+		info, err := service.LeaseBrowser(browser.LeaseOptions{
+			Session:     req.Session,
+			IdleTimeout: time.Duration(req.IdleTimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return browserError(err, "lease_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(info)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserPoolReleaseHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/browser/browsers/")
+		if id == "" || strings.Contains(id, "/") {
+			return api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+		}
+		if err := service.ReleaseBrowser(id); err != nil {
+			return browserError(err, "release_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"released": id})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/pkg/types"
+)
+
+// recordingStartRequest leaves path empty to save under the recording
+// directory of the workspace.
+type recordingStartRequest struct {
+	Path      string         `json:"path"`
+	Format    string         `json:"format"`
+	Tab       browser.TabRef `json:"tab"`
+	Quality   int            `json:"quality"`
+	MaxWidth  int            `json:"max_width"`
+	MaxHeight int            `json:"max_height"`
+}
+
+func registerBrowserRecordingRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/recordings", inBrowserContext(service, BrowserRecordingListHandler))
+	router.Handle(http.MethodPost, "/v1/browser/recordings/start", inBrowserContext(service, BrowserRecordingStartHandler))
+	router.Handle(http.MethodPost, "/v1/browser/recordings/stop", inBrowserContext(service, BrowserRecordingStopHandler))
+}
+
+func BrowserRecordingListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"recordings": service.Recordings()})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserRecordingStartHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req recordingStartRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if strings.TrimSpace(req.Path) != "" {
+			if !filepath.IsAbs(req.Path) {
+				return api.NewAppError("bad_request", "path must be absolute", http.StatusBadRequest)
+			}
+			if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
+				return api.NewAppError("bad_request", "path must be within workspace", http.StatusBadRequest)
+			}
+		}
+		info, err := service.StartRecording(browser.RecordingOptions{
+			Path:      req.Path,
+			Format:    req.Format,
+			Tab:       req.Tab,
+			Quality:   req.Quality,
+			MaxWidth:  req.MaxWidth,
+			MaxHeight: req.MaxHeight,
+		})
+		if err != nil {
+			return browserError(err, "recording_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(info)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserRecordingStopHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		info, err := service.StopRecording()
+		if err != nil {
+			return browserError(err, "recording_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(info)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
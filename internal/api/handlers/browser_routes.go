@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/pkg/types"
+)
+
+func registerBrowserRouteRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/routes", inBrowser(service, BrowserRouteListHandler))
+	router.Handle(http.MethodPost, "/v1/browser/routes", inBrowser(service, BrowserRouteAddHandler))
+	router.Handle(http.MethodDelete, "/v1/browser/routes", inBrowser(service, BrowserRouteClearHandler))
+	router.HandlePrefix(http.MethodDelete, "/v1/browser/routes/", inBrowser(service, BrowserRouteDeleteHandler))
+}
+
+func BrowserRouteListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"routes": service.Routes()})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserRouteAddHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var rule browser.RouteRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if rule.BodyFile != "" {
+			if !filepath.IsAbs(rule.BodyFile) {
+				return api.NewAppError("bad_request", "body_file must be absolute", http.StatusBadRequest)
+			}
+			if err := file.ValidateWorkspacePath(rule.BodyFile, config.WorkspacePath()); err != nil {
+				return api.NewAppError("bad_request", "body_file must be within workspace", http.StatusBadRequest)
+			}
+		}
+		added, err := service.AddRoute(rule)
+		if err != nil {
+			return browserError(err, "route_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(added)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserRouteClearHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		service.ClearRoutes()
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"cleared": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserRouteDeleteHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/browser/routes/")
+		if id == "" || strings.Contains(id, "/") {
+			return api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+		}
+		if err := service.RemoveRoute(id); err != nil {
+			if errors.Is(err, browser.ErrRouteNotFound) {
+				return api.NewAppError("not_found", err.Error(), http.StatusNotFound)
+			}
+			return api.NewAppError("route_failed", err.Error(), http.StatusInternalServerError)
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"deleted": id})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
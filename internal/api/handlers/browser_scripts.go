@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+const browserScriptsPrefix = "/v1/browser/scripts/"
+
+type scriptRecordRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type scriptRunRequest struct {
+	Variables map[string]string `json:"variables"`
+}
+
+// Scripts live in the workspace rather than a browser, so only recording
+// and running them resolve a browser context.
+func registerBrowserScriptRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/scripts", BrowserScriptListHandler(service))
+	router.Handle(http.MethodPost, "/v1/browser/scripts", BrowserScriptSaveHandler(service))
+	router.Handle(http.MethodPost, "/v1/browser/scripts/record/start", inBrowserContext(service, BrowserScriptRecordStartHandler))
+	router.Handle(http.MethodPost, "/v1/browser/scripts/record/stop", inBrowserContext(service, BrowserScriptRecordStopHandler))
+	router.HandlePrefix(http.MethodGet, browserScriptsPrefix, BrowserScriptGetHandler(service))
+	router.HandlePrefix(http.MethodDelete, browserScriptsPrefix, BrowserScriptDeleteHandler(service))
+	router.HandlePrefix(http.MethodPost, browserScriptsPrefix, inBrowserContext(service, BrowserScriptRunHandler))
+}
+
+// scriptName reads the name from /v1/browser/scripts/{name}[/suffix].
+func scriptName(r *http.Request, suffix string) (string, *api.AppError) {
+	name := strings.TrimPrefix(r.URL.Path, browserScriptsPrefix)
+	if suffix != "" {
+		var ok bool
+		if name, ok = strings.CutSuffix(name, "/"+suffix); !ok {
+			return "", api.NewAppError(api.CodeNotFound, "not found", http.StatusNotFound)
+		}
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return "", api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+	}
+	return name, nil
+}
+
+func BrowserScriptListHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		scripts, err := service.Scripts()
+		if err != nil {
+			return api.NewAppError("script_failed", err.Error(), http.StatusInternalServerError)
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"scripts": scripts})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScriptSaveHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var script browser.Script
+		if err := json.NewDecoder(r.Body).Decode(&script); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		// Keep the creation time of the script being replaced.
+		if existing, err := service.LoadScript(script.Name); err == nil {
+			script.CreatedAt = existing.CreatedAt
+		}
+		if err := service.SaveScript(&script); err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(script)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScriptGetHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		name, appErr := scriptName(r, "")
+		if appErr != nil {
+			return appErr
+		}
+		script, err := service.LoadScript(name)
+		if err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(script)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScriptDeleteHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		name, appErr := scriptName(r, "")
+		if appErr != nil {
+			return appErr
+		}
+		if err := service.DeleteScript(name); err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"deleted": name})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+// BrowserScriptRunHandler answers 200 for failed runs too; the result
+// tells which step failed and where its screenshot is.
+func BrowserScriptRunHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		name, appErr := scriptName(r, "run")
+		if appErr != nil {
+			return appErr
+		}
+		var req scriptRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		run, err := service.RunScript(name, browser.ScriptRunOptions{Variables: req.Variables}, executeAction)
+		if err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(run)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScriptRecordStartHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req scriptRecordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return api.NewAppError("bad_request", "name is required", http.StatusBadRequest)
+		}
+		script, err := service.StartScriptRecording(browser.ScriptRecordOptions{Name: req.Name, Description: req.Description})
+		if err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(script)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserScriptRecordStopHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		script, err := service.StopScriptRecording()
+		if err != nil {
+			return browserError(err, "script_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(script)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/pkg/types"
+)
+
+type cookieSetRequest struct {
+	Cookies []browser.Cookie `json:"cookies"`
+}
+
+type storageStateRequest struct {
+	Path  string                `json:"path"`
+	State *browser.StorageState `json:"state"`
+}
+
+func registerBrowserStorageRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodGet, "/v1/browser/cookies", inBrowserContext(service, BrowserCookiesHandler))
+	router.Handle(http.MethodPost, "/v1/browser/cookies", inBrowserContext(service, BrowserSetCookiesHandler))
+	router.Handle(http.MethodDelete, "/v1/browser/cookies", inBrowserContext(service, BrowserClearCookiesHandler))
+	router.Handle(http.MethodGet, "/v1/browser/storage", inBrowserContext(service, BrowserStorageHandler))
+	router.Handle(http.MethodPost, "/v1/browser/storage", inBrowserContext(service, BrowserUpdateStorageHandler))
+	router.Handle(http.MethodDelete, "/v1/browser/storage", inBrowserContext(service, BrowserClearStorageHandler))
+	router.Handle(http.MethodGet, "/v1/browser/storage_state", inBrowserContext(service, BrowserStorageStateHandler))
+	router.Handle(http.MethodPost, "/v1/browser/storage_state/save", inBrowserContext(service, BrowserSaveStorageStateHandler))
+	router.Handle(http.MethodPost, "/v1/browser/storage_state/load", inBrowserContext(service, BrowserLoadStorageStateHandler))
+}
+
+// storageStatePath validates a workspace path for a storage state file.
+func storageStatePath(path string) *api.AppError {
+	if strings.TrimSpace(path) == "" {
+		return api.NewAppError("bad_request", "path is required", http.StatusBadRequest)
+	}
+	if !filepath.IsAbs(path) {
+		return api.NewAppError("bad_request", "path must be absolute", http.StatusBadRequest)
+	}
+	if err := file.ValidateWorkspacePath(path, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", "path must be within workspace", http.StatusBadRequest)
+	}
+	return nil
+}
+
+func BrowserCookiesHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		cookies, err := service.Cookies(r.URL.Query()["url"])
+		if err != nil {
+			return browserError(err, "cookies_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"cookies": cookies})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserSetCookiesHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req cookieSetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.SetCookies(req.Cookies); err != nil {
+			return browserError(err, "cookies_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"set": len(req.Cookies)})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserClearCookiesHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		query := r.URL.Query()
+		removed, err := service.ClearCookies(browser.CookieFilter{
+			Name:   query.Get("name"),
+			Domain: query.Get("domain"),
+			Path:   query.Get("path"),
+		})
+		if err != nil {
+			return browserError(err, "cookies_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"removed": removed})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserStorageHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		tab := tabQueryParam(r)
+		storage, err := service.Storage(r.URL.Query().Get("kind"), tab)
+		if err != nil {
+			return browserError(err, "storage_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(storage)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserUpdateStorageHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req browser.StorageUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		storage, err := service.UpdateStorage(req)
+		if err != nil {
+			return browserError(err, "storage_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(storage)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserClearStorageHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		tab := tabQueryParam(r)
+		storage, err := service.UpdateStorage(browser.StorageUpdate{Kind: r.URL.Query().Get("kind"), Clear: true, Tab: tab})
+		if err != nil {
+			return browserError(err, "storage_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(storage)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserStorageStateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		state, err := service.StorageState()
+		if err != nil {
+			return browserError(err, "storage_state_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(state)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserSaveStorageStateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req storageStateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if appErr := storageStatePath(req.Path); appErr != nil {
+			return appErr
+		}
+		result, err := service.SaveStorageState(req.Path)
+		if err != nil {
+			return browserError(err, "storage_state_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(result)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+// BrowserLoadStorageStateHandler accepts either a workspace path or an
+// inline state document.
+func BrowserLoadStorageStateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req storageStateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		var (
+			result *browser.StorageStateResult
+			err    error
+		)
+		switch {
+		case req.State != nil:
+			result, err = service.LoadStorageState(req.State)
+		default:
+			if appErr := storageStatePath(req.Path); appErr != nil {
+				return appErr
+			}
+			result, err = service.LoadStorageStateFile(req.Path)
+		}
+		if err != nil {
+			return browserError(err, "storage_state_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(result)); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
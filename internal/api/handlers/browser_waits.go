@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/browser"
+	"open-sandbox/pkg/types"
+)
+
+// The wait requests double as /v1/browser/actions steps, which ignore the
+// extra action_type field.
+type waitForSelectorRequest struct {
+	browser.Locator
+	State string `json:"state"`
+}
+
+type waitForURLRequest struct {
+	URL       string `json:"url"`
+	TimeoutMS int    `json:"timeout_ms"`
+}
+
+type waitForFunctionRequest struct {
+	Expression string `json:"expression"`
+	TimeoutMS  int    `json:"timeout_ms"`
+}
+
+type waitForLoadStateRequest struct {
+	State     string `json:"state"`
+	TimeoutMS int    `json:"timeout_ms"`
+}
+
+func registerBrowserWaitRoutes(router *api.Router, service *browser.Service) {
+	router.Handle(http.MethodPost, "/v1/browser/wait_for_selector", inBrowserContext(service, BrowserWaitForSelectorHandler))
+	router.Handle(http.MethodPost, "/v1/browser/wait_for_url", inBrowserContext(service, BrowserWaitForURLHandler))
+	router.Handle(http.MethodPost, "/v1/browser/wait_for_function", inBrowserContext(service, BrowserWaitForFunctionHandler))
+	router.Handle(http.MethodPost, "/v1/browser/wait_for_load_state", inBrowserContext(service, BrowserWaitForLoadStateHandler))
+}
+
+func millis(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func BrowserWaitForSelectorHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req waitForSelectorRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.WaitForSelector(req.Locator, req.State); err != nil {
+			return browserError(err, "wait_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"waited": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserWaitForURLHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req waitForURLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		url, err := service.WaitForURL(req.URL, millis(req.TimeoutMS))
+		if err != nil {
+			return browserError(err, "wait_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"url": url})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserWaitForFunctionHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req waitForFunctionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		value, err := service.WaitForFunction(req.Expression, millis(req.TimeoutMS))
+		if err != nil {
+			return browserError(err, "wait_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"value": value})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
+
+func BrowserWaitForLoadStateHandler(service *browser.Service) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
+		var req waitForLoadStateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := service.WaitForLoadState(req.State, millis(req.TimeoutMS)); err != nil {
+			return browserError(err, "wait_failed")
+		}
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"waited": true})); err != nil {
+			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+}
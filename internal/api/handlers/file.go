@@ -2,7 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"open-sandbox/internal/api"
 	"open-sandbox/internal/config"
@@ -30,12 +37,41 @@ type fileReplaceRequest struct {
 	Replace string `json:"replace"`
 }
 
+type fileRestoreRequest struct {
+	Path    string `json:"path"`
+	Version int    `json:"version"`
+}
+
+type fileExtractRequest struct {
+	Path       string `json:"path"`
+	Target     string `json:"target"`
+	MaxBytes   int64  `json:"max_bytes"`
+	MaxEntries int    `json:"max_entries"`
+	Overwrite  bool   `json:"overwrite"`
+}
+
+type fileArchiveRequest struct {
+	Paths  []string `json:"paths"`
+	Globs  []string `json:"globs"`
+	Format string   `json:"format"`
+	Output string   `json:"output"`
+}
+
 func RegisterFileRoutes(router *api.Router) {
 	router.Handle(http.MethodPost, "/v1/file/read", FileReadHandler)
 	router.Handle(http.MethodPost, "/v1/file/write", FileWriteHandler)
 	router.Handle(http.MethodGet, "/v1/file/list", FileListHandler)
 	router.Handle(http.MethodPost, "/v1/file/search", FileSearchHandler)
 	router.Handle(http.MethodPost, "/v1/file/replace", FileReplaceHandler)
+	router.Handle(http.MethodGet, "/v1/file/watch", FileWatchHandler)
+	router.Handle(http.MethodGet, "/v1/file/history", FileHistoryHandler)
+	router.Handle(http.MethodPost, "/v1/file/restore", FileRestoreHandler)
+	router.Handle(http.MethodPost, "/v1/file/extract", FileExtractHandler)
+	router.Handle(http.MethodPost, "/v1/file/archive", FileArchiveHandler)
+}
+
+func fileHistory() *file.History {
+	return file.NewHistory(config.FileHistoryPath(), file.DefaultHistoryLimit)
 }
 
 func FileReadHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
@@ -70,7 +106,10 @@ func FileWriteHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
 	if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
 		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
 	}
-	if err := file.Write(req.Path, req.Content); err != nil {
+	err := fileHistory().Apply(req.Path, "write", func() error {
+		return file.Write(req.Path, req.Content)
+	})
+	if err != nil {
 		return api.NewAppError("write_failed", err.Error(), http.StatusInternalServerError)
 	}
 
@@ -137,7 +176,11 @@ func FileReplaceHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
 		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
 	}
 
-	count, err := file.Replace(req.Path, req.Search, req.Replace)
+	var count int
+	err := fileHistory().Apply(req.Path, "replace", func() (err error) {
+		count, err = file.Replace(req.Path, req.Search, req.Replace)
+		return err
+	})
 	if err != nil {
 		return api.NewAppError("replace_failed", err.Error(), http.StatusInternalServerError)
 	}
@@ -151,3 +194,271 @@ func FileReplaceHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
 	}
 	return nil
 }
+
+func FileHistoryHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	path := r.URL.Query().Get("path")
+	if err := file.ValidateWorkspacePath(path, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+
+	versions, err := fileHistory().List(path)
+	if err != nil {
+		return api.NewAppError("history_failed", err.Error(), http.StatusInternalServerError)
+	}
+
+	payload := map[string]any{
+		"path":     path,
+		"versions": versions,
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func FileRestoreHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req fileRestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+
+	version, err := fileHistory().Restore(req.Path, req.Version)
+	if err != nil {
+		if errors.Is(err, file.ErrVersionNotFound) {
+			return api.NewAppError("not_found", err.Error(), http.StatusNotFound)
+		}
+		return api.NewAppError("restore_failed", err.Error(), http.StatusInternalServerError)
+	}
+
+	payload := map[string]any{
+		"path":     req.Path,
+		"restored": version,
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// uploadMaxBytes caps multipart uploads; SANDBOX_UPLOAD_MAX_BYTES overrides
+// the default.
+func uploadMaxBytes() int64 {
+	if value, err := strconv.ParseInt(strings.TrimSpace(os.Getenv("SANDBOX_UPLOAD_MAX_BYTES")), 10, 64); err == nil && value > 0 {
+		return value
+	}
+	return file.DefaultUploadMaxBytes
+}
+
+// FileExtractHandler accepts either a multipart upload in the "file" field
+// or a JSON body naming an archive already in the workspace.
+func FileExtractHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req fileExtractRequest
+	archivePath := ""
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, uploadMaxBytes())
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return api.NewAppError("upload_too_large", err.Error(), http.StatusRequestEntityTooLarge)
+			}
+			return api.NewAppError("bad_request", "invalid multipart body", http.StatusBadRequest)
+		}
+		defer r.MultipartForm.RemoveAll()
+		req.Target = r.FormValue("target")
+		req.MaxBytes, _ = strconv.ParseInt(r.FormValue("max_bytes"), 10, 64)
+		req.MaxEntries, _ = strconv.Atoi(r.FormValue("max_entries"))
+		req.Overwrite, _ = strconv.ParseBool(r.FormValue("overwrite"))
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			return api.NewAppError("bad_request", "file upload is required", http.StatusBadRequest)
+		}
+		defer upload.Close()
+
+		tmp, err := os.CreateTemp("", "sandbox-upload-*")
+		if err != nil {
+			return api.NewAppError("extract_failed", err.Error(), http.StatusInternalServerError)
+		}
+		defer os.Remove(tmp.Name())
+		_, err = io.Copy(tmp, upload)
+		tmp.Close()
+		if err != nil {
+			return api.NewAppError("extract_failed", err.Error(), http.StatusInternalServerError)
+		}
+		archivePath = tmp.Name()
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+		}
+		if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
+			return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+		}
+		archivePath = req.Path
+	}
+	if err := file.ValidateWorkspacePath(req.Target, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+
+	result, err := file.Extract(archivePath, req.Target, file.ExtractOptions{
+		MaxBytes:   req.MaxBytes,
+		MaxEntries: req.MaxEntries,
+		Overwrite:  req.Overwrite,
+		History:    fileHistory(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, file.ErrArchiveExists):
+			return api.NewAppError("file_exists", err.Error(), http.StatusConflict)
+		case errors.Is(err, file.ErrArchiveTooLarge), errors.Is(err, file.ErrArchiveTooMany):
+			return api.NewAppError("archive_too_large", err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, file.ErrUnsupportedFormat), errors.Is(err, file.ErrUnsafeEntry):
+			return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+		}
+		return api.NewAppError("extract_failed", err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(result)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// FileArchiveHandler writes the archive to output when set, otherwise streams
+// it back as the response body.
+func FileArchiveHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req fileArchiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	if req.Format == "" {
+		req.Format = file.FormatZip
+	}
+	if req.Format != file.FormatZip && req.Format != file.FormatTarGz {
+		return api.NewAppError("bad_request", "format must be zip or tar.gz", http.StatusBadRequest)
+	}
+	if len(req.Paths) == 0 && len(req.Globs) == 0 {
+		return api.NewAppError("bad_request", "paths or globs are required", http.StatusBadRequest)
+	}
+	root := config.WorkspacePath()
+	for _, path := range req.Paths {
+		if err := file.ValidateWorkspacePath(path, root); err != nil {
+			return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+		}
+	}
+	options := file.ArchiveOptions{Format: req.Format, Root: root, Paths: req.Paths, Globs: req.Globs}
+
+	if req.Output == "" {
+		contentType := "application/zip"
+		if req.Format == file.FormatTarGz {
+			contentType = "application/gzip"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"workspace.%s\"", req.Format))
+		// Headers are committed once the first entry is written, so failures
+		// after that point can only truncate the stream.
+		if _, err := file.Archive(w, options); err != nil {
+			return api.NewAppError("archive_failed", err.Error(), http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := file.ValidateWorkspacePath(req.Output, root); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+	result, err := file.ArchiveToFile(req.Output, options)
+	if err != nil {
+		return api.NewAppError("archive_failed", err.Error(), http.StatusInternalServerError)
+	}
+
+	payload := map[string]any{
+		"path":    req.Output,
+		"format":  result.Format,
+		"entries": result.Entries,
+		"bytes":   result.Bytes,
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func FileWatchHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	query := r.URL.Query()
+	path := query.Get("path")
+	if err := file.ValidateWorkspacePath(path, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+	options := file.WatchOptions{
+		Recursive: query.Get("recursive") == "true" || query.Get("recursive") == "1",
+		Ignore:    splitQueryList(query["ignore"]),
+	}
+	if raw := query.Get("debounce_ms"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return api.NewAppError("bad_request", "invalid debounce_ms", http.StatusBadRequest)
+		}
+		options.Debounce = time.Duration(value) * time.Millisecond
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return api.NewAppError(api.CodeInternalError, "streaming unsupported", http.StatusInternalServerError)
+	}
+	events, err := file.Watch(r.Context(), path, options)
+	if err != nil {
+		return api.NewAppError("watch_failed", err.Error(), http.StatusBadRequest)
+	}
+
+	// The server write timeout would otherwise cut long-lived streams.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	writeSSEEvent(w, "ready", map[string]any{"path": path, "recursive": options.Recursive})
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case batch, ok := <-events:
+			if !ok {
+				return nil
+			}
+			for _, event := range batch {
+				writeSSEEvent(w, event.Type, event)
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, event string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+func splitQueryList(values []string) []string {
+	var results []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				results = append(results, item)
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+	"open-sandbox/internal/git"
+	"open-sandbox/pkg/types"
+)
+
+type gitBranchRequest struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	StartPoint string `json:"start_point"`
+	Delete     bool   `json:"delete"`
+	Force      bool   `json:"force"`
+}
+
+type gitCheckoutRequest struct {
+	Path   string `json:"path"`
+	Ref    string `json:"ref"`
+	Create bool   `json:"create"`
+}
+
+type gitAddRequest struct {
+	Path  string   `json:"path"`
+	Paths []string `json:"paths"`
+	All   bool     `json:"all"`
+}
+
+type gitCommitRequest struct {
+	Path       string     `json:"path"`
+	Message    string     `json:"message"`
+	Author     git.Author `json:"author"`
+	All        bool       `json:"all"`
+	AllowEmpty bool       `json:"allow_empty"`
+}
+
+type gitStashRequest struct {
+	Path             string `json:"path"`
+	Action           string `json:"action"`
+	Message          string `json:"message"`
+	IncludeUntracked bool   `json:"include_untracked"`
+	Index            int    `json:"index"`
+}
+
+type gitCloneRequest struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	Depth  int    `json:"depth"`
+}
+
+func RegisterGitRoutes(router *api.Router) {
+	router.Handle(http.MethodGet, "/v1/git/status", GitStatusHandler)
+	router.Handle(http.MethodGet, "/v1/git/diff", GitDiffHandler)
+	router.Handle(http.MethodGet, "/v1/git/log", GitLogHandler)
+	router.Handle(http.MethodGet, "/v1/git/branches", GitBranchesHandler)
+	router.Handle(http.MethodPost, "/v1/git/branch", GitBranchHandler)
+	router.Handle(http.MethodPost, "/v1/git/checkout", GitCheckoutHandler)
+	router.Handle(http.MethodPost, "/v1/git/add", GitAddHandler)
+	router.Handle(http.MethodPost, "/v1/git/commit", GitCommitHandler)
+	router.Handle(http.MethodPost, "/v1/git/stash", GitStashHandler)
+	router.Handle(http.MethodPost, "/v1/git/clone", GitCloneHandler)
+}
+
+// gitRepo opens the repository at path, defaulting to the workspace root.
+func gitRepo(path string) (*git.Repo, *api.AppError) {
+	if strings.TrimSpace(path) == "" {
+		return git.OpenIn(config.WorkspacePath(), config.WorkspacePath()), nil
+	}
+	if err := file.ValidateWorkspacePath(path, config.WorkspacePath()); err != nil {
+		return nil, api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+	return git.OpenIn(path, config.WorkspacePath()), nil
+}
+
+func gitError(err error) *api.AppError {
+	var cmdErr *git.CommandError
+	switch {
+	case errors.Is(err, git.ErrUnavailable):
+		return api.NewAppError("git_unavailable", err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, git.ErrInvalidInput):
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	case errors.Is(err, git.ErrNotRepository):
+		return api.NewAppError("not_a_repository", err.Error(), http.StatusBadRequest)
+	case errors.As(err, &cmdErr):
+		return api.NewAppError("git_failed", err.Error(), http.StatusBadRequest)
+	}
+	return api.NewAppError("git_failed", err.Error(), http.StatusInternalServerError)
+}
+
+func writeGitResult(w http.ResponseWriter, payload any) *api.AppError {
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(payload)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func GitStatusHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	repo, appErr := gitRepo(r.URL.Query().Get("path"))
+	if appErr != nil {
+		return appErr
+	}
+	status, err := repo.Status(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, status)
+}
+
+func GitDiffHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	query := r.URL.Query()
+	repo, appErr := gitRepo(query.Get("path"))
+	if appErr != nil {
+		return appErr
+	}
+	options := git.DiffOptions{
+		Staged: query.Get("staged") == "true" || query.Get("staged") == "1",
+		Ref:    query.Get("ref"),
+		Paths:  splitQueryList(query["paths"]),
+	}
+	if raw := query.Get("context"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return api.NewAppError("bad_request", "invalid context", http.StatusBadRequest)
+		}
+		options.Context = value
+	}
+
+	files, err := repo.Diff(r.Context(), options)
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"files": files})
+}
+
+func GitLogHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	query := r.URL.Query()
+	repo, appErr := gitRepo(query.Get("path"))
+	if appErr != nil {
+		return appErr
+	}
+	options := git.LogOptions{Ref: query.Get("ref"), Path: query.Get("file")}
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return api.NewAppError("bad_request", "invalid limit", http.StatusBadRequest)
+		}
+		options.Limit = value
+	}
+
+	commits, err := repo.Log(r.Context(), options)
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"commits": commits})
+}
+
+func GitBranchesHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	repo, appErr := gitRepo(r.URL.Query().Get("path"))
+	if appErr != nil {
+		return appErr
+	}
+	branches, err := repo.Branches(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"branches": branches})
+}
+
+func GitBranchHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	repo, appErr := gitRepo(req.Path)
+	if appErr != nil {
+		return appErr
+	}
+
+	var err error
+	if req.Delete {
+		err = repo.DeleteBranch(r.Context(), req.Name, req.Force)
+	} else {
+		err = repo.CreateBranch(r.Context(), req.Name, req.StartPoint)
+	}
+	if err != nil {
+		return gitError(err)
+	}
+	branches, err := repo.Branches(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"branches": branches})
+}
+
+func GitCheckoutHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitCheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	repo, appErr := gitRepo(req.Path)
+	if appErr != nil {
+		return appErr
+	}
+	if err := repo.Checkout(r.Context(), req.Ref, req.Create); err != nil {
+		return gitError(err)
+	}
+	status, err := repo.Status(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, status)
+}
+
+func GitAddHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	repo, appErr := gitRepo(req.Path)
+	if appErr != nil {
+		return appErr
+	}
+	if err := repo.Add(r.Context(), req.Paths, req.All); err != nil {
+		return gitError(err)
+	}
+	status, err := repo.Status(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, status)
+}
+
+func GitCommitHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitCommitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return api.NewAppError("bad_request", "message is required", http.StatusBadRequest)
+	}
+	repo, appErr := gitRepo(req.Path)
+	if appErr != nil {
+		return appErr
+	}
+	commit, err := repo.Commit(r.Context(), git.CommitOptions{
+		Message:    req.Message,
+		Author:     req.Author,
+		All:        req.All,
+		AllowEmpty: req.AllowEmpty,
+	})
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, commit)
+}
+
+func GitStashHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitStashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	repo, appErr := gitRepo(req.Path)
+	if appErr != nil {
+		return appErr
+	}
+	entries, err := repo.Stash(r.Context(), git.StashOptions{
+		Action:           req.Action,
+		Message:          req.Message,
+		IncludeUntracked: req.IncludeUntracked,
+		Index:            req.Index,
+	})
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"stashes": entries})
+}
+
+func GitCloneHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req gitCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+	if err := file.ValidateWorkspacePath(req.Path, config.WorkspacePath()); err != nil {
+		return api.NewAppError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+	// Clones routinely outlive the server's default write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(git.CloneTimeout))
+	repo, err := git.Clone(r.Context(), git.CloneOptions{
+		URL:    req.URL,
+		Dir:    req.Path,
+		Branch: req.Branch,
+		Depth:  req.Depth,
+	})
+	if err != nil {
+		return gitError(err)
+	}
+	status, err := repo.Status(r.Context())
+	if err != nil {
+		return gitError(err)
+	}
+	return writeGitResult(w, map[string]any{"path": req.Path, "status": status})
+}
@@ -17,7 +17,9 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 		Input: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
-				"url": map[string]any{"type": "string"},
+				"url":        map[string]any{"type": "string"},
+				"wait_until": map[string]any{"type": "string", "enum": []string{"commit", "domcontentloaded", "load", "networkidle"}, "description": "defaults to commit"},
+				"timeout_ms": map[string]any{"type": "integer", "description": "how long to wait for wait_until, default 30000"},
 			},
 			"required": []string{"url"},
 		},
@@ -32,27 +34,61 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 	browserScreenshotSchema := mcp.ToolSchema{
 		Input: mcp.JSONSchema{
 			"type": "object",
-			"properties": map[string]any{
-				"path": map[string]any{"type": "string"},
-			},
-			"required": []string{"path"},
+			"properties": browserLocatorProperties(map[string]any{
+				"path":      map[string]any{"type": "string", "description": "also save the image to this workspace path"},
+				"format":    map[string]any{"type": "string", "enum": []string{"png", "jpeg", "webp"}},
+				"quality":   map[string]any{"type": "integer", "description": "0-100, jpeg and webp only"},
+				"full_page": map[string]any{"type": "boolean"},
+				"clip": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"x":      map[string]any{"type": "number"},
+						"y":      map[string]any{"type": "number"},
+						"width":  map[string]any{"type": "number"},
+						"height": map[string]any{"type": "number"},
+					},
+					"required": []string{"x", "y", "width", "height"},
+				},
+				"scale": map[string]any{"type": "number", "description": "device scale factor, defaults to 1"},
+				"marks": map[string]any{"type": "boolean", "description": "number visible interactive elements; pass the number as element to other browser tools"},
+			}),
 		},
 		Output: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
-				"path": map[string]any{"type": "string"},
+				"path":      map[string]any{"type": "string"},
+				"format":    map[string]any{"type": "string"},
+				"mime_type": map[string]any{"type": "string"},
+				"bytes":     map[string]any{"type": "integer"},
+				"marks": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"number":   map[string]any{"type": "integer"},
+							"selector": map[string]any{"type": "string"},
+							"role":     map[string]any{"type": "string"},
+							"name":     map[string]any{"type": "string"},
+							"x":        map[string]any{"type": "number"},
+							"y":        map[string]any{"type": "number"},
+							"width":    map[string]any{"type": "number"},
+							"height":   map[string]any{"type": "number"},
+						},
+					},
+				},
 			},
-			"required": []string{"path"},
+			"required": []string{"format", "mime_type", "bytes"},
 		},
 	}
 	browserClickSchema := mcp.ToolSchema{
 		Input: mcp.JSONSchema{
 			"type": "object",
-			"properties": map[string]any{
-				"x": map[string]any{"type": "number"},
-				"y": map[string]any{"type": "number"},
-			},
-			"required": []string{"x", "y"},
+			"properties": browserLocatorProperties(map[string]any{
+				"x":           map[string]any{"type": "number"},
+				"y":           map[string]any{"type": "number"},
+				"button":      map[string]any{"type": "string", "enum": []string{"left", "right", "middle"}},
+				"click_count": map[string]any{"type": "integer"},
+			}),
 		},
 		Output: mcp.JSONSchema{
 			"type": "object",
@@ -139,25 +175,27 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 		Output: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
+				"id":    map[string]any{"type": "string"},
 				"index": map[string]any{"type": "integer"},
 			},
-			"required": []string{"index"},
+			"required": []string{"id", "index"},
 		},
 	}
 	browserSwitchTabSchema := mcp.ToolSchema{
 		Input: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
-				"index": map[string]any{"type": "integer"},
+				"id":    map[string]any{"type": "string", "description": "tab id from browser_tab_list"},
+				"index": map[string]any{"type": "integer", "description": "tab position, used when id is not set"},
 			},
-			"required": []string{"index"},
 		},
 		Output: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
+				"id":    map[string]any{"type": "string"},
 				"index": map[string]any{"type": "integer"},
 			},
-			"required": []string{"index"},
+			"required": []string{"id", "index"},
 		},
 	}
 	browserTabListSchema := mcp.ToolSchema{
@@ -177,9 +215,9 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 		Input: mcp.JSONSchema{
 			"type": "object",
 			"properties": map[string]any{
-				"index": map[string]any{"type": "integer"},
+				"id":    map[string]any{"type": "string", "description": "tab id from browser_tab_list"},
+				"index": map[string]any{"type": "integer", "description": "tab position, used when id is not set"},
 			},
-			"required": []string{"index"},
 		},
 		Output: mcp.JSONSchema{
 			"type": "object",
@@ -301,6 +339,335 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			"required": []string{"count"},
 		},
 	}
+	fileHistorySchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string"},
+			},
+			"required": []string{"path"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":     map[string]any{"type": "string"},
+				"versions": map[string]any{"type": "array"},
+			},
+			"required": []string{"path", "versions"},
+		},
+	}
+	fileRestoreSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string"},
+				"version": map[string]any{"type": "integer"},
+			},
+			"required": []string{"path", "version"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":     map[string]any{"type": "string"},
+				"restored": map[string]any{"type": "object"},
+			},
+			"required": []string{"path", "restored"},
+		},
+	}
+	fileExtractSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":        map[string]any{"type": "string"},
+				"target":      map[string]any{"type": "string"},
+				"max_bytes":   map[string]any{"type": "integer"},
+				"max_entries": map[string]any{"type": "integer"},
+				"overwrite":   map[string]any{"type": "boolean"},
+			},
+			"required": []string{"path", "target"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"format":  map[string]any{"type": "string"},
+				"target":  map[string]any{"type": "string"},
+				"entries": map[string]any{"type": "integer"},
+				"bytes":   map[string]any{"type": "integer"},
+				"skipped": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+			"required": []string{"format", "target", "entries", "bytes"},
+		},
+	}
+	fileArchiveSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"paths":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"globs":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"format": map[string]any{"type": "string", "enum": []string{"zip", "tar.gz"}},
+				"output": map[string]any{"type": "string"},
+			},
+			"required": []string{"output"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string"},
+				"format":  map[string]any{"type": "string"},
+				"entries": map[string]any{"type": "integer"},
+				"bytes":   map[string]any{"type": "integer"},
+			},
+			"required": []string{"path", "format", "entries", "bytes"},
+		},
+	}
+	snapshotSummaryOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
+			"label":      map[string]any{"type": "string"},
+			"created_at": map[string]any{"type": "string"},
+			"files":      map[string]any{"type": "integer"},
+			"total_size": map[string]any{"type": "integer"},
+		},
+		"required": []string{"id", "created_at"},
+	}
+	workspaceSnapshotCreateSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"label": map[string]any{"type": "string"},
+			},
+		},
+		Output: snapshotSummaryOutput,
+	}
+	workspaceSnapshotListSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"snapshots": map[string]any{"type": "array"},
+			},
+			"required": []string{"snapshots"},
+		},
+	}
+	workspaceSnapshotDiffSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"from": map[string]any{"type": "string"},
+				"to":   map[string]any{"type": "string"},
+			},
+			"required": []string{"from"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"added":    map[string]any{"type": "array"},
+				"removed":  map[string]any{"type": "array"},
+				"modified": map[string]any{"type": "array"},
+			},
+			"required": []string{"added", "removed", "modified"},
+		},
+	}
+	workspaceSnapshotRestoreSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{"type": "string"},
+			},
+			"required": []string{"id"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"restored": map[string]any{"type": "object"},
+			},
+			"required": []string{"restored"},
+		},
+	}
+	gitStatusOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"branch": map[string]any{"type": "string"},
+			"commit": map[string]any{"type": "string"},
+			"ahead":  map[string]any{"type": "integer"},
+			"behind": map[string]any{"type": "integer"},
+			"clean":  map[string]any{"type": "boolean"},
+			"files":  map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+		},
+		"required": []string{"branch", "clean", "files"},
+	}
+	gitBranchesOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"branches": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+		},
+		"required": []string{"branches"},
+	}
+	gitCommitOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"hash":    map[string]any{"type": "string"},
+			"author":  map[string]any{"type": "object"},
+			"date":    map[string]any{"type": "string"},
+			"subject": map[string]any{"type": "string"},
+		},
+		"required": []string{"hash", "subject"},
+	}
+	gitStatusSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string"},
+			},
+		},
+		Output: gitStatusOutput,
+	}
+	gitDiffSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string"},
+				"staged":  map[string]any{"type": "boolean"},
+				"ref":     map[string]any{"type": "string"},
+				"paths":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"context": map[string]any{"type": "integer"},
+			},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"files": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			},
+			"required": []string{"files"},
+		},
+	}
+	gitLogSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":  map[string]any{"type": "string"},
+				"ref":   map[string]any{"type": "string"},
+				"file":  map[string]any{"type": "string"},
+				"limit": map[string]any{"type": "integer"},
+			},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"commits": map[string]any{"type": "array", "items": gitCommitOutput},
+			},
+			"required": []string{"commits"},
+		},
+	}
+	gitBranchesSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string"},
+			},
+		},
+		Output: gitBranchesOutput,
+	}
+	gitBranchSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":        map[string]any{"type": "string"},
+				"name":        map[string]any{"type": "string"},
+				"start_point": map[string]any{"type": "string"},
+				"delete":      map[string]any{"type": "boolean"},
+				"force":       map[string]any{"type": "boolean"},
+			},
+			"required": []string{"name"},
+		},
+		Output: gitBranchesOutput,
+	}
+	gitCheckoutSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":   map[string]any{"type": "string"},
+				"ref":    map[string]any{"type": "string"},
+				"create": map[string]any{"type": "boolean"},
+			},
+			"required": []string{"ref"},
+		},
+		Output: gitStatusOutput,
+	}
+	gitAddSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":  map[string]any{"type": "string"},
+				"paths": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"all":   map[string]any{"type": "boolean"},
+			},
+		},
+		Output: gitStatusOutput,
+	}
+	gitCommitSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string"},
+				"message": map[string]any{"type": "string"},
+				"author": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"name":  map[string]any{"type": "string"},
+						"email": map[string]any{"type": "string"},
+					},
+				},
+				"all":         map[string]any{"type": "boolean"},
+				"allow_empty": map[string]any{"type": "boolean"},
+			},
+			"required": []string{"message"},
+		},
+		Output: gitCommitOutput,
+	}
+	gitStashSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":              map[string]any{"type": "string"},
+				"action":            map[string]any{"type": "string", "enum": []string{"push", "pop", "apply", "drop", "list"}},
+				"message":           map[string]any{"type": "string"},
+				"include_untracked": map[string]any{"type": "boolean"},
+				"index":             map[string]any{"type": "integer"},
+			},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"stashes": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			},
+			"required": []string{"stashes"},
+		},
+	}
+	gitCloneSchema := mcp.ToolSchema{
+		Input: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"url":    map[string]any{"type": "string"},
+				"path":   map[string]any{"type": "string"},
+				"branch": map[string]any{"type": "string"},
+				"depth":  map[string]any{"type": "integer"},
+			},
+			"required": []string{"url", "path"},
+		},
+		Output: mcp.JSONSchema{
+			"type": "object",
+			"properties": map[string]any{
+				"path":   map[string]any{"type": "string"},
+				"status": gitStatusOutput,
+			},
+			"required": []string{"path", "status"},
+		},
+	}
 	shellExecSchema := mcp.ToolSchema{
 		Input: mcp.JSONSchema{
 			"type": "object",
@@ -349,7 +716,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "network",
 		},
 		Schema:  browserNavigateSchema,
-		Handler: tools.BrowserNavigate(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserNavigate),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser.screenshot",
@@ -359,7 +726,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserScreenshotSchema,
-		Handler: tools.BrowserScreenshot(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserScreenshot),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser.click",
@@ -369,7 +736,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserClickSchema,
-		Handler: tools.BrowserClick(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserClick),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_navigate",
@@ -379,7 +746,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "network",
 		},
 		Schema:  browserNavigateSchema,
-		Handler: tools.BrowserNavigate(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserNavigate),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_screenshot",
@@ -389,7 +756,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserScreenshotSchema,
-		Handler: tools.BrowserScreenshot(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserScreenshot),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_click",
@@ -399,7 +766,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserClickSchema,
-		Handler: tools.BrowserClick(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserClick),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_form_input_fill",
@@ -409,7 +776,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserFormInputFillSchema,
-		Handler: tools.BrowserFormInputFill(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserFormInputFill),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_select",
@@ -419,7 +786,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserSelectSchema,
-		Handler: tools.BrowserSelect(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSelect),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_scroll",
@@ -429,7 +796,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserScrollSchema,
-		Handler: tools.BrowserScroll(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserScroll),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_evaluate",
@@ -439,7 +806,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserEvaluateSchema,
-		Handler: tools.BrowserEvaluate(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserEvaluate),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_new_tab",
@@ -449,7 +816,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserNewTabSchema,
-		Handler: tools.BrowserNewTab(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserNewTab),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_switch_tab",
@@ -459,7 +826,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserSwitchTabSchema,
-		Handler: tools.BrowserSwitchTab(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSwitchTab),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_tab_list",
@@ -469,7 +836,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserTabListSchema,
-		Handler: tools.BrowserTabList(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserTabList),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_close_tab",
@@ -479,7 +846,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserCloseTabSchema,
-		Handler: tools.BrowserCloseTab(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserCloseTab),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_get_download_list",
@@ -489,7 +856,7 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserDownloadListSchema,
-		Handler: tools.BrowserGetDownloadList(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserGetDownloadList),
 	})
 	registry.Register(mcp.Tool{
 		Name:    "browser_press_key",
@@ -499,8 +866,19 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 			Scope: "workspace",
 		},
 		Schema:  browserPressKeySchema,
-		Handler: tools.BrowserPressKey(browserService),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserPressKey),
 	})
+	registerBrowserElementTools(registry, browserService)
+	registerBrowserDevtoolsTools(registry, browserService)
+	registerBrowserRouteTools(registry, browserService)
+	registerBrowserStorageTools(registry, browserService)
+	registerBrowserContextTools(registry, browserService)
+	registerBrowserRecordingTools(registry, browserService)
+	registerBrowserWaitTools(registry, browserService)
+	registerBrowserDialogTools(registry, browserService)
+	registerBrowserEmulationTools(registry, browserService)
+	registerBrowserPoolTools(registry, browserService)
+	addBrowserContextParam(registry)
 	registry.Register(mcp.Tool{
 		Name:    "file.read",
 		Version: "v1",
@@ -551,6 +929,186 @@ func NewMCPRegistry(browserService *browser.Service, remoteManager *remote.Manag
 		Schema:  fileReplaceSchema,
 		Handler: tools.FileReplace(),
 	})
+	registry.Register(mcp.Tool{
+		Name:    "file.history",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  fileHistorySchema,
+		Handler: tools.FileHistory(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "file.restore",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  fileRestoreSchema,
+		Handler: tools.FileRestore(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "file.extract",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  fileExtractSchema,
+		Handler: tools.FileExtract(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "file.archive",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  fileArchiveSchema,
+		Handler: tools.FileArchive(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "workspace.snapshot_create",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  workspaceSnapshotCreateSchema,
+		Handler: tools.WorkspaceSnapshotCreate(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "workspace.snapshot_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  workspaceSnapshotListSchema,
+		Handler: tools.WorkspaceSnapshotList(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "workspace.snapshot_diff",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  workspaceSnapshotDiffSchema,
+		Handler: tools.WorkspaceSnapshotDiff(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "workspace.snapshot_restore",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  workspaceSnapshotRestoreSchema,
+		Handler: tools.WorkspaceSnapshotRestore(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.status",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitStatusSchema,
+		Handler: tools.GitStatus(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.diff",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitDiffSchema,
+		Handler: tools.GitDiff(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.log",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitLogSchema,
+		Handler: tools.GitLog(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.branches",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitBranchesSchema,
+		Handler: tools.GitBranches(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.branch",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitBranchSchema,
+		Handler: tools.GitBranch(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.checkout",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitCheckoutSchema,
+		Handler: tools.GitCheckout(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.add",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitAddSchema,
+		Handler: tools.GitAdd(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.commit",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitCommitSchema,
+		Handler: tools.GitCommit(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.stash",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  gitStashSchema,
+		Handler: tools.GitStash(),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "git.clone",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "network",
+		},
+		Schema:  gitCloneSchema,
+		Handler: tools.GitClone(),
+	})
 	registry.Register(mcp.Tool{
 		Name:    "shell.exec",
 		Version: "v1",
@@ -582,6 +1140,7 @@ func RegisterMCPRoutes(router *api.Router, browserService *browser.Service, remo
 
 	auth, authErr := mcp.NewAuthenticator(mcp.LoadAuthConfig())
 	server := mcp.NewServer(registry, auth, authErr)
+	server.SetResourceWatcher(tools.FileResourceWatcher())
 
 	router.Handle("POST", "/mcp", func(w http.ResponseWriter, r *http.Request) *api.AppError {
 		server.ServeHTTP(w, r)
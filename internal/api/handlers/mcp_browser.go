@@ -0,0 +1,1125 @@
+package handlers
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+	"open-sandbox/internal/mcp/tools"
+)
+
+// browserLocatorProperties returns the schema properties shared by every
+// element-targeting browser tool, merged with extra.
+func browserLocatorProperties(extra map[string]any) map[string]any {
+	properties := map[string]any{
+		"ref":        map[string]any{"type": "string", "description": "element ref from browser_snapshot, e.g. e12"},
+		"element":    map[string]any{"type": "integer", "description": "element number from a browser_screenshot taken with marks"},
+		"selector":   map[string]any{"type": "string", "description": "CSS selector"},
+		"xpath":      map[string]any{"type": "string"},
+		"text":       map[string]any{"type": "string", "description": "visible text of the element"},
+		"role":       map[string]any{"type": "string", "description": "ARIA role, e.g. button or link"},
+		"name":       map[string]any{"type": "string", "description": "accessible name, used with role"},
+		"exact":      map[string]any{"type": "boolean"},
+		"nth":        map[string]any{"type": "integer"},
+		"timeout_ms": map[string]any{"type": "integer"},
+	}
+	maps.Copy(properties, extra)
+	return properties
+}
+
+func registerBrowserElementTools(registry *mcp.Registry, browserService *browser.Service) {
+	elementSchema := func(extra map[string]any, output string) mcp.ToolSchema {
+		return mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type":       "object",
+				"properties": browserLocatorProperties(extra),
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					output: map[string]any{"type": "boolean"},
+				},
+				"required": []string{output},
+			},
+		}
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_snapshot",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"interactive": map[string]any{"type": "boolean", "description": "only list elements that have a ref"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"url":   map[string]any{"type": "string"},
+					"title": map[string]any{"type": "string"},
+					"refs":  map[string]any{"type": "integer"},
+					"tree":  map[string]any{"type": "object"},
+					"text":  map[string]any{"type": "string"},
+				},
+				"required": []string{"tree", "text"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSnapshot),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_content",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"format":    map[string]any{"type": "string", "enum": []string{"markdown", "text", "html"}, "default": "markdown"},
+					"selector":  map[string]any{"type": "string", "description": "limit extraction to the first matching element"},
+					"max_chars": map[string]any{"type": "integer", "default": browser.DefaultContentMaxChars},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"url":       map[string]any{"type": "string"},
+					"title":     map[string]any{"type": "string"},
+					"content":   map[string]any{"type": "string"},
+					"truncated": map[string]any{"type": "boolean"},
+					"links":     map[string]any{"type": "array"},
+					"forms":     map[string]any{"type": "array"},
+				},
+				"required": []string{"content"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserContent),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_hover",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  elementSchema(nil, "hovered"),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserHover),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_type",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: elementSchema(map[string]any{
+			"value": map[string]any{"type": "string", "description": "text to type"},
+			"clear": map[string]any{"type": "boolean"},
+		}, "typed"),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserType),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_focus",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  elementSchema(nil, "focused"),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserFocus),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_check",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: elementSchema(map[string]any{
+			"checked": map[string]any{"type": "boolean", "default": true},
+		}, "checked"),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserCheck),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_scroll_into_view",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  elementSchema(nil, "scrolled"),
+		Handler: tools.InBrowserContext(browserService, tools.BrowserScrollIntoView),
+	})
+	uploadSchema := elementSchema(map[string]any{
+		"paths": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"minItems":    1,
+			"description": "workspace files to set on the input or the file chooser the element opens",
+		},
+	}, "uploaded")
+	uploadSchema.Input["required"] = []string{"paths"}
+	registry.Register(mcp.Tool{
+		Name:    "browser_upload_file",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema:  uploadSchema,
+		Handler: tools.InBrowserContext(browserService, tools.BrowserUploadFile),
+	})
+}
+
+func registerBrowserDevtoolsTools(registry *mcp.Registry, browserService *browser.Service) {
+	cursorProperties := func(extra map[string]any) map[string]any {
+		properties := map[string]any{
+			"since": map[string]any{"type": "integer", "description": "cursor from a previous call"},
+			"tab":   map[string]any{"type": "string", "description": "tab id, defaults to the active tab"},
+		}
+		maps.Copy(properties, extra)
+		return properties
+	}
+	cursorOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"entries": map[string]any{"type": "array"},
+			"cursor":  map[string]any{"type": "integer"},
+			"dropped": map[string]any{"type": "boolean"},
+		},
+		"required": []string{"entries", "cursor"},
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_console_logs",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": cursorProperties(map[string]any{
+					"level": map[string]any{"type": "string", "enum": []string{"debug", "info", "warning", "error"}, "description": "minimum level"},
+					"limit": map[string]any{"type": "integer", "default": browser.DefaultConsoleLimit},
+				}),
+			},
+			Output: cursorOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserConsoleLogs),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_network_requests",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": cursorProperties(map[string]any{
+					"url":    map[string]any{"type": "string", "description": "substring of the request URL"},
+					"method": map[string]any{"type": "string"},
+					"type":   map[string]any{"type": "string", "description": "resource type, e.g. XHR, Fetch, Document"},
+					"failed": map[string]any{"type": "boolean", "description": "only network errors and status >= 400"},
+					"limit":  map[string]any{"type": "integer", "default": browser.DefaultNetworkLimit},
+				}),
+			},
+			Output: cursorOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserNetworkRequests),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_network_response_body",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"request_id": map[string]any{"type": "string"},
+					"tab":        map[string]any{"type": "string"},
+				},
+				"required": []string{"request_id"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"body":   map[string]any{"type": "string"},
+					"base64": map[string]any{"type": "boolean"},
+				},
+				"required": []string{"body"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserResponseBody),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_network_export_har",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":           map[string]any{"type": "string"},
+					"tab":            map[string]any{"type": "string"},
+					"include_bodies": map[string]any{"type": "boolean"},
+				},
+				"required": []string{"path"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":    map[string]any{"type": "string"},
+					"entries": map[string]any{"type": "integer"},
+				},
+				"required": []string{"path", "entries"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserExportHAR),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_pdf",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":   map[string]any{"type": "string", "description": "workspace path of the PDF"},
+					"format": map[string]any{"type": "string", "enum": []string{"letter", "legal", "tabloid", "ledger", "a0", "a1", "a2", "a3", "a4", "a5", "a6"}},
+					"width":  map[string]any{"type": "number", "description": "paper width in inches, overrides format"},
+					"height": map[string]any{"type": "number", "description": "paper height in inches, overrides format"},
+					"margin": map[string]any{
+						"type":        "object",
+						"description": "margins in inches",
+						"properties": map[string]any{
+							"top":    map[string]any{"type": "number"},
+							"right":  map[string]any{"type": "number"},
+							"bottom": map[string]any{"type": "number"},
+							"left":   map[string]any{"type": "number"},
+						},
+					},
+					"landscape":            map[string]any{"type": "boolean"},
+					"page_ranges":          map[string]any{"type": "string", "description": "e.g. 1-3, 5"},
+					"header_template":      map[string]any{"type": "string", "description": "HTML; elements with class pageNumber, totalPages, title, url or date are filled in"},
+					"footer_template":      map[string]any{"type": "string"},
+					"print_background":     map[string]any{"type": "boolean"},
+					"scale":                map[string]any{"type": "number", "description": "0.1-2"},
+					"prefer_css_page_size": map[string]any{"type": "boolean"},
+				},
+				"required": []string{"path"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":  map[string]any{"type": "string"},
+					"bytes": map[string]any{"type": "integer"},
+				},
+				"required": []string{"path", "bytes"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserPDF),
+	})
+}
+
+func registerBrowserRouteTools(registry *mcp.Registry, browserService *browser.Service) {
+	registry.Register(mcp.Tool{
+		Name:    "browser_route_add",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"url":            map[string]any{"type": "string", "description": "URL glob; * matches any characters, ? one"},
+					"method":         map[string]any{"type": "string"},
+					"resource_type":  map[string]any{"type": "string", "description": "e.g. Document, Script, Image, XHR"},
+					"action":         map[string]any{"type": "string", "enum": []string{browser.RouteActionFulfill, browser.RouteActionAbort, browser.RouteActionContinue}, "default": browser.RouteActionFulfill},
+					"status":         map[string]any{"type": "integer", "default": 200},
+					"headers":        map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}, "description": "response headers for fulfill, request headers to set for continue"},
+					"remove_headers": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"body":           map[string]any{"type": "string"},
+					"body_file":      map[string]any{"type": "string", "description": "workspace file served as the response body"},
+					"error_reason":   map[string]any{"type": "string", "default": "BlockedByClient"},
+					"times":          map[string]any{"type": "integer", "description": "apply at most this many times"},
+				},
+				"required": []string{"url"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{"type": "string"},
+				},
+				"required": []string{"id"},
+			},
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserRouteAdd),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_route_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{"type": "object"},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"routes": map[string]any{"type": "array"},
+				},
+				"required": []string{"routes"},
+			},
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserRouteList),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_route_remove",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"id":  map[string]any{"type": "string"},
+					"all": map[string]any{"type": "boolean", "description": "remove every rule"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"removed": map[string]any{"type": "boolean"},
+				},
+				"required": []string{"removed"},
+			},
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserRouteRemove),
+	})
+}
+
+func registerBrowserStorageTools(registry *mcp.Registry, browserService *browser.Service) {
+	cookieSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string"},
+			"value":    map[string]any{"type": "string"},
+			"url":      map[string]any{"type": "string", "description": "alternative to domain and path"},
+			"domain":   map[string]any{"type": "string"},
+			"path":     map[string]any{"type": "string"},
+			"expires":  map[string]any{"type": "number", "description": "seconds since the epoch, -1 for a session cookie"},
+			"httpOnly": map[string]any{"type": "boolean"},
+			"secure":   map[string]any{"type": "boolean"},
+			"sameSite": map[string]any{"type": "string", "enum": []string{"Strict", "Lax", "None"}},
+		},
+		"required": []string{"name", "value"},
+	}
+	storageKind := map[string]any{"type": "string", "enum": []string{browser.StorageKindLocal, browser.StorageKindSession}, "default": browser.StorageKindLocal}
+	storageOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"origin": map[string]any{"type": "string"},
+			"kind":   map[string]any{"type": "string"},
+			"items":  map[string]any{"type": "object"},
+		},
+		"required": []string{"origin", "items"},
+	}
+	stateOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"path":    map[string]any{"type": "string"},
+			"cookies": map[string]any{"type": "integer"},
+			"origins": map[string]any{"type": "integer"},
+		},
+		"required": []string{"cookies", "origins"},
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_get_cookies",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"urls": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "only cookies sent to these URLs; all cookies when omitted"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"cookies": map[string]any{"type": "array", "items": cookieSchema},
+				},
+				"required": []string{"cookies"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserGetCookies),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_set_cookies",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"cookies": map[string]any{"type": "array", "items": cookieSchema},
+				},
+				"required": []string{"cookies"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"set": map[string]any{"type": "integer"},
+				},
+				"required": []string{"set"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSetCookies),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_clear_cookies",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"name":   map[string]any{"type": "string"},
+					"domain": map[string]any{"type": "string", "description": "also matches subdomains"},
+					"path":   map[string]any{"type": "string"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"removed": map[string]any{"type": "integer"},
+				},
+				"required": []string{"removed"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserClearCookies),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_get_storage",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"kind": storageKind,
+					"tab":  map[string]any{"type": "string"},
+				},
+			},
+			Output: storageOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserGetStorage),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_set_storage",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"kind":   storageKind,
+					"items":  map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+					"remove": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"clear":  map[string]any{"type": "boolean", "description": "clear before applying items"},
+					"tab":    map[string]any{"type": "string"},
+				},
+			},
+			Output: storageOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSetStorage),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_save_storage_state",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{"type": "string", "description": "workspace file for Playwright-compatible storage state"},
+				},
+				"required": []string{"path"},
+			},
+			Output: stateOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserSaveStorageState),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_load_storage_state",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":  map[string]any{"type": "string"},
+					"state": map[string]any{"type": "object", "description": "inline storage state instead of path"},
+				},
+			},
+			Output: stateOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserLoadStorageState),
+	})
+}
+
+func registerBrowserContextTools(registry *mcp.Registry, browserService *browser.Service) {
+	contextOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"name":       map[string]any{"type": "string"},
+			"tabs":       map[string]any{"type": "integer"},
+			"expires_at": map[string]any{"type": "string"},
+		},
+		"required": []string{"name"},
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_context_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{"type": "object"},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"contexts": map[string]any{"type": "array"},
+				},
+				"required": []string{"contexts"},
+			},
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserContextList),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_context_create",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"name":                 map[string]any{"type": "string"},
+					"idle_timeout_seconds": map[string]any{"type": "integer", "description": "close the context after this long unused"},
+				},
+				"required": []string{"name"},
+			},
+			Output: contextOutput,
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserContextCreate),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_context_close",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+				},
+				"required": []string{"name"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"closed": map[string]any{"type": "string"},
+				},
+				"required": []string{"closed"},
+			},
+		},
+		Handler: tools.InBrowser(browserService, tools.BrowserContextClose),
+	})
+}
+
+func registerBrowserRecordingTools(registry *mcp.Registry, browserService *browser.Service) {
+	recordingOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"id":          map[string]any{"type": "string"},
+			"tab_id":      map[string]any{"type": "string"},
+			"format":      map[string]any{"type": "string"},
+			"path":        map[string]any{"type": "string"},
+			"state":       map[string]any{"type": "string", "enum": []string{"recording", "finished", "failed"}},
+			"frames":      map[string]any{"type": "integer"},
+			"duration_ms": map[string]any{"type": "integer"},
+			"bytes":       map[string]any{"type": "integer"},
+			"error":       map[string]any{"type": "string"},
+		},
+		"required": []string{"id", "format", "path", "state"},
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_recording_start",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"path":       map[string]any{"type": "string", "description": "workspace path; defaults to the recordings directory"},
+					"format":     map[string]any{"type": "string", "enum": []string{"webm", "mp4", "gif", "frames"}, "description": "webm and mp4 need ffmpeg and fall back to gif"},
+					"tab":        map[string]any{"type": "string"},
+					"quality":    map[string]any{"type": "integer", "description": "jpeg quality of captured frames, 0-100"},
+					"max_width":  map[string]any{"type": "integer"},
+					"max_height": map[string]any{"type": "integer"},
+				},
+			},
+			Output: recordingOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserRecordingStart),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_recording_stop",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input:  mcp.JSONSchema{"type": "object"},
+			Output: recordingOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserRecordingStop),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_recording_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{"type": "object"},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"recordings": map[string]any{"type": "array", "items": recordingOutput},
+				},
+				"required": []string{"recordings"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserRecordingList),
+	})
+}
+
+func registerBrowserWaitTools(registry *mcp.Registry, browserService *browser.Service) {
+	waitedOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"waited": map[string]any{"type": "boolean"},
+		},
+		"required": []string{"waited"},
+	}
+	timeout := map[string]any{"type": "integer", "description": "milliseconds, default 30000"}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_wait_for_selector",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": browserLocatorProperties(map[string]any{
+					"state": map[string]any{"type": "string", "enum": []string{"attached", "detached", "visible", "hidden"}, "description": "defaults to visible"},
+				}),
+			},
+			Output: waitedOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserWaitForSelector),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_wait_for_url",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"url":        map[string]any{"type": "string", "description": "URL glob; * matches any characters, ? one"},
+					"timeout_ms": timeout,
+				},
+				"required": []string{"url"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"url": map[string]any{"type": "string"},
+				},
+				"required": []string{"url"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserWaitForURL),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_wait_for_function",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"expression": map[string]any{"type": "string", "description": "JavaScript expression or function, polled until truthy; may return a promise"},
+					"timeout_ms": timeout,
+				},
+				"required": []string{"expression"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"value": map[string]any{},
+				},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserWaitForFunction),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_wait_for_load_state",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"state":      map[string]any{"type": "string", "enum": []string{"domcontentloaded", "load", "networkidle"}, "description": "defaults to load"},
+					"timeout_ms": timeout,
+				},
+			},
+			Output: waitedOutput,
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserWaitForLoadState),
+	})
+}
+
+func registerBrowserDialogTools(registry *mcp.Registry, browserService *browser.Service) {
+	dialogProperties := map[string]any{
+		"tab_id":         map[string]any{"type": "string"},
+		"type":           map[string]any{"type": "string", "enum": []string{"alert", "confirm", "prompt", "beforeunload"}},
+		"message":        map[string]any{"type": "string"},
+		"default_prompt": map[string]any{"type": "string"},
+		"url":            map[string]any{"type": "string"},
+		"opened_at":      map[string]any{"type": "string"},
+		"accepted":       map[string]any{"type": "boolean"},
+		"user_input":     map[string]any{"type": "string"},
+	}
+	policy := map[string]any{"type": "string", "enum": []string{"accept", "dismiss", "wait"}}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_dialog_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{"type": "object"},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"policy":  policy,
+					"dialogs": map[string]any{"type": "array", "items": map[string]any{"type": "object", "properties": dialogProperties}},
+				},
+				"required": []string{"policy", "dialogs"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserDialogList),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_dialog_policy",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"policy": map[string]any{"type": "string", "enum": []string{"accept", "dismiss", "wait"}, "description": "wait leaves dialogs open for browser_handle_dialog"},
+				},
+				"required": []string{"policy"},
+			},
+			Output: mcp.JSONSchema{
+				"type":       "object",
+				"properties": map[string]any{"policy": policy},
+				"required":   []string{"policy"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserDialogPolicy),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_handle_dialog",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"accept":      map[string]any{"type": "boolean", "default": true},
+					"prompt_text": map[string]any{"type": "string", "description": "answer to a prompt dialog"},
+					"tab":         map[string]any{"type": "string"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type":       "object",
+				"properties": dialogProperties,
+				"required":   []string{"tab_id", "type", "message"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserHandleDialog),
+	})
+}
+
+func registerBrowserEmulationTools(registry *mcp.Registry, browserService *browser.Service) {
+	registry.Register(mcp.Tool{
+		Name:    "browser_emulate",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"device": map[string]any{"type": "string", "enum": slices.Sorted(maps.Keys(browser.Devices())), "description": "preset for viewport, scale, touch and user agent"},
+					"viewport": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"width":  map[string]any{"type": "integer"},
+							"height": map[string]any{"type": "integer"},
+						},
+						"required": []string{"width", "height"},
+					},
+					"device_scale_factor": map[string]any{"type": "number"},
+					"mobile":              map[string]any{"type": "boolean"},
+					"touch":               map[string]any{"type": "boolean"},
+					"user_agent":          map[string]any{"type": "string"},
+					"locale":              map[string]any{"type": "string", "description": "e.g. de-DE; also sets Accept-Language"},
+					"timezone":            map[string]any{"type": "string", "description": "IANA id, e.g. Europe/Berlin"},
+					"geolocation": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"latitude":  map[string]any{"type": "number"},
+							"longitude": map[string]any{"type": "number"},
+							"accuracy":  map[string]any{"type": "number", "description": "meters, default 10"},
+						},
+						"required":    []string{"latitude", "longitude"},
+						"description": "also grants the geolocation permission",
+					},
+					"color_scheme":   map[string]any{"type": "string", "enum": []string{"light", "dark", "no-preference"}},
+					"reduced_motion": map[string]any{"type": "string", "enum": []string{"reduce", "no-preference"}},
+					"offline":        map[string]any{"type": "boolean"},
+					"network":        map[string]any{"type": "string", "enum": []string{"slow-3g", "fast-3g", "4g", "no-throttling"}},
+					"tab":            map[string]any{"type": "string", "description": "emulate one tab only; defaults to the whole context"},
+					"reset":          map[string]any{"type": "boolean", "description": "clear the current emulation first"},
+				},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"emulation": map[string]any{"type": "object"},
+				},
+				"required": []string{"emulation"},
+			},
+		},
+		Handler: tools.InBrowserContext(browserService, tools.BrowserEmulate),
+	})
+}
+
+func registerBrowserPoolTools(registry *mcp.Registry, browserService *browser.Service) {
+	browserOutput := mcp.JSONSchema{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
+			"session":    map[string]any{"type": "string"},
+			"expires_at": map[string]any{"type": "string"},
+			"status":     map[string]any{"type": "object"},
+		},
+		"required": []string{"id", "status"},
+	}
+
+	registry.Register(mcp.Tool{
+		Name:    "browser_pool_list",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{"type": "object"},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"browsers": map[string]any{"type": "array"},
+				},
+				"required": []string{"browsers"},
+			},
+		},
+		Handler: tools.BrowserPoolList(browserService),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_pool_lease",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"session":              map[string]any{"type": "string", "description": "returns the browser already leased to this session"},
+					"idle_timeout_seconds": map[string]any{"type": "integer", "description": "release the browser after this long unused"},
+				},
+			},
+			Output: browserOutput,
+		},
+		Handler: tools.BrowserPoolLease(browserService),
+	})
+	registry.Register(mcp.Tool{
+		Name:    "browser_pool_release",
+		Version: "v1",
+		Permissions: mcp.PermissionMeta{
+			Allow: true,
+			Scope: "workspace",
+		},
+		Schema: mcp.ToolSchema{
+			Input: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"browser_id": map[string]any{"type": "string"},
+				},
+				"required": []string{"browser_id"},
+			},
+			Output: mcp.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"released": map[string]any{"type": "string"},
+				},
+				"required": []string{"released"},
+			},
+		},
+		Handler: tools.BrowserPoolRelease(browserService),
+	})
+}
+
+// addBrowserContextParam documents the optional browser_id and context
+// arguments on every browser tool. Route and context management tools act
+// on a whole browser so only take browser_id; pool tools take neither.
+func addBrowserContextParam(registry *mcp.Registry) {
+	browserWide := map[string]bool{
+		"browser_route_add":      true,
+		"browser_route_list":     true,
+		"browser_route_remove":   true,
+		"browser_context_list":   true,
+		"browser_context_create": true,
+		"browser_context_close":  true,
+	}
+	for _, info := range registry.List() {
+		if !strings.HasPrefix(info.Name, "browser") || strings.HasPrefix(info.Name, "browser_pool_") {
+			continue
+		}
+		tool, ok := registry.Get(info.Name)
+		if !ok {
+			continue
+		}
+		input := maps.Clone(tool.Schema.Input)
+		if input == nil {
+			input = mcp.JSONSchema{"type": "object"}
+		}
+		properties, _ := input["properties"].(map[string]any)
+		properties = maps.Clone(properties)
+		if properties == nil {
+			properties = map[string]any{}
+		}
+		properties["browser_id"] = map[string]any{"type": "string", "description": "pooled browser from browser_pool_lease; defaults to the default browser"}
+		if !browserWide[info.Name] {
+			properties["context"] = map[string]any{"type": "string", "description": "named browser context; defaults to the shared default context"}
+		}
+		input["properties"] = properties
+		tool.Schema.Input = input
+		registry.Register(tool)
+	}
+}
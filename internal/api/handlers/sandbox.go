@@ -19,6 +19,8 @@ func SandboxIndexHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
 			"vnc":         "/vnc/index.html",
 			"shell":       "/v1/shell",
 			"file":        "/v1/file",
+			"workspace":   "/v1/workspace",
+			"git":         "/v1/git",
 			"code_exec":   "/v1/code",
 			"jupyter":     "/jupyter",
 			"code_server": "/code-server/",
@@ -20,19 +20,45 @@ const vncHTML = `<!doctype html>
     #toolbar { padding: 8px 12px; background: #1e1e1e; display: flex; gap: 12px; align-items: center; }
     #screen { display: block; margin: 0 auto; max-width: 100vw; max-height: calc(100vh - 48px); cursor: crosshair; }
     #status { font-size: 12px; opacity: 0.8; }
+    #tabs { display: flex; gap: 4px; overflow-x: auto; }
+    #tabs button { max-width: 180px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; background: #333; color: #eee; border: 0; padding: 4px 8px; cursor: pointer; }
+    #tabs button:disabled { background: #555; cursor: default; }
   </style>
 </head>
 <body>
   <div id="toolbar">
     <strong>VNC Takeover</strong>
     <span id="status">connecting...</span>
+    <div id="tabs"></div>
   </div>
   <img id="screen" alt="live screen" />
   <script>
     const statusEl = document.getElementById('status');
     const screen = document.getElementById('screen');
+    const tabsEl = document.getElementById('tabs');
     let lastUpdate = 0;
 
+    async function loadTabs() {
+      const res = await fetch('/vnc/tab/list');
+      if (!res.ok) return;
+      const body = await res.json();
+      tabsEl.replaceChildren(...body.data.tabs.map((tab) => {
+        const button = document.createElement('button');
+        button.textContent = tab.title || tab.url || 'New tab';
+        button.title = tab.url;
+        button.disabled = tab.active;
+        button.addEventListener('click', async () => {
+          await fetch('/vnc/tab/switch', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ id: tab.id })
+          });
+          loadTabs();
+        });
+        return button;
+      }));
+    }
+
     async function refresh() {
       try {
         const ts = Date.now();
@@ -57,8 +83,14 @@ const vncHTML = `<!doctype html>
       });
     });
 
+    const events = new EventSource('/v1/browser/events');
+    ['tab.created', 'tab.closed', 'tab.activated', 'tab.navigated', 'tab.title_changed'].forEach((type) => {
+      events.addEventListener(type, loadTabs);
+    });
+
     setInterval(refresh, 800);
     refresh();
+    loadTabs();
   </script>
 </body>
 </html>`
@@ -91,14 +123,6 @@ type vncEvaluateRequest struct {
 	Expression string `json:"expression"`
 }
 
-type vncTabSwitchRequest struct {
-	Index int `json:"index"`
-}
-
-type vncTabCloseRequest struct {
-	Index int `json:"index"`
-}
-
 type vncTabNewRequest struct {
 	URL string `json:"url"`
 }
@@ -254,11 +278,11 @@ func VNCTabNewHandler(service *browser.Service) api.HandlerFunc {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		index, err := service.NewTab(req.URL)
+		tab, err := service.NewTab(req.URL)
 		if err != nil {
 			return api.NewAppError("tab_new_failed", err.Error(), http.StatusInternalServerError)
 		}
-		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"index": index})); err != nil {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"id": tab.ID, "index": tab.Index})); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
 		}
 		return nil
@@ -267,14 +291,19 @@ func VNCTabNewHandler(service *browser.Service) api.HandlerFunc {
 
 func VNCTabSwitchHandler(service *browser.Service) api.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
-		var req vncTabSwitchRequest
+		var req tabRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		if err := service.SwitchTab(req.Index); err != nil {
+		ref, appErr := req.ref()
+		if appErr != nil {
+			return appErr
+		}
+		tab, err := service.SwitchTab(ref)
+		if err != nil {
 			return api.NewAppError("tab_switch_failed", err.Error(), http.StatusInternalServerError)
 		}
-		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"index": req.Index})); err != nil {
+		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"id": tab.ID, "index": tab.Index})); err != nil {
 			return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
 		}
 		return nil
@@ -296,11 +325,15 @@ func VNCTabListHandler(service *browser.Service) api.HandlerFunc {
 
 func VNCTabCloseHandler(service *browser.Service) api.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) *api.AppError {
-		var req vncTabCloseRequest
+		var req tabRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
 		}
-		if err := service.CloseTab(req.Index); err != nil {
+		ref, appErr := req.ref()
+		if appErr != nil {
+			return appErr
+		}
+		if err := service.CloseTab(ref); err != nil {
 			return api.NewAppError("tab_close_failed", err.Error(), http.StatusInternalServerError)
 		}
 		if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"closed": true})); err != nil {
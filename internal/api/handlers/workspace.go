@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/snapshot"
+	"open-sandbox/pkg/types"
+)
+
+type snapshotCreateRequest struct {
+	Label string `json:"label"`
+}
+
+func RegisterWorkspaceRoutes(router *api.Router) {
+	router.Handle(http.MethodGet, "/v1/workspace/snapshots", SnapshotListHandler)
+	router.Handle(http.MethodPost, "/v1/workspace/snapshots", SnapshotCreateHandler)
+	router.Handle(http.MethodGet, "/v1/workspace/snapshots/diff", SnapshotDiffHandler)
+	router.HandlePrefix(http.MethodGet, "/v1/workspace/snapshots/", SnapshotGetHandler)
+	router.HandlePrefix(http.MethodPost, "/v1/workspace/snapshots/", SnapshotRestoreHandler)
+	router.HandlePrefix(http.MethodDelete, "/v1/workspace/snapshots/", SnapshotDeleteHandler)
+}
+
+func snapshotStore() *snapshot.Store {
+	return snapshot.NewStore(config.SnapshotsPath())
+}
+
+func SnapshotCreateHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	var req snapshotCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return api.NewAppError("bad_request", "invalid request body", http.StatusBadRequest)
+	}
+
+	summary, err := snapshotStore().Create(config.WorkspacePath(), req.Label)
+	if err != nil {
+		return api.NewAppError("snapshot_failed", err.Error(), http.StatusInternalServerError)
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(summary)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func SnapshotListHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	snapshots, err := snapshotStore().List()
+	if err != nil {
+		return api.NewAppError("snapshot_list_failed", err.Error(), http.StatusInternalServerError)
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"snapshots": snapshots})); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func SnapshotDiffHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if strings.TrimSpace(from) == "" {
+		return api.NewAppError("bad_request", "from is required", http.StatusBadRequest)
+	}
+
+	diff, err := snapshotStore().Diff(config.WorkspacePath(), from, to)
+	if err != nil {
+		return snapshotError(err, "snapshot_diff_failed")
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(diff)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func SnapshotGetHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	id := strings.TrimPrefix(r.URL.Path, "/v1/workspace/snapshots/")
+	if id == "" || strings.Contains(id, "/") {
+		return api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+	}
+
+	manifest, err := snapshotStore().Get(id)
+	if err != nil {
+		return snapshotError(err, "snapshot_get_failed")
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(manifest)); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func SnapshotRestoreHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	name := strings.TrimPrefix(r.URL.Path, "/v1/workspace/snapshots/")
+	id, ok := strings.CutSuffix(name, "/restore")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return api.NewAppError("not_found", "not found", http.StatusNotFound)
+	}
+
+	summary, err := snapshotStore().Restore(config.WorkspacePath(), id)
+	if err != nil {
+		return snapshotError(err, "snapshot_restore_failed")
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"restored": summary})); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func SnapshotDeleteHandler(w http.ResponseWriter, r *http.Request) *api.AppError {
+	id := strings.TrimPrefix(r.URL.Path, "/v1/workspace/snapshots/")
+	if id == "" || strings.Contains(id, "/") {
+		return api.NewAppError("bad_request", "invalid path", http.StatusBadRequest)
+	}
+
+	if err := snapshotStore().Delete(id); err != nil {
+		return snapshotError(err, "snapshot_delete_failed")
+	}
+	if err := api.WriteJSON(w, http.StatusOK, types.Ok(map[string]any{"deleted": id})); err != nil {
+		return api.NewAppError(api.CodeInternalError, "internal error", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func snapshotError(err error, code string) *api.AppError {
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return api.NewAppError("not_found", "snapshot not found", http.StatusNotFound)
+	}
+	return api.NewAppError(code, err.Error(), http.StatusInternalServerError)
+}
@@ -1,7 +1,6 @@
 package browser
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,16 +8,20 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
 	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 	"github.com/chromedp/chromedp/kb"
@@ -37,25 +40,99 @@ type Config struct {
 	ScreenshotTimeout      time.Duration
 	Headless               bool
 	DownloadDir            string
-}
-
-type Service struct {
+	RecordingDir           string
+	// FFmpegPath encodes recordings; ffmpeg is looked up on PATH when empty.
+	FFmpegPath string
+	// ContextIdleTimeout closes named contexts unused for this long.
+	ContextIdleTimeout time.Duration
+	// DialogPolicy is accept, dismiss or wait; contexts default to accept.
+	DialogPolicy string
+	// LogDir receives chrome.log with the browser's output; it is
+	// discarded when empty.
+	LogDir string
+	// ExtraArgs are added to the command line of launched browsers.
+	ExtraArgs []string
+	// ScriptDir holds recorded and uploaded action scripts.
+	ScriptDir string
+	// PoolMaxInstances caps the browsers running at once, the default one
+	// included.
+	PoolMaxInstances int
+	// PoolIdleTimeout releases leased browsers unused for this long.
+	PoolIdleTimeout time.Duration
+}
+
+// engine is the browser process and the state shared by every context.
+type engine struct {
 	config Config
 
 	mu          sync.Mutex
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
-	tabCtx      context.Context
-	tabCancel   context.CancelFunc
 	cdpURL      string
-	cmd         *exec.Cmd
 
+	processMu sync.Mutex
+	process   *chromeProcess
+	status    processStatus
+
+	routesMu sync.Mutex
+	routes   []*RouteRule
+	routeSeq int
+
+	main *Service
+	// released is set under mu once a leased browser is given back; it
+	// never starts again.
+	released bool
+
+	contextsMu    sync.Mutex
+	contexts      map[string]*Service
+	contextReaper *idleReaper
+
+	events eventHub
+
+	// pool and primary are shared with the browsers leased from the
+	// default one.
+	pool    *browserPool
+	primary *Service
+}
+
+// Service drives one browser context. NewService returns the default
+// context; Context returns isolated ones that share its browser process.
+type Service struct {
+	*engine
+
+	name             string
+	browserContextID cdp.BrowserContextID
+	idleTimeout      time.Duration
+	createdAt        time.Time
+	lastUsed         time.Time
+	closed           bool
+
+	tabCtx    context.Context
+	tabCancel context.CancelFunc
 	tabs      []tabHandle
 	activeTab int
 
 	downloadsMu sync.Mutex
 	downloads   map[string]*DownloadInfo
 
+	refsMu sync.Mutex
+	refs   elementRefs
+	marks  elementRefs
+
+	recordingsMu    sync.Mutex
+	recordings      []*recording
+	activeRecording *recording
+	recordingSeq    int
+
+	dialogMu     sync.Mutex
+	dialogPolicy string
+
+	// emulation applies to every tab of the context; guarded by mu.
+	emulation EmulationOptions
+
+	scriptMu       sync.Mutex
+	scriptRecorder *scriptRecorder
+
 	mouseMu   sync.Mutex
 	mouseX    float64
 	mouseY    float64
@@ -70,6 +147,49 @@ type tabHandle struct {
 	ctx      context.Context
 	cancel   context.CancelFunc
 	targetID target.ID
+	openerID target.ID
+	state    *tabState
+}
+
+// tabState holds what a tab's event listeners collect.
+type tabState struct {
+	console *ringBuffer[ConsoleEntry]
+	network *networkLog
+
+	infoMu sync.Mutex
+	url    string
+	title  string
+
+	recorderMu sync.Mutex
+	recorder   *recording
+
+	lifecycleMu sync.Mutex
+	loaderID    cdp.LoaderID
+	lifecycle   map[string]bool
+
+	fileChooserMu sync.Mutex
+	fileChooser   chan *page.EventFileChooserOpened
+
+	dialogMu      sync.Mutex
+	dialog        *Dialog
+	dialogCtx     context.Context
+	dialogWaiting bool
+	interrupt     context.CancelFunc
+
+	// emulation overrides the context's for this tab; guarded by the
+	// service's mu.
+	emulation EmulationOptions
+
+	// scriptRecorderID is the injected script recorder; guarded by the
+	// service's mu.
+	scriptRecorderID page.ScriptIdentifier
+}
+
+func newTabState() *tabState {
+	return &tabState{
+		console: newRingBuffer[ConsoleEntry](consoleBufferSize),
+		network: newNetworkLog(),
+	}
 }
 
 type DownloadInfo struct {
@@ -116,17 +236,48 @@ func NewService(config Config) *Service {
 	if config.ScreenshotTimeout == 0 {
 		config.ScreenshotTimeout = 15 * time.Second
 	}
-	return &Service{
-		config:    config,
+	if config.ContextIdleTimeout == 0 {
+		config.ContextIdleTimeout = DefaultContextIdleTimeout
+	}
+	if config.PoolMaxInstances == 0 {
+		config.PoolMaxInstances = DefaultPoolMaxInstances
+	}
+	if config.PoolIdleTimeout == 0 {
+		config.PoolIdleTimeout = DefaultPoolIdleTimeout
+	}
+	pool := &browserPool{instances: make(map[string]*poolInstance)}
+	service := newService(config, pool, nil)
+	pool.reaper = newIdleReaper(service.sweepBrowsers)
+	return service
+}
+
+// newService builds the engine of one browser process. primary is nil for
+// the default browser.
+func newService(config Config, pool *browserPool, primary *Service) *Service {
+	shared := &engine{config: config, contexts: make(map[string]*Service), pool: pool, primary: primary}
+	shared.main = &Service{
+		engine:    shared,
+		name:      DefaultContextName,
+		createdAt: time.Now(),
 		downloads: make(map[string]*DownloadInfo),
 	}
+	shared.contextReaper = newIdleReaper(shared.main.sweepContexts)
+	if primary == nil {
+		shared.primary = shared.main
+	}
+	return shared.main
 }
 
 func (service *Service) Start() error {
 	return service.ensureStarted()
 }
 
+// Close shuts down the browser and every context. Closing the default
+// browser also releases the browsers leased from it.
 func (service *Service) Close() {
+	if service.primary == service.main {
+		service.releaseBrowsers()
+	}
 	service.mu.Lock()
 	defer service.mu.Unlock()
 
@@ -149,27 +300,6 @@ func (service *Service) Navigate(url string) error {
 	})
 }
 
-func (service *Service) Screenshot(path string) error {
-	data, err := service.ScreenshotPNG()
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
-}
-
-func (service *Service) ScreenshotPNG() ([]byte, error) {
-	var buf []byte
-	if err := service.runTabAction(service.config.ScreenshotTimeout, func(ctx context.Context) error {
-		return chromedp.Run(ctx, chromedp.CaptureScreenshot(&buf))
-	}); err != nil {
-		return nil, err
-	}
-	return buf, nil
-}
-
 func (service *Service) Click(x, y float64) error {
 	return service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
 		return chromedp.Run(ctx, chromedp.MouseClickXY(x, y))
@@ -365,119 +495,6 @@ func (service *Service) SetResolution(width, height int) error {
 	})
 }
 
-func (service *Service) NewTab(url string) (int, error) {
-	service.mu.Lock()
-	defer service.mu.Unlock()
-
-	if err := service.ensureStartedLocked(); err != nil {
-		return -1, err
-	}
-
-	handle, err := service.createTabLocked()
-	if err != nil {
-		return -1, err
-	}
-	service.tabs = append(service.tabs, handle)
-	service.activeTab = len(service.tabs) - 1
-	service.tabCtx = handle.ctx
-	service.tabCancel = handle.cancel
-
-	if url != "" {
-		if err := runWithTimeout(handle.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
-			_, _, _, err := page.Navigate(url).Do(ctx)
-			return err
-		}); err != nil {
-			return -1, err
-		}
-	}
-	return service.activeTab, nil
-}
-
-func (service *Service) SwitchTab(index int) error {
-	service.mu.Lock()
-	defer service.mu.Unlock()
-
-	if err := service.ensureStartedLocked(); err != nil {
-		return err
-	}
-	if index < 0 || index >= len(service.tabs) {
-		return errors.New("invalid tab index")
-	}
-	handle := service.tabs[index]
-	if handle.ctx == nil || handle.ctx.Err() != nil {
-		return errors.New("tab unavailable")
-	}
-	service.activeTab = index
-	service.tabCtx = handle.ctx
-	service.tabCancel = handle.cancel
-	return nil
-}
-
-func (service *Service) CloseTab(index int) error {
-	service.mu.Lock()
-	defer service.mu.Unlock()
-
-	if err := service.ensureStartedLocked(); err != nil {
-		return err
-	}
-	if index < 0 || index >= len(service.tabs) {
-		return errors.New("invalid tab index")
-	}
-	handle := service.tabs[index]
-	if handle.ctx != nil {
-		_ = target.CloseTarget(handle.targetID).Do(handle.ctx)
-		handle.cancel()
-	}
-	service.tabs = append(service.tabs[:index], service.tabs[index+1:]...)
-	if len(service.tabs) == 0 {
-		newHandle, err := service.createTabLocked()
-		if err != nil {
-			return err
-		}
-		service.tabs = append(service.tabs, newHandle)
-	}
-	if service.activeTab >= len(service.tabs) {
-		service.activeTab = len(service.tabs) - 1
-	}
-	active := service.tabs[service.activeTab]
-	service.tabCtx = active.ctx
-	service.tabCancel = active.cancel
-	return nil
-}
-
-type TabInfo struct {
-	Index int    `json:"index"`
-	Title string `json:"title"`
-	URL   string `json:"url"`
-}
-
-func (service *Service) TabList() ([]TabInfo, error) {
-	service.mu.Lock()
-	defer service.mu.Unlock()
-
-	if err := service.ensureStartedLocked(); err != nil {
-		return nil, err
-	}
-
-	results := make([]TabInfo, 0, len(service.tabs))
-	for i, tab := range service.tabs {
-		if tab.ctx == nil || tab.ctx.Err() != nil {
-			results = append(results, TabInfo{Index: i})
-			continue
-		}
-		var title string
-		var url string
-		_ = runWithTimeout(tab.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
-			return chromedp.Run(ctx,
-				chromedp.Evaluate("document.title", &title),
-				chromedp.Evaluate("location.href", &url),
-			)
-		})
-		results = append(results, TabInfo{Index: i, Title: title, URL: url})
-	}
-	return results, nil
-}
-
 func (service *Service) UserAgent() (string, error) {
 	var ua string
 	if err := service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
@@ -521,17 +538,35 @@ func (service *Service) ensureStarted() error {
 }
 
 func (service *Service) ensureStartedLocked() error {
+	if service.released {
+		return fmt.Errorf("%w: it was released", ErrBrowserNotFound)
+	}
+	if service.closed {
+		return ErrContextNotFound
+	}
+	service.touch()
 	if service.isTabHealthyLocked() {
 		return nil
 	}
+	// A named context whose tabs went away is reopened on the running
+	// browser; anything else restarts the browser.
+	if service != service.main && service.main.isTabHealthyLocked() {
+		service.clearLocked()
+		return service.openContextLocked()
+	}
 
 	service.resetLocked()
 
+	var err error
 	if service.config.ExistingWebSocketDebug != "" {
-		return service.connectRemote(service.config.ExistingWebSocketDebug)
+		err = service.connectRemote(service.config.ExistingWebSocketDebug)
+	} else {
+		err = service.launchBrowser()
 	}
-
-	return service.launchBrowser()
+	if err != nil || service == service.main {
+		return err
+	}
+	return service.openContextLocked()
 }
 
 func (service *Service) connectRemote(wsURL string) error {
@@ -546,25 +581,24 @@ func (service *Service) connectRemote(wsURL string) error {
 	service.allocCtx = allocCtx
 	service.allocCancel = allocCancel
 	service.cdpURL = wsURL
-	service.downloads = make(map[string]*DownloadInfo)
+	service.recordConnected(tabCtx)
 
-	handle, err := service.setupTabLocked(tabCtx, tabCancel)
+	main := service.main
+	handle, err := main.setupTabLocked(tabCtx, tabCancel)
 	if err != nil {
 		tabCancel()
 		allocCancel()
 		return err
 	}
-	service.tabs = []tabHandle{handle}
-	service.activeTab = 0
-	service.tabCtx = handle.ctx
-	service.tabCancel = handle.cancel
+	main.tabs = nil
+	main.activateTabLocked(main.addTabLocked(handle))
 	return nil
 }
 
 func (service *Service) launchBrowser() error {
 	binary := service.config.BinaryPath
 	if binary == "" {
-		binary = detectChromeBinary()
+		binary = DetectBinary()
 	}
 	if binary == "" {
 		return ErrBrowserUnavailable
@@ -604,37 +638,6 @@ func (service *Service) launchBrowser() error {
 	return nil
 }
 
-func (service *Service) startChromeProcess(binary string, userDataDir string) error {
-	args := []string{
-		"--no-first-run",
-		"--no-default-browser-check",
-		"--disable-background-networking",
-		"--disable-client-side-phishing-detection",
-		"--disable-component-update",
-		"--disable-default-apps",
-		"--disable-sync",
-		"--disable-translate",
-		"--disable-popup-blocking",
-		"--remote-debugging-address=" + service.config.RemoteDebuggingHost,
-		"--remote-debugging-port=" + fmt.Sprintf("%d", service.config.RemoteDebuggingPort),
-		"--user-data-dir=" + userDataDir,
-		"--disable-gpu",
-		"about:blank",
-	}
-	if service.config.Headless {
-		args = append(args, "--headless=new")
-	}
-
-	cmd := exec.Command(binary, args...)
-	cmd.Stdout = &bytes.Buffer{}
-	cmd.Stderr = cmd.Stdout
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-	service.cmd = cmd
-	return nil
-}
-
 func (service *Service) runTabAction(timeout time.Duration, action func(ctx context.Context) error) error {
 	service.mu.Lock()
 	defer service.mu.Unlock()
@@ -643,7 +646,7 @@ func (service *Service) runTabAction(timeout time.Duration, action func(ctx cont
 		return err
 	}
 
-	err := runWithTimeout(service.tabCtx, timeout, action)
+	err := runWithTimeout(service.tabCtx, timeout, service.activeTabStateLocked().guardDialog(action))
 	if err == nil {
 		return nil
 	}
@@ -651,11 +654,39 @@ func (service *Service) runTabAction(timeout time.Duration, action func(ctx cont
 		return err
 	}
 
-	service.resetLocked()
+	// Named contexts only replace their own tabs so other contexts keep
+	// running.
+	if service == service.main {
+		service.resetLocked()
+	} else {
+		service.clearLocked()
+	}
 	if err := service.ensureStartedLocked(); err != nil {
 		return err
 	}
-	return runWithTimeout(service.tabCtx, timeout, action)
+	return runWithTimeout(service.tabCtx, timeout, service.activeTabStateLocked().guardDialog(action))
+}
+
+// runOnTab runs action against the tab ref selects without switching to
+// it; the empty ref runs against the active tab.
+func (service *Service) runOnTab(ref TabRef, timeout time.Duration, action func(ctx context.Context) error) error {
+	if ref == "" {
+		return service.runTabAction(timeout, action)
+	}
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return err
+	}
+	index, err := service.tabIndexLocked(ref)
+	if err != nil {
+		return err
+	}
+	handle := service.tabs[index]
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return errors.New("tab unavailable")
+	}
+	return runWithTimeout(handle.ctx, timeout, handle.state.guardDialog(action))
 }
 
 func runWithTimeout(parent context.Context, timeout time.Duration, action func(ctx context.Context) error) error {
@@ -678,13 +709,32 @@ func (service *Service) isTabHealthyLocked() bool {
 	if err := service.tabCtx.Err(); err != nil {
 		return false
 	}
-	if service.cmd != nil && service.cmd.ProcessState != nil && service.cmd.ProcessState.Exited() {
+	if service.processExited() {
 		return false
 	}
 	return true
 }
 
+// resetLocked stops the browser and clears every context; named contexts
+// get a new browser context the next time they are used.
 func (service *Service) resetLocked() {
+	for _, browserContext := range service.allContextsLocked() {
+		browserContext.clearLocked()
+		browserContext.browserContextID = ""
+	}
+	if service.allocCancel != nil {
+		service.allocCancel()
+		service.allocCancel = nil
+	}
+	service.allocCtx = nil
+	service.cdpURL = ""
+	service.recordDisconnected()
+	service.stopChromeProcess()
+}
+
+// clearLocked closes the tabs of this context and forgets what they
+// collected.
+func (service *Service) clearLocked() {
 	for _, tab := range service.tabs {
 		if tab.cancel != nil {
 			tab.cancel()
@@ -694,25 +744,30 @@ func (service *Service) resetLocked() {
 	service.activeTab = 0
 	service.tabCtx = nil
 	service.tabCancel = nil
-	if service.allocCancel != nil {
-		service.allocCancel()
-		service.allocCancel = nil
-	}
-	service.allocCtx = nil
-	service.cdpURL = ""
-	service.stopChromeProcess()
+	service.downloadsMu.Lock()
 	service.downloads = make(map[string]*DownloadInfo)
+	service.downloadsMu.Unlock()
+	service.mouseMu.Lock()
 	service.hasMouse = false
+	service.mouseMu.Unlock()
+	service.refsMu.Lock()
+	service.refs = elementRefs{}
+	service.marks = elementRefs{}
+	service.refsMu.Unlock()
 }
 
 func (service *Service) ensureDownloadDirLocked() (string, error) {
 	if service.config.DownloadDir == "" {
 		service.config.DownloadDir = filepath.Join(os.TempDir(), "open-sandbox-downloads")
 	}
-	if err := os.MkdirAll(service.config.DownloadDir, 0755); err != nil {
+	dir := service.config.DownloadDir
+	if service != service.main {
+		dir = filepath.Join(dir, "contexts", service.name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", err
 	}
-	return service.config.DownloadDir, nil
+	return dir, nil
 }
 
 func (service *Service) setupTabLocked(ctx context.Context, cancel context.CancelFunc) (tabHandle, error) {
@@ -720,8 +775,36 @@ func (service *Service) setupTabLocked(ctx context.Context, cancel context.Cance
 	if err != nil {
 		return tabHandle{}, err
 	}
+	targetID := target.ID("")
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+		targetID = c.Target.TargetID
+	}
+	state := newTabState()
 	chromedp.ListenTarget(ctx, func(ev interface{}) {
 		switch e := ev.(type) {
+		case *target.EventTargetCreated, *target.EventTargetInfoChanged, *target.EventTargetDestroyed:
+			service.handleTargetEvent(targetID, state, ev)
+		case *runtime.EventConsoleAPICalled, *runtime.EventExceptionThrown, *log.EventEntryAdded:
+			state.handleConsoleEvent(ev)
+		case *network.EventRequestWillBeSent, *network.EventResponseReceived, *network.EventRequestServedFromCache,
+			*network.EventDataReceived, *network.EventLoadingFinished, *network.EventLoadingFailed:
+			state.network.handleEvent(ev)
+		case *fetch.EventRequestPaused:
+			go service.handleRequestPaused(ctx, e)
+		case *page.EventScreencastFrame:
+			go state.handleScreencastFrame(ctx, e)
+		case *page.EventLifecycleEvent:
+			state.handleLifecycleEvent(cdp.FrameID(targetID), e)
+		case *page.EventFileChooserOpened:
+			state.handleFileChooser(e)
+		case *page.EventJavascriptDialogOpening:
+			service.handleDialogOpening(ctx, string(targetID), state, e)
+		case *page.EventJavascriptDialogClosed:
+			service.handleDialogClosed(string(targetID), state, e)
+		case *runtime.EventBindingCalled:
+			if e.Name == scriptBinding {
+				service.handleScriptBinding(e.Payload)
+			}
 		case *browser.EventDownloadWillBegin:
 			filename := e.SuggestedFilename
 			if filename == "" {
@@ -737,7 +820,9 @@ func (service *Service) setupTabLocked(ctx context.Context, cancel context.Cance
 			}
 			service.downloadsMu.Lock()
 			service.downloads[e.GUID] = info
+			started := *info
 			service.downloadsMu.Unlock()
+			service.publish(Event{Type: EventDownloadStarted, TabID: string(targetID), URL: e.URL, Download: &started})
 		case *browser.EventDownloadProgress:
 			service.downloadsMu.Lock()
 			info := service.downloads[e.GUID]
@@ -748,30 +833,57 @@ func (service *Service) setupTabLocked(ctx context.Context, cancel context.Cance
 			info.TotalBytes = e.TotalBytes
 			info.ReceivedBytes = e.ReceivedBytes
 			info.State = e.State.String()
-			if e.State == browser.DownloadProgressStateCompleted || e.State == browser.DownloadProgressStateCanceled {
+			finished := e.State == browser.DownloadProgressStateCompleted || e.State == browser.DownloadProgressStateCanceled
+			if finished {
 				info.FinishedAt = time.Now()
 			}
+			snapshot := *info
 			service.downloadsMu.Unlock()
+			if finished {
+				service.publish(Event{Type: EventDownloadFinished, TabID: string(targetID), URL: snapshot.URL, Download: &snapshot})
+			}
 		}
 	})
 
-	if err := chromedp.Run(ctx,
-		browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllow).
-			WithDownloadPath(downloadDir).
-			WithEventsEnabled(true),
-	); err != nil {
+	downloadBehavior := browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllow).
+		WithDownloadPath(downloadDir).
+		WithEventsEnabled(true)
+	if service.browserContextID != "" {
+		downloadBehavior = downloadBehavior.WithBrowserContextID(service.browserContextID)
+	}
+	if err := chromedp.Run(ctx, downloadBehavior, page.SetLifecycleEventsEnabled(true)); err != nil {
+		return tabHandle{}, err
+	}
+	if service.hasRoutes() {
+		if err := chromedp.Run(ctx, setInterception(true)); err != nil {
+			return tabHandle{}, err
+		}
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return service.applyEmulation(ctx, service.emulation)
+	})); err != nil {
 		return tabHandle{}, err
 	}
 
-	targetID := target.ID("")
-	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
-		targetID = c.Target.TargetID
+	handle := tabHandle{ctx: ctx, cancel: cancel, targetID: targetID, state: state}
+	// A tab that fails to record should still open.
+	if service.activeScriptRecorder() != nil {
+		_ = installScriptRecorder(handle, service.config.NavigateTimeout)
 	}
-	return tabHandle{ctx: ctx, cancel: cancel, targetID: targetID}, nil
+	return handle, nil
+}
+
+// newTargetLocked returns a chromedp context whose first Run opens a tab
+// in this browser context.
+func (service *Service) newTargetLocked() (context.Context, context.CancelFunc) {
+	if service.browserContextID != "" {
+		return chromedp.NewContext(service.allocCtx, chromedp.WithExistingBrowserContext(service.browserContextID))
+	}
+	return chromedp.NewContext(service.allocCtx)
 }
 
 func (service *Service) createTabLocked() (tabHandle, error) {
-	tabCtx, tabCancel := chromedp.NewContext(service.allocCtx)
+	tabCtx, tabCancel := service.newTargetLocked()
 	if err := chromedp.Run(tabCtx); err != nil {
 		tabCancel()
 		return tabHandle{}, err
@@ -833,15 +945,6 @@ func normalizeKey(key string) string {
 	}
 }
 
-func (service *Service) stopChromeProcess() {
-	if service.cmd == nil || service.cmd.Process == nil {
-		return
-	}
-	_ = service.cmd.Process.Kill()
-	_, _ = service.cmd.Process.Wait()
-	service.cmd = nil
-}
-
 func fetchWebSocketURL(host string, port int, timeout time.Duration) (string, error) {
 	url := fmt.Sprintf("http://%s:%d/json/version", host, port)
 	if timeout <= 0 {
@@ -888,20 +991,6 @@ func minDuration(a time.Duration, b time.Duration) time.Duration {
 	return b
 }
 
-func detectChromeBinary() string {
-	candidates := []string{
-		`C:\Program Files\Google\Chrome\Application\chrome.exe`,
-		`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
-		`C:\Program Files\Chromium\Application\chrome.exe`,
-	}
-	for _, candidate := range candidates {
-		if _, err := os.Stat(candidate); err == nil {
-			return candidate
-		}
-	}
-	return ""
-}
-
 func pickFreePort() (int, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -0,0 +1,232 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/runtime"
+)
+
+const (
+	consoleBufferSize   = 1000
+	DefaultConsoleLimit = 200
+)
+
+const (
+	ConsoleLevelDebug   = "debug"
+	ConsoleLevelInfo    = "info"
+	ConsoleLevelWarning = "warning"
+	ConsoleLevelError   = "error"
+)
+
+var consoleLevelRank = map[string]int{
+	ConsoleLevelDebug:   0,
+	ConsoleLevelInfo:    1,
+	ConsoleLevelWarning: 2,
+	ConsoleLevelError:   3,
+}
+
+type ConsoleEntry struct {
+	Seq   uint64    `json:"seq"`
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	// Source is "console", "exception", or the Log domain source such as
+	// "network" or "security".
+	Source string `json:"source"`
+	Type   string `json:"type,omitempty"`
+	Text   string `json:"text"`
+	URL    string `json:"url,omitempty"`
+	Line   int64  `json:"line,omitempty"`
+	Column int64  `json:"column,omitempty"`
+	Stack  string `json:"stack,omitempty"`
+}
+
+type ConsoleQuery struct {
+	// Tab selects a tab; empty means the active tab.
+	Tab TabRef
+	// Level is the minimum level to return.
+	Level string
+	Since uint64
+	Limit int
+}
+
+type ConsoleLogs struct {
+	Entries []ConsoleEntry `json:"entries"`
+	// Cursor is passed back as Since to read only newer entries.
+	Cursor uint64 `json:"cursor"`
+	// Dropped reports that entries after Since were evicted from the buffer.
+	Dropped bool `json:"dropped"`
+}
+
+func (state *tabState) handleConsoleEvent(ev any) {
+	var entry ConsoleEntry
+	switch e := ev.(type) {
+	case *runtime.EventConsoleAPICalled:
+		entry = ConsoleEntry{
+			Level:  consoleAPILevel(e.Type),
+			Source: "console",
+			Type:   string(e.Type),
+			Text:   remoteObjectsText(e.Args),
+		}
+		if frame := topFrame(e.StackTrace); frame != nil {
+			entry.URL, entry.Line, entry.Column = frame.URL, frame.LineNumber+1, frame.ColumnNumber+1
+		}
+		if e.Type == runtime.APITypeError || e.Type == runtime.APITypeAssert || e.Type == runtime.APITypeTrace {
+			entry.Stack = formatStackTrace(e.StackTrace)
+		}
+	case *runtime.EventExceptionThrown:
+		details := e.ExceptionDetails
+		if details == nil {
+			return
+		}
+		entry = ConsoleEntry{
+			Level:  ConsoleLevelError,
+			Source: "exception",
+			Text:   exceptionText(details),
+			URL:    details.URL,
+			Line:   details.LineNumber + 1,
+			Column: details.ColumnNumber + 1,
+			Stack:  formatStackTrace(details.StackTrace),
+		}
+	case *log.EventEntryAdded:
+		if e.Entry == nil {
+			return
+		}
+		entry = ConsoleEntry{
+			Level:  logEntryLevel(e.Entry.Level),
+			Source: string(e.Entry.Source),
+			Text:   e.Entry.Text,
+			URL:    e.Entry.URL,
+			Line:   e.Entry.LineNumber,
+			Stack:  formatStackTrace(e.Entry.StackTrace),
+		}
+	default:
+		return
+	}
+	entry.Time = time.Now()
+	state.console.push(func(seq uint64) ConsoleEntry {
+		entry.Seq = seq
+		return entry
+	})
+}
+
+// ConsoleLogs reads console output, uncaught exceptions and browser log
+// entries captured for a tab since it was opened.
+func (service *Service) ConsoleLogs(query ConsoleQuery) (*ConsoleLogs, error) {
+	minRank := 0
+	if query.Level != "" {
+		rank, ok := consoleLevelRank[strings.ToLower(query.Level)]
+		if !ok {
+			return nil, fmt.Errorf("%w: level must be debug, info, warning or error", ErrInvalidOption)
+		}
+		minRank = rank
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultConsoleLimit
+	}
+	state, err := service.tabStateFor(query.Tab)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return &ConsoleLogs{Entries: []ConsoleEntry{}, Cursor: query.Since}, nil
+	}
+	entries, cursor, dropped := state.console.since(query.Since, query.Limit, func(entry ConsoleEntry) bool {
+		return consoleLevelRank[entry.Level] >= minRank
+	})
+	return &ConsoleLogs{Entries: entries, Cursor: cursor, Dropped: dropped}, nil
+}
+
+// tabStateFor returns the collected state of the tab ref selects. It does
+// not start the browser; a nil state means nothing has been captured yet.
+func (service *Service) tabStateFor(ref TabRef) (*tabState, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if ref == "" && len(service.tabs) == 0 {
+		return nil, nil
+	}
+	index, err := service.tabIndexLocked(ref)
+	if err != nil {
+		return nil, err
+	}
+	return service.tabs[index].state, nil
+}
+
+func consoleAPILevel(kind runtime.APIType) string {
+	switch kind {
+	case runtime.APITypeDebug:
+		return ConsoleLevelDebug
+	case runtime.APITypeWarning:
+		return ConsoleLevelWarning
+	case runtime.APITypeError, runtime.APITypeAssert:
+		return ConsoleLevelError
+	}
+	return ConsoleLevelInfo
+}
+
+func logEntryLevel(level log.Level) string {
+	switch level {
+	case log.LevelVerbose:
+		return ConsoleLevelDebug
+	case log.LevelWarning:
+		return ConsoleLevelWarning
+	case log.LevelError:
+		return ConsoleLevelError
+	}
+	return ConsoleLevelInfo
+}
+
+func remoteObjectsText(args []*runtime.RemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, remoteObjectText(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func remoteObjectText(object *runtime.RemoteObject) string {
+	if object == nil {
+		return ""
+	}
+	if object.UnserializableValue != "" {
+		return string(object.UnserializableValue)
+	}
+	if len(object.Value) > 0 {
+		if object.Type == runtime.TypeString {
+			var text string
+			if err := json.Unmarshal(object.Value, &text); err == nil {
+				return text
+			}
+		}
+		return string(object.Value)
+	}
+	if object.Description != "" {
+		return object.Description
+	}
+	return string(object.Type)
+}
+
+func topFrame(trace *runtime.StackTrace) *runtime.CallFrame {
+	if trace == nil || len(trace.CallFrames) == 0 {
+		return nil
+	}
+	return trace.CallFrames[0]
+}
+
+func formatStackTrace(trace *runtime.StackTrace) string {
+	if trace == nil || len(trace.CallFrames) == 0 {
+		return ""
+	}
+	var out strings.Builder
+	for _, frame := range trace.CallFrames {
+		name := frame.FunctionName
+		if name == "" {
+			name = "<anonymous>"
+		}
+		fmt.Fprintf(&out, "    at %s (%s:%d:%d)\n", name, frame.URL, frame.LineNumber+1, frame.ColumnNumber+1)
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
@@ -0,0 +1,286 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	ContentFormatMarkdown = "markdown"
+	ContentFormatText     = "text"
+	ContentFormatHTML     = "html"
+
+	DefaultContentMaxChars = 100000
+)
+
+var ErrInvalidOption = errors.New("invalid option")
+
+type ContentOptions struct {
+	Format   string `json:"format,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	// MaxChars caps Content; links and forms are not counted.
+	MaxChars int `json:"max_chars,omitempty"`
+}
+
+type PageLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+type FormField struct {
+	Name     string   `json:"name,omitempty"`
+	Type     string   `json:"type"`
+	Label    string   `json:"label,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type PageForm struct {
+	ID     string      `json:"id,omitempty"`
+	Action string      `json:"action,omitempty"`
+	Method string      `json:"method"`
+	Fields []FormField `json:"fields"`
+}
+
+// PageContent is what Content extracted. Length counts the characters of
+// Content after truncation.
+type PageContent struct {
+	URL       string     `json:"url"`
+	Title     string     `json:"title"`
+	Format    string     `json:"format"`
+	Content   string     `json:"content"`
+	Length    int        `json:"length"`
+	Truncated bool       `json:"truncated"`
+	Links     []PageLink `json:"links"`
+	Forms     []PageForm `json:"forms"`
+}
+
+// Content extracts the readable part of the active page. Without a
+// selector it prefers <main>, <article> or role=main and falls back to the
+// body minus navigation chrome. Links and forms come from the same scope.
+func (service *Service) Content(options ContentOptions) (*PageContent, error) {
+	switch options.Format {
+	case "":
+		options.Format = ContentFormatMarkdown
+	case ContentFormatMarkdown, ContentFormatText, ContentFormatHTML:
+	default:
+		return nil, fmt.Errorf("%w: format must be markdown, text or html", ErrInvalidOption)
+	}
+	if options.MaxChars <= 0 {
+		options.MaxChars = DefaultContentMaxChars
+	}
+	spec, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var content struct {
+		PageContent
+		SelectorError string `json:"selectorError"`
+	}
+	err = service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			result, exception, err := runtime.Evaluate("(" + extractContentJS + ")(" + string(spec) + ")").
+				WithReturnByValue(true).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			if exception != nil {
+				return fmt.Errorf("extract content: %s", exceptionText(exception))
+			}
+			return json.Unmarshal(result.Value, &content)
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.SelectorError != "" {
+		return nil, fmt.Errorf("%w: selector %q: %s", ErrInvalidLocator, options.Selector, content.SelectorError)
+	}
+	if content.URL == "" && options.Selector != "" {
+		return nil, fmt.Errorf("%w: selector %q matched no element", ErrInvalidLocator, options.Selector)
+	}
+
+	page := content.PageContent
+	page.Format = options.Format
+	page.Content = strings.TrimSpace(page.Content)
+	page.Length = utf8.RuneCountInString(page.Content)
+	if page.Length > options.MaxChars {
+		page.Content = string([]rune(page.Content)[:options.MaxChars])
+		page.Length = options.MaxChars
+		page.Truncated = true
+	}
+	if page.Links == nil {
+		page.Links = []PageLink{}
+	}
+	if page.Forms == nil {
+		page.Forms = []PageForm{}
+	}
+	return &page, nil
+}
+
+// extractContentJS returns an empty object when the selector matches
+// nothing so the caller can tell that apart from an empty page, and
+// selectorError when it is not a valid selector. It stops collecting text
+// once max_chars is reached so large pages are not sent over CDP whole;
+// Content trims the result exactly.
+const extractContentJS = `function(spec) {
+  const normalize = (value) => (value || '').replace(/\s+/g, ' ').trim();
+  const plain = spec.format === 'text';
+  let root;
+  let chrome = null;
+  if (spec.selector) {
+    try {
+      root = document.querySelector(spec.selector);
+    } catch (error) {
+      return { selectorError: error.message };
+    }
+    if (!root) return {};
+  } else {
+    root = document.querySelector('main, article, [role="main"]');
+    if (!root) {
+      root = document.body;
+      chrome = 'nav, header, footer, aside, [role="navigation"], [role="banner"], [role="contentinfo"]';
+    }
+  }
+  // localName, since tagName is lowercase for SVG elements.
+  const skip = new Set(['script', 'style', 'noscript', 'template', 'svg', 'canvas', 'iframe']);
+  const excluded = (el) => skip.has(el.localName) || (chrome && el.matches(chrome)) || getComputedStyle(el).display === 'none';
+  const inChrome = (el) => chrome && el.closest(chrome);
+  let budget = spec.max_chars;
+  const spend = (text) => {
+    budget -= text.length;
+    return text;
+  };
+
+  const inline = (node) => {
+    if (budget < 0) return '';
+    if (node.nodeType === Node.TEXT_NODE) return spend(node.textContent.replace(/\s+/g, ' '));
+    if (node.nodeType !== Node.ELEMENT_NODE || excluded(node)) return '';
+    const inner = () => Array.from(node.childNodes).map(inline).join('');
+    switch (node.tagName) {
+      case 'A': {
+        const text = normalize(inner());
+        const href = node.getAttribute('href');
+        if (plain || !href || href.startsWith('javascript:') || !text) return text;
+        return '[' + text + '](' + node.href + ')';
+      }
+      case 'STRONG': case 'B': { const t = normalize(inner()); return t && !plain ? '**' + t + '** ' : t; }
+      case 'EM': case 'I': { const t = normalize(inner()); return t && !plain ? '_' + t + '_ ' : t; }
+      case 'CODE': return plain ? spend(node.textContent) : '` + "`" + `' + spend(node.textContent) + '` + "`" + `';
+      case 'IMG': { const alt = normalize(node.getAttribute('alt')); return alt && !plain ? '![' + alt + '](' + node.src + ')' : alt; }
+      case 'BR': return '\n';
+    }
+    return block(node) ? '\n\n' + markdown(node) + '\n\n' : inner();
+  };
+  const blockTags = new Set(['P', 'DIV', 'SECTION', 'ARTICLE', 'MAIN', 'HEADER', 'FOOTER', 'NAV', 'ASIDE', 'UL', 'OL', 'LI',
+    'H1', 'H2', 'H3', 'H4', 'H5', 'H6', 'PRE', 'BLOCKQUOTE', 'TABLE', 'HR', 'FORM', 'FIGURE', 'DL', 'DT', 'DD']);
+  const block = (node) => blockTags.has(node.tagName);
+
+  // Preformatted output is parked behind placeholders so the whitespace
+  // collapsing of enclosing blocks cannot strip its indentation.
+  const preserved = [];
+  const keep = (text) => '\u0000' + (preserved.push(text) - 1) + '\u0000';
+  const markdown = (node, depth = 0) => {
+    const children = () => Array.from(node.childNodes).map(inline).join('');
+    const tag = node.tagName;
+    if (/^H[1-6]$/.test(tag)) return (plain ? '' : '#'.repeat(Number(tag[1])) + ' ') + normalize(children());
+    switch (tag) {
+      case 'PRE': {
+        const code = spend(node.textContent.replace(/\n$/, ''));
+        return keep(plain ? code : '` + "```" + `\n' + code + '\n` + "```" + `');
+      }
+      case 'BLOCKQUOTE': return plain ? collapse(children()) : collapse(children()).split('\n').map((line) => '> ' + line).join('\n');
+      case 'HR': return plain ? '' : '---';
+      case 'UL': case 'OL': {
+        let index = 1;
+        return keep(Array.from(node.children).filter((li) => li.tagName === 'LI').map((li) => {
+          const marker = tag === 'OL' ? (index++) + '. ' : '- ';
+          const nested = [];
+          const text = Array.from(li.childNodes).map((child) => {
+            if (child.nodeType === Node.ELEMENT_NODE && (child.tagName === 'UL' || child.tagName === 'OL')) {
+              nested.push(markdown(child, depth + 1));
+              return '';
+            }
+            return inline(child);
+          }).join('');
+          return ['  '.repeat(depth) + marker + collapse(text).replace(/\n+/g, ' '), ...nested].join('\n');
+        }).join('\n'));
+      }
+      case 'TABLE': {
+        const rows = Array.from(node.querySelectorAll('tr')).map((tr) =>
+          Array.from(tr.children).map((cell) => normalize(Array.from(cell.childNodes).map(inline).join('')).replace(/\|/g, '\\|')));
+        if (!rows.length) return '';
+        const width = Math.max(...rows.map((row) => row.length));
+        const line = (row) => '| ' + Array.from({ length: width }, (_, i) => row[i] || '').join(' | ') + ' |';
+        return keep([line(rows[0]), line(Array(width).fill('---')), ...rows.slice(1).map(line)].join('\n'));
+      }
+    }
+    return collapse(children());
+  };
+  const collapse = (text) => text.split('\n').map((line) => line.replace(/[ \t]+/g, ' ').trim()).join('\n').replace(/\n{3,}/g, '\n\n').trim();
+
+  let content;
+  if (spec.format === 'html') {
+    if (chrome) {
+      const copy = root.cloneNode(true);
+      copy.querySelectorAll(chrome + ', script, style, noscript, template').forEach((el) => el.remove());
+      content = copy.outerHTML;
+    } else {
+      content = root.outerHTML;
+    }
+  } else {
+    content = markdown(root);
+  }
+  // Placeholders nest (lists inside lists), so expand until a pass changes
+  // nothing. Page text may hold NULs of its own, which never match.
+  for (let pass = 0; pass < 64; pass++) {
+    const next = content.replace(/\u0000(\d+)\u0000/g, (match, i) => preserved[Number(i)] ?? match);
+    if (next === content) break;
+    content = next;
+  }
+  let truncated = budget < 0;
+  if (content.length > spec.max_chars) {
+    content = content.slice(0, spec.max_chars);
+    // Do not leave half of a surrogate pair behind.
+    if (/[\ud800-\udbff]$/.test(content)) content = content.slice(0, -1);
+    truncated = true;
+  }
+
+  const links = [];
+  const seen = new Set();
+  root.querySelectorAll('a[href]').forEach((a) => {
+    if (a.href.startsWith('javascript:') || seen.has(a.href) || inChrome(a)) return;
+    seen.add(a.href);
+    links.push({ text: normalize(a.innerText || a.textContent || a.getAttribute('aria-label')), url: a.href });
+  });
+
+  const labelFor = (field) => {
+    if (field.labels && field.labels.length) return normalize(field.labels[0].textContent);
+    return normalize(field.getAttribute('aria-label') || field.getAttribute('placeholder'));
+  };
+  const forms = Array.from(root.querySelectorAll('form')).filter((form) => !inChrome(form)).map((form) => ({
+    id: form.id || undefined,
+    action: form.getAttribute('action') ? form.action : undefined,
+    method: (form.getAttribute('method') || 'get').toLowerCase(),
+    fields: Array.from(form.elements).filter((field) => field.type !== 'hidden' && field.tagName !== 'FIELDSET' && field.tagName !== 'OBJECT').map((field) => ({
+      name: field.name || undefined,
+      type: field.tagName === 'SELECT' || field.tagName === 'TEXTAREA' ? field.tagName.toLowerCase() : (field.type || 'text'),
+      label: labelFor(field) || undefined,
+      value: field.type === 'password' ? undefined : (field.value || undefined),
+      required: field.required || undefined,
+      options: field.tagName === 'SELECT' ? Array.from(field.options).map((option) => normalize(option.textContent)) : undefined,
+    })),
+  }));
+
+  return { url: location.href, title: document.title, content, truncated, links, forms };
+}`
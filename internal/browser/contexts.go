@@ -0,0 +1,256 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	DefaultContextName        = "default"
+	DefaultContextIdleTimeout = 30 * time.Minute
+)
+
+var ErrContextNotFound = errors.New("browser context not found")
+
+var contextNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+type ContextOptions struct {
+	Name string
+	// IdleTimeout overrides Config.ContextIdleTimeout for this context.
+	IdleTimeout time.Duration
+}
+
+type ContextInfo struct {
+	Name      string    `json:"name"`
+	Tabs      int       `json:"tabs"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used,omitempty"`
+	// ExpiresAt is when the context closes unless it is used again; the
+	// default context never expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Name returns the context name, DefaultContextName for the default one.
+func (service *Service) Name() string {
+	return service.name
+}
+
+// Context returns the named context, or ErrContextNotFound when there is
+// none; CreateContext makes new ones. Contexts have their own tabs,
+// cookies, storage and downloads, but share the browser process and route
+// rules. An empty name selects the default context. The browser context
+// itself is only created once a tab is needed.
+func (service *Service) Context(name string) (*Service, error) {
+	return service.lookupContext(ContextOptions{Name: name}, false)
+}
+
+// CreateContext creates a named context and fails if it already exists.
+func (service *Service) CreateContext(options ContextOptions) (*ContextInfo, error) {
+	created, err := service.lookupContext(options, true)
+	if err != nil {
+		return nil, err
+	}
+	info := created.info(0)
+	return &info, nil
+}
+
+func (service *Service) lookupContext(options ContextOptions, create bool) (*Service, error) {
+	service.sweepContexts()
+	if options.Name == "" || options.Name == DefaultContextName {
+		if create {
+			return nil, fmt.Errorf("%w: context %q already exists", ErrInvalidOption, DefaultContextName)
+		}
+		return service.main, nil
+	}
+	if !contextNamePattern.MatchString(options.Name) {
+		return nil, fmt.Errorf("%w: context name must be 1-64 letters, digits, '.', '_' or '-'", ErrInvalidOption)
+	}
+
+	service.contextsMu.Lock()
+	defer service.contextsMu.Unlock()
+	if existing, ok := service.contexts[options.Name]; ok {
+		if create {
+			return nil, fmt.Errorf("%w: context %q already exists", ErrInvalidOption, options.Name)
+		}
+		existing.lastUsed = time.Now()
+		return existing, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("%w: %q", ErrContextNotFound, options.Name)
+	}
+	idleTimeout := options.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = service.config.ContextIdleTimeout
+	}
+	now := time.Now()
+	named := &Service{
+		engine:      service.engine,
+		name:        options.Name,
+		idleTimeout: idleTimeout,
+		createdAt:   now,
+		lastUsed:    now,
+		downloads:   make(map[string]*DownloadInfo),
+	}
+	service.contexts[options.Name] = named
+	service.contextReaper.poke()
+	return named, nil
+}
+
+// Contexts lists the default context followed by named ones.
+func (service *Service) Contexts() []ContextInfo {
+	service.sweepContexts()
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	contexts := service.allContextsLocked()
+	infos := make([]ContextInfo, 0, len(contexts))
+	for _, browserContext := range contexts {
+		infos = append(infos, browserContext.info(len(browserContext.tabs)))
+	}
+	return infos
+}
+
+// CloseContext closes the tabs of a named context and disposes of its
+// browser context, discarding its cookies and storage.
+func (service *Service) CloseContext(name string) error {
+	if name == "" || name == DefaultContextName {
+		return fmt.Errorf("%w: the default context cannot be closed", ErrInvalidOption)
+	}
+	service.contextsMu.Lock()
+	named, ok := service.contexts[name]
+	delete(service.contexts, name)
+	service.contextsMu.Unlock()
+	if !ok {
+		return ErrContextNotFound
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	named.closeLocked()
+	return nil
+}
+
+func (service *Service) info(tabs int) ContextInfo {
+	service.contextsMu.Lock()
+	defer service.contextsMu.Unlock()
+	info := ContextInfo{Name: service.name, Tabs: tabs, CreatedAt: service.createdAt, LastUsed: service.lastUsed}
+	if service != service.main && service.idleTimeout > 0 {
+		expires := service.lastUsed.Add(service.idleTimeout)
+		info.ExpiresAt = &expires
+	}
+	return info
+}
+
+// touch records use of the context so it does not expire.
+func (service *Service) touch() {
+	service.contextsMu.Lock()
+	service.lastUsed = time.Now()
+	service.contextsMu.Unlock()
+}
+
+// sweepContexts closes named contexts that have been idle too long and
+// returns when the next one expires. It runs on lookups and from the
+// context reaper.
+func (service *Service) sweepContexts() (time.Time, bool) {
+	now := time.Now()
+	var expired []*Service
+	var next time.Time
+	service.contextsMu.Lock()
+	for name, named := range service.contexts {
+		if named.idleTimeout <= 0 {
+			continue
+		}
+		expires := named.lastUsed.Add(named.idleTimeout)
+		if now.After(expires) {
+			delete(service.contexts, name)
+			expired = append(expired, named)
+		} else if next.IsZero() || expires.Before(next) {
+			next = expires
+		}
+	}
+	service.contextsMu.Unlock()
+	if len(expired) > 0 {
+		service.mu.Lock()
+		for _, named := range expired {
+			named.closeLocked()
+		}
+		service.mu.Unlock()
+	}
+	return next, !next.IsZero()
+}
+
+// allContextsLocked returns the default context followed by named ones in
+// name order.
+func (service *Service) allContextsLocked() []*Service {
+	service.contextsMu.Lock()
+	defer service.contextsMu.Unlock()
+	contexts := []*Service{service.main}
+	names := make([]string, 0, len(service.contexts))
+	for name := range service.contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		contexts = append(contexts, service.contexts[name])
+	}
+	return contexts
+}
+
+// openContextLocked creates the CDP browser context if needed and opens
+// its first tab. The default context must be running.
+func (service *Service) openContextLocked() error {
+	if service.browserContextID == "" {
+		executor, err := service.browserExecutorLocked()
+		if err != nil {
+			return err
+		}
+		id, err := target.CreateBrowserContext().Do(executor)
+		if err != nil {
+			return err
+		}
+		service.browserContextID = id
+	}
+	handle, err := service.createTabLocked()
+	if err != nil {
+		return err
+	}
+	service.tabs = nil
+	service.activateTabLocked(service.addTabLocked(handle))
+	return nil
+}
+
+func (service *Service) closeLocked() {
+	service.closed = true
+	service.clearLocked()
+	if service.browserContextID == "" {
+		return
+	}
+	if executor, err := service.browserExecutorLocked(); err == nil {
+		ctx, cancel := context.WithTimeout(executor, service.config.NavigateTimeout)
+		_ = target.DisposeBrowserContext(service.browserContextID).Do(ctx)
+		cancel()
+	}
+	service.browserContextID = ""
+}
+
+// browserExecutorLocked returns a context for browser-level CDP commands,
+// sent over the default context's connection.
+func (service *Service) browserExecutorLocked() (context.Context, error) {
+	main := service.main
+	if !main.isTabHealthyLocked() {
+		return nil, errors.New("browser not running")
+	}
+	c := chromedp.FromContext(main.tabCtx)
+	if c == nil || c.Browser == nil {
+		return nil, errors.New("browser not running")
+	}
+	return cdp.WithExecutor(main.tabCtx, c.Browser), nil
+}
@@ -0,0 +1,249 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// DialogPolicyAccept accepts every dialog, answering prompts with their
+	// default text.
+	DialogPolicyAccept  = "accept"
+	DialogPolicyDismiss = "dismiss"
+	// DialogPolicyWait leaves dialogs open until HandleDialog is called.
+	// Tab actions fail with ErrDialogOpen meanwhile.
+	DialogPolicyWait = "wait"
+
+	dialogHandleTimeout = 5 * time.Second
+)
+
+var (
+	ErrDialogOpen = errors.New("javascript dialog open")
+	ErrNoDialog   = errors.New("no javascript dialog open")
+)
+
+// Dialog is an alert, confirm, prompt or beforeunload dialog of a tab.
+// Accepted and UserInput are set once it closes.
+type Dialog struct {
+	TabID         string    `json:"tab_id"`
+	Type          string    `json:"type"`
+	Message       string    `json:"message"`
+	DefaultPrompt string    `json:"default_prompt,omitempty"`
+	URL           string    `json:"url"`
+	OpenedAt      time.Time `json:"opened_at"`
+	Accepted      *bool     `json:"accepted,omitempty"`
+	UserInput     string    `json:"user_input,omitempty"`
+}
+
+func validateDialogPolicy(policy string) error {
+	switch policy {
+	case DialogPolicyAccept, DialogPolicyDismiss, DialogPolicyWait:
+		return nil
+	}
+	return fmt.Errorf("%w: dialog policy must be accept, dismiss or wait", ErrInvalidOption)
+}
+
+// DialogPolicy returns how this context answers dialogs.
+func (service *Service) DialogPolicy() string {
+	service.dialogMu.Lock()
+	defer service.dialogMu.Unlock()
+	if service.dialogPolicy != "" {
+		return service.dialogPolicy
+	}
+	if validateDialogPolicy(service.config.DialogPolicy) == nil {
+		return service.config.DialogPolicy
+	}
+	return DialogPolicyAccept
+}
+
+// SetDialogPolicy changes how this context answers dialogs opened from now on.
+func (service *Service) SetDialogPolicy(policy string) error {
+	if err := validateDialogPolicy(policy); err != nil {
+		return err
+	}
+	service.dialogMu.Lock()
+	service.dialogPolicy = policy
+	service.dialogMu.Unlock()
+	return nil
+}
+
+// Dialogs returns the dialogs open in this context's tabs.
+func (service *Service) Dialogs() []Dialog {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	dialogs := make([]Dialog, 0)
+	for _, handle := range service.tabs {
+		if dialog := handle.state.openDialog(); dialog != nil {
+			dialogs = append(dialogs, *dialog)
+		}
+	}
+	return dialogs
+}
+
+// HandleDialog accepts or dismisses the dialog open in the tab ref selects,
+// the active tab when ref is empty. promptText answers prompt dialogs.
+func (service *Service) HandleDialog(ref TabRef, accept bool, promptText string) (*Dialog, error) {
+	service.mu.Lock()
+	var state *tabState
+	if ref == "" {
+		state = service.activeTabStateLocked()
+	} else {
+		index, err := service.tabIndexLocked(ref)
+		if err != nil {
+			service.mu.Unlock()
+			return nil, err
+		}
+		state = service.tabs[index].state
+	}
+	service.mu.Unlock()
+
+	if state == nil {
+		return nil, ErrNoDialog
+	}
+	dialog, ctx := state.openDialogWithContext()
+	if dialog == nil {
+		return nil, ErrNoDialog
+	}
+	ctx, cancel := context.WithTimeout(ctx, dialogHandleTimeout)
+	defer cancel()
+	params := page.HandleJavaScriptDialog(accept)
+	if dialog.Type == page.DialogTypePrompt.String() {
+		params = params.WithPromptText(promptText)
+	}
+	if err := params.Do(ctx); err != nil {
+		return nil, err
+	}
+	dialog.Accepted = &accept
+	if accept && dialog.Type == page.DialogTypePrompt.String() {
+		dialog.UserInput = promptText
+	}
+	return dialog, nil
+}
+
+// handleDialogOpening runs on the listener goroutine, so answering the
+// dialog happens on its own goroutine.
+func (service *Service) handleDialogOpening(ctx context.Context, targetID string, state *tabState, e *page.EventJavascriptDialogOpening) {
+	dialog := &Dialog{
+		TabID:         targetID,
+		Type:          e.Type.String(),
+		Message:       e.Message,
+		DefaultPrompt: e.DefaultPrompt,
+		URL:           e.URL,
+		OpenedAt:      time.Now(),
+	}
+	executor := ctx
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+		executor = cdp.WithExecutor(ctx, c.Target)
+	}
+	policy := service.DialogPolicy()
+	state.setDialog(dialog, executor, policy == DialogPolicyWait)
+	service.publish(Event{Type: EventDialogOpened, TabID: targetID, URL: e.URL, Dialog: dialog})
+
+	if policy == DialogPolicyWait {
+		state.interruptAction()
+		return
+	}
+	accept := policy == DialogPolicyAccept
+	go func() {
+		_ = page.HandleJavaScriptDialog(accept).WithPromptText(e.DefaultPrompt).Do(executor)
+	}()
+}
+
+func (service *Service) handleDialogClosed(targetID string, state *tabState, e *page.EventJavascriptDialogClosed) {
+	dialog, _ := state.openDialogWithContext()
+	state.setDialog(nil, nil, false)
+	if dialog == nil {
+		return
+	}
+	accepted := e.Result
+	dialog.Accepted = &accepted
+	dialog.UserInput = e.UserInput
+	service.publish(Event{Type: EventDialogClosed, TabID: targetID, URL: dialog.URL, Dialog: dialog})
+}
+
+// guardDialog runs action so that a dialog left open by the wait policy
+// cancels it, and reports the dialog instead of a context error that
+// would make runTabAction restart the browser.
+func (state *tabState) guardDialog(action func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if state == nil {
+			return action(ctx)
+		}
+		if dialog := state.waitingDialog(); dialog != nil {
+			return dialogOpenError(dialog)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		state.setInterrupt(cancel)
+		defer state.setInterrupt(nil)
+		err := action(ctx)
+		if isContextErr(err) {
+			if dialog := state.waitingDialog(); dialog != nil {
+				return dialogOpenError(dialog)
+			}
+		}
+		return err
+	}
+}
+
+func dialogOpenError(dialog *Dialog) error {
+	return fmt.Errorf("%w: %s %q", ErrDialogOpen, dialog.Type, dialog.Message)
+}
+
+func (state *tabState) setDialog(dialog *Dialog, ctx context.Context, waiting bool) {
+	state.dialogMu.Lock()
+	state.dialog = dialog
+	state.dialogCtx = ctx
+	state.dialogWaiting = waiting
+	state.dialogMu.Unlock()
+}
+
+// waitingDialog returns the open dialog if it waits for HandleDialog.
+// Dialogs the policy answers close on their own and do not block actions.
+func (state *tabState) waitingDialog() *Dialog {
+	state.dialogMu.Lock()
+	waiting := state.dialogWaiting
+	state.dialogMu.Unlock()
+	if !waiting {
+		return nil
+	}
+	return state.openDialog()
+}
+
+func (state *tabState) openDialog() *Dialog {
+	dialog, _ := state.openDialogWithContext()
+	return dialog
+}
+
+// openDialogWithContext returns a copy of the open dialog and a context
+// that sends commands to its tab.
+func (state *tabState) openDialogWithContext() (*Dialog, context.Context) {
+	state.dialogMu.Lock()
+	defer state.dialogMu.Unlock()
+	if state.dialog == nil {
+		return nil, nil
+	}
+	dialog := *state.dialog
+	return &dialog, state.dialogCtx
+}
+
+func (state *tabState) setInterrupt(cancel context.CancelFunc) {
+	state.dialogMu.Lock()
+	state.interrupt = cancel
+	state.dialogMu.Unlock()
+}
+
+func (state *tabState) interruptAction() {
+	state.dialogMu.Lock()
+	interrupt := state.interrupt
+	state.dialogMu.Unlock()
+	if interrupt != nil {
+		interrupt()
+	}
+}
@@ -0,0 +1,497 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	defaultElementTimeout = 10 * time.Second
+	elementPollInterval   = 100 * time.Millisecond
+)
+
+var (
+	ErrInvalidLocator = errors.New("invalid locator")
+	ErrWaitTimeout    = errors.New("wait timed out")
+)
+
+// Locator identifies one element on the active tab. Ref points at a node
+// from the latest Snapshot and Element at a number from the latest marked
+// screenshot; both take precedence over the other fields.
+// Selector and XPath pick candidates; Text, Role and Name narrow them down.
+// When several elements match, the first visible one wins unless Nth is set.
+type Locator struct {
+	Ref       string `json:"ref,omitempty"`
+	Element   int    `json:"element,omitempty"`
+	Selector  string `json:"selector,omitempty"`
+	XPath     string `json:"xpath,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Exact     bool   `json:"exact,omitempty"`
+	Nth       *int   `json:"nth,omitempty"`
+	TimeoutMS int    `json:"timeout_ms,omitempty"`
+}
+
+func (locator Locator) IsZero() bool {
+	return locator.Ref == "" && locator.Element == 0 && locator.Selector == "" && locator.XPath == "" && locator.Text == "" && locator.Role == ""
+}
+
+func (locator Locator) validate() error {
+	if locator.IsZero() {
+		return fmt.Errorf("%w: ref, element, selector, xpath, text or role is required", ErrInvalidLocator)
+	}
+	if locator.Element < 0 {
+		return fmt.Errorf("%w: element must be positive", ErrInvalidLocator)
+	}
+	if locator.Ref == "" && locator.Name != "" && locator.Role == "" {
+		return fmt.Errorf("%w: name requires role", ErrInvalidLocator)
+	}
+	return nil
+}
+
+func (locator Locator) timeout() time.Duration {
+	if locator.TimeoutMS > 0 {
+		return time.Duration(locator.TimeoutMS) * time.Millisecond
+	}
+	return defaultElementTimeout
+}
+
+type ClickOptions struct {
+	Button string
+	Count  int
+}
+
+func (service *Service) ClickElement(locator Locator, options ClickOptions) error {
+	if options.Count <= 0 {
+		options.Count = 1
+	}
+	if options.Button == "" {
+		options.Button = "left"
+	}
+	var x, y float64
+	err := service.withElement(locator, true, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		var err error
+		x, y, err = elementCenter(ctx, element)
+		if err != nil {
+			return err
+		}
+		return chromedp.MouseClickXY(x, y, chromedp.Button(options.Button), chromedp.ClickCount(options.Count)).Do(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	service.setMousePos(x, y)
+	return nil
+}
+
+func (service *Service) HoverElement(locator Locator) error {
+	var x, y float64
+	err := service.withElement(locator, false, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		var err error
+		x, y, err = elementCenter(ctx, element)
+		if err != nil {
+			return err
+		}
+		return input.DispatchMouseEvent(input.MouseMoved, x, y).Do(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	service.setMousePos(x, y)
+	return nil
+}
+
+// ElementCenter scrolls the element into view and returns its center in
+// viewport coordinates.
+func (service *Service) ElementCenter(locator Locator) (float64, float64, error) {
+	var x, y float64
+	err := service.withElement(locator, false, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		var err error
+		x, y, err = elementCenter(ctx, element)
+		return err
+	})
+	return x, y, err
+}
+
+// TypeElement focuses the element and sends text as key events, so pages
+// see the same keydown/input sequence a user would produce.
+func (service *Service) TypeElement(locator Locator, text string, clear bool) error {
+	return service.withElement(locator, true, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		if _, err := callElement(ctx, element, focusElementJS, clear); err != nil {
+			return err
+		}
+		return chromedp.KeyEvent(text).Do(ctx)
+	})
+}
+
+func (service *Service) FocusElement(locator Locator) error {
+	return service.withElement(locator, false, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		_, err := callElement(ctx, element, focusElementJS, false)
+		return err
+	})
+}
+
+// CheckElement clicks a checkbox, radio or switch only when its state differs
+// from checked.
+func (service *Service) CheckElement(locator Locator, checked bool) error {
+	return service.withElement(locator, true, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		var current bool
+		if err := callElementInto(ctx, element, checkedStateJS, &current); err != nil {
+			return err
+		}
+		if current == checked {
+			return nil
+		}
+		x, y, err := elementCenter(ctx, element)
+		if err != nil {
+			return err
+		}
+		if err := chromedp.MouseClickXY(x, y).Do(ctx); err != nil {
+			return err
+		}
+		if err := callElementInto(ctx, element, checkedStateJS, &current); err != nil {
+			return err
+		}
+		if current != checked {
+			return errors.New("element did not change checked state")
+		}
+		return nil
+	})
+}
+
+func (service *Service) ScrollIntoView(locator Locator) error {
+	return service.withElement(locator, false, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		_, err := callElement(ctx, element, scrollIntoViewJS)
+		return err
+	})
+}
+
+// withElement waits for the locator to resolve to an actionable element and
+// runs action against it. The wait has its own deadline so a missing element
+// surfaces as ErrWaitTimeout rather than a context error, which would make
+// runTabAction restart the browser.
+func (service *Service) withElement(locator Locator, requireEnabled bool, action func(ctx context.Context, element runtime.RemoteObjectID) error) error {
+	if err := locator.validate(); err != nil {
+		return err
+	}
+	wait := locator.timeout()
+	return service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			find := locateElement(locator)
+			if locator.Ref != "" {
+				backendID, err := service.lookupRef(ctx, locator.Ref)
+				if err != nil {
+					return err
+				}
+				find = resolveRef(backendID)
+			} else if locator.Element > 0 {
+				backendID, err := service.lookupMark(ctx, locator.Element)
+				if err != nil {
+					return err
+				}
+				find = resolveRef(backendID)
+			}
+			element, err := waitForElement(ctx, find, wait, requireEnabled)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = runtime.ReleaseObject(element).Do(ctx)
+			}()
+			return action(ctx, element)
+		}))
+	})
+}
+
+// elementFinder returns the current candidate element, or an empty ID when
+// nothing matches yet.
+type elementFinder func(ctx context.Context) (runtime.RemoteObjectID, error)
+
+func locateElement(locator Locator) elementFinder {
+	return func(ctx context.Context) (runtime.RemoteObjectID, error) {
+		spec, err := json.Marshal(locator)
+		if err != nil {
+			return "", err
+		}
+		result, exception, err := runtime.Evaluate("(" + locateElementJS + ")(" + string(spec) + ")").Do(ctx)
+		if err != nil {
+			return "", err
+		}
+		if exception != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidLocator, exceptionText(exception))
+		}
+		return result.ObjectID, nil
+	}
+}
+
+func waitForElement(ctx context.Context, find elementFinder, wait time.Duration, requireEnabled bool) (runtime.RemoteObjectID, error) {
+	deadline := time.Now().Add(wait)
+	reason := "element not found"
+
+	for {
+		element, err := find(ctx)
+		if err != nil {
+			return "", err
+		}
+		if element != "" {
+			var state elementState
+			if err := callElementInto(ctx, element, elementStateJS, &state); err != nil {
+				return "", err
+			}
+			switch {
+			case !state.Visible:
+				reason = "element not visible"
+			case requireEnabled && !state.Enabled:
+				reason = "element not enabled"
+			default:
+				return element, nil
+			}
+			_ = runtime.ReleaseObject(element).Do(ctx)
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w: %s", ErrWaitTimeout, reason)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(elementPollInterval):
+		}
+	}
+}
+
+type elementState struct {
+	Visible bool `json:"visible"`
+	Enabled bool `json:"enabled"`
+}
+
+func elementCenter(ctx context.Context, element runtime.RemoteObjectID) (float64, float64, error) {
+	var point struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	}
+	if err := callElementInto(ctx, element, elementCenterJS, &point); err != nil {
+		return 0, 0, err
+	}
+	return point.X, point.Y, nil
+}
+
+func callElement(ctx context.Context, element runtime.RemoteObjectID, function string, args ...any) (*runtime.RemoteObject, error) {
+	arguments := make([]*runtime.CallArgument, 0, len(args))
+	for _, arg := range args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, &runtime.CallArgument{Value: raw})
+	}
+	result, exception, err := runtime.CallFunctionOn(function).
+		WithObjectID(element).
+		WithArguments(arguments).
+		WithReturnByValue(true).
+		WithAwaitPromise(true).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, errors.New(exceptionText(exception))
+	}
+	return result, nil
+}
+
+func callElementInto(ctx context.Context, element runtime.RemoteObjectID, function string, target any, args ...any) error {
+	result, err := callElement(ctx, element, function, args...)
+	if err != nil {
+		return err
+	}
+	if len(result.Value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Value, target)
+}
+
+// evaluateInto calls function in the page with JSON-encoded args and
+// decodes its result into target.
+func evaluateInto(ctx context.Context, function string, target any, args ...any) error {
+	encoded := make([]string, 0, len(args))
+	for _, arg := range args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, string(raw))
+	}
+	result, exception, err := runtime.Evaluate("(" + function + ")(" + strings.Join(encoded, ", ") + ")").
+		WithReturnByValue(true).
+		WithAwaitPromise(true).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	if exception != nil {
+		return errors.New(exceptionText(exception))
+	}
+	if len(result.Value) == 0 || target == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Value, target)
+}
+
+func exceptionText(exception *runtime.ExceptionDetails) string {
+	if exception.Exception != nil && exception.Exception.Description != "" {
+		return strings.SplitN(exception.Exception.Description, "\n", 2)[0]
+	}
+	return exception.Text
+}
+
+// domHelpersJS defines role and accessible-name heuristics shared by the
+// locator and the page annotation scripts.
+const domHelpersJS = `
+const normalizeText = (value) => (value || '').replace(/\s+/g, ' ').trim();
+const roleOf = (el) => {
+  const explicit = el.getAttribute('role');
+  if (explicit) return explicit.trim().split(/\s+/)[0].toLowerCase();
+  const tag = el.tagName.toLowerCase();
+  const type = (el.getAttribute('type') || '').toLowerCase();
+  switch (tag) {
+    case 'a': case 'area': return el.hasAttribute('href') ? 'link' : '';
+    case 'button': case 'summary': return 'button';
+    case 'input':
+      if (['button', 'submit', 'reset', 'image'].includes(type)) return 'button';
+      if (type === 'checkbox') return 'checkbox';
+      if (type === 'radio') return 'radio';
+      if (type === 'range') return 'slider';
+      if (type === 'search') return 'searchbox';
+      if (type === 'hidden') return '';
+      return 'textbox';
+    case 'textarea': return 'textbox';
+    case 'select': return el.multiple || el.size > 1 ? 'listbox' : 'combobox';
+    case 'option': return 'option';
+    case 'img': return 'img';
+    case 'h1': case 'h2': case 'h3': case 'h4': case 'h5': case 'h6': return 'heading';
+    case 'ul': case 'ol': return 'list';
+    case 'li': return 'listitem';
+    case 'nav': return 'navigation';
+    case 'main': return 'main';
+    case 'form': return 'form';
+    case 'table': return 'table';
+    case 'tr': return 'row';
+    case 'td': return 'cell';
+    case 'th': return 'columnheader';
+    case 'dialog': return 'dialog';
+  }
+  return '';
+};
+const nameOf = (el) => {
+  const label = el.getAttribute('aria-label');
+  if (label && label.trim()) return normalizeText(label);
+  const labelledBy = el.getAttribute('aria-labelledby');
+  if (labelledBy) {
+    const text = labelledBy.split(/\s+/).map((id) => {
+      const node = document.getElementById(id);
+      return node ? node.textContent : '';
+    }).join(' ');
+    if (normalizeText(text)) return normalizeText(text);
+  }
+  if (el.labels && el.labels.length) {
+    return normalizeText(Array.from(el.labels).map((node) => node.textContent).join(' '));
+  }
+  const alt = el.getAttribute('alt');
+  if (alt) return normalizeText(alt);
+  const tag = el.tagName.toLowerCase();
+  if (tag === 'input' && ['button', 'submit', 'reset'].includes((el.type || '').toLowerCase())) {
+    return normalizeText(el.value);
+  }
+  if (!['input', 'textarea', 'select'].includes(tag)) {
+    const text = normalizeText(el.innerText || el.textContent);
+    if (text) return text;
+  }
+  return normalizeText(el.getAttribute('title') || el.getAttribute('placeholder'));
+};
+const isVisible = (el) => {
+  const style = getComputedStyle(el);
+  const rect = el.getBoundingClientRect();
+  return style.visibility !== 'hidden' && style.display !== 'none' && rect.width > 0 && rect.height > 0;
+};
+`
+
+const locateElementJS = `function(spec) {
+` + domHelpersJS + `
+  const matches = (value, expected) => {
+    const actual = normalizeText(value);
+    const wanted = normalizeText(expected);
+    return spec.exact ? actual === wanted : actual.toLowerCase().includes(wanted.toLowerCase());
+  };
+  let candidates;
+  if (spec.selector) {
+    candidates = Array.from(document.querySelectorAll(spec.selector));
+  } else if (spec.xpath) {
+    const snapshot = document.evaluate(spec.xpath, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+    candidates = [];
+    for (let i = 0; i < snapshot.snapshotLength; i++) {
+      const node = snapshot.snapshotItem(i);
+      if (node.nodeType === Node.ELEMENT_NODE) candidates.push(node);
+    }
+  } else {
+    candidates = Array.from(document.body ? document.body.querySelectorAll('*') : []);
+  }
+  if (spec.role) {
+    candidates = candidates.filter((el) => roleOf(el) === spec.role.toLowerCase());
+    if (spec.name) candidates = candidates.filter((el) => matches(nameOf(el), spec.name));
+  }
+  if (spec.text) {
+    const textMatches = candidates.filter((el) => matches(el.innerText || el.textContent, spec.text));
+    const set = new Set(textMatches);
+    // Keep the innermost match so a container is not chosen over its label.
+    candidates = textMatches.filter((el) => !Array.from(el.querySelectorAll('*')).some((child) => set.has(child)));
+  }
+  if (typeof spec.nth === 'number') return candidates[spec.nth] || null;
+  return candidates.find(isVisible) || candidates[0] || null;
+}`
+
+const elementStateJS = `function() {
+` + domHelpersJS + `
+  if (!this.isConnected) return { visible: false, enabled: false };
+  const disabled = this.disabled === true || this.getAttribute('aria-disabled') === 'true' || !!this.closest('fieldset[disabled]');
+  return { visible: isVisible(this), enabled: !disabled };
+}`
+
+const elementCenterJS = `function() {
+  this.scrollIntoView({ block: 'center', inline: 'center', behavior: 'instant' });
+  const rect = this.getBoundingClientRect();
+  return { x: rect.left + rect.width / 2, y: rect.top + rect.height / 2 };
+}`
+
+const focusElementJS = `function(clear) {
+  this.scrollIntoView({ block: 'center', inline: 'center', behavior: 'instant' });
+  this.focus();
+  if (clear) {
+    if ('value' in this) {
+      this.value = '';
+      this.dispatchEvent(new Event('input', { bubbles: true }));
+    } else if (this.isContentEditable) {
+      this.textContent = '';
+    }
+  }
+}`
+
+const checkedStateJS = `function() {
+  if ('checked' in this && (this.type === 'checkbox' || this.type === 'radio')) return this.checked;
+  const aria = this.getAttribute('aria-checked') || this.getAttribute('aria-pressed');
+  if (aria !== null) return aria === 'true';
+  throw new Error('element is not checkable');
+}`
+
+const scrollIntoViewJS = `function() {
+  this.scrollIntoView({ block: 'center', inline: 'center', behavior: 'instant' });
+}`
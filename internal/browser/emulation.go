@@ -0,0 +1,434 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	ColorSchemeLight        = "light"
+	ColorSchemeDark         = "dark"
+	ColorSchemeNoPreference = "no-preference"
+
+	ReducedMotionReduce       = "reduce"
+	ReducedMotionNoPreference = "no-preference"
+
+	// NetworkNoThrottling turns a throttled profile off again.
+	NetworkNoThrottling = "no-throttling"
+)
+
+// Device is a preset for the viewport, scale, touch and user agent of a
+// device.
+type Device struct {
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"device_scale_factor"`
+	Mobile            bool    `json:"mobile"`
+	Touch             bool    `json:"touch"`
+	UserAgent         string  `json:"user_agent"`
+}
+
+const (
+	iosUserAgent     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	ipadUserAgent    = "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 14; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+	galaxyUserAgent  = "Mozilla/5.0 (Linux; Android 13; SM-S911B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+	desktopUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+var devices = map[string]Device{
+	"iphone-se":     {Width: 375, Height: 667, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: iosUserAgent},
+	"iphone-14":     {Width: 390, Height: 844, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: iosUserAgent},
+	"iphone-14-pro": {Width: 393, Height: 852, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: iosUserAgent},
+	"ipad-mini":     {Width: 768, Height: 1024, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: ipadUserAgent},
+	"ipad-pro-11":   {Width: 834, Height: 1194, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: ipadUserAgent},
+	"pixel-7":       {Width: 412, Height: 915, DeviceScaleFactor: 2.625, Mobile: true, Touch: true, UserAgent: androidUserAgent},
+	"galaxy-s23":    {Width: 360, Height: 780, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: galaxyUserAgent},
+	"desktop-hd":    {Width: 1366, Height: 768, DeviceScaleFactor: 1, UserAgent: desktopUserAgent},
+	"desktop-fhd":   {Width: 1920, Height: 1080, DeviceScaleFactor: 1, UserAgent: desktopUserAgent},
+}
+
+// networkProfile mirrors the DevTools throttling presets. Throughput is in
+// bytes per second and latency in milliseconds.
+type networkProfile struct {
+	latency  float64
+	download float64
+	upload   float64
+}
+
+var networkProfiles = map[string]networkProfile{
+	"slow-3g": {latency: 2000, download: 50000, upload: 50000},
+	"fast-3g": {latency: 563, download: 180000, upload: 84375},
+	"4g":      {latency: 170, download: 1012500, upload: 1012500},
+}
+
+// Devices returns the device presets by name.
+func Devices() map[string]Device {
+	return maps.Clone(devices)
+}
+
+type Viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"`
+}
+
+// EmulationOptions are emulated device and environment settings. Empty
+// fields leave the current setting alone; Device fills in the viewport,
+// scale, mobile, touch and user agent fields that are not set explicitly.
+type EmulationOptions struct {
+	Device            string       `json:"device,omitempty"`
+	Viewport          *Viewport    `json:"viewport,omitempty"`
+	DeviceScaleFactor float64      `json:"device_scale_factor,omitempty"`
+	Mobile            *bool        `json:"mobile,omitempty"`
+	Touch             *bool        `json:"touch,omitempty"`
+	UserAgent         string       `json:"user_agent,omitempty"`
+	Locale            string       `json:"locale,omitempty"`
+	Timezone          string       `json:"timezone,omitempty"`
+	Geolocation       *Geolocation `json:"geolocation,omitempty"`
+	ColorScheme       string       `json:"color_scheme,omitempty"`
+	ReducedMotion     string       `json:"reduced_motion,omitempty"`
+	Offline           *bool        `json:"offline,omitempty"`
+	Network           string       `json:"network,omitempty"`
+}
+
+func (options EmulationOptions) validate() error {
+	if options.Device != "" {
+		if _, ok := devices[options.Device]; !ok {
+			return fmt.Errorf("%w: unknown device %q, expected one of %s", ErrInvalidOption, options.Device, strings.Join(slices.Sorted(maps.Keys(devices)), ", "))
+		}
+	}
+	if viewport := options.Viewport; viewport != nil && (viewport.Width <= 0 || viewport.Height <= 0) {
+		return fmt.Errorf("%w: viewport width and height must be positive", ErrInvalidOption)
+	}
+	if options.DeviceScaleFactor < 0 {
+		return fmt.Errorf("%w: device_scale_factor must be positive", ErrInvalidOption)
+	}
+	if geo := options.Geolocation; geo != nil {
+		if geo.Latitude < -90 || geo.Latitude > 90 || geo.Longitude < -180 || geo.Longitude > 180 || geo.Accuracy < 0 {
+			return fmt.Errorf("%w: geolocation needs latitude in [-90, 90] and longitude in [-180, 180]", ErrInvalidOption)
+		}
+	}
+	switch options.ColorScheme {
+	case "", ColorSchemeLight, ColorSchemeDark, ColorSchemeNoPreference:
+	default:
+		return fmt.Errorf("%w: color_scheme must be light, dark or no-preference", ErrInvalidOption)
+	}
+	switch options.ReducedMotion {
+	case "", ReducedMotionReduce, ReducedMotionNoPreference:
+	default:
+		return fmt.Errorf("%w: reduced_motion must be reduce or no-preference", ErrInvalidOption)
+	}
+	if options.Network != "" && options.Network != NetworkNoThrottling {
+		if _, ok := networkProfiles[options.Network]; !ok {
+			return fmt.Errorf("%w: network must be %s or %s", ErrInvalidOption, strings.Join(slices.Sorted(maps.Keys(networkProfiles)), ", "), NetworkNoThrottling)
+		}
+	}
+	return nil
+}
+
+// merge returns options with the fields set in update replaced.
+func (options EmulationOptions) merge(update EmulationOptions) EmulationOptions {
+	if update.Device != "" {
+		options.Device = update.Device
+	}
+	if update.Viewport != nil {
+		options.Viewport = update.Viewport
+	}
+	if update.DeviceScaleFactor > 0 {
+		options.DeviceScaleFactor = update.DeviceScaleFactor
+	}
+	if update.Mobile != nil {
+		options.Mobile = update.Mobile
+	}
+	if update.Touch != nil {
+		options.Touch = update.Touch
+	}
+	if update.UserAgent != "" {
+		options.UserAgent = update.UserAgent
+	}
+	if update.Locale != "" {
+		options.Locale = update.Locale
+	}
+	if update.Timezone != "" {
+		options.Timezone = update.Timezone
+	}
+	if update.Geolocation != nil {
+		options.Geolocation = update.Geolocation
+	}
+	if update.ColorScheme != "" {
+		options.ColorScheme = update.ColorScheme
+	}
+	if update.ReducedMotion != "" {
+		options.ReducedMotion = update.ReducedMotion
+	}
+	if update.Offline != nil {
+		options.Offline = update.Offline
+	}
+	if update.Network != "" {
+		options.Network = update.Network
+	}
+	return options
+}
+
+// Emulate merges options into the emulation of the tab ref selects, or of
+// the whole context when ref is empty. Context settings also apply to tabs
+// opened later; tab settings take precedence over them. It returns the
+// settings now in effect on the tab, the active one for the context.
+func (service *Service) Emulate(ref TabRef, options EmulationOptions) (*EmulationOptions, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return nil, err
+	}
+
+	handles, err := service.emulationTargetsLocked(ref)
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		service.emulation = service.emulation.merge(options)
+	} else {
+		handles[0].state.emulation = handles[0].state.emulation.merge(options)
+	}
+	for _, handle := range handles {
+		if err := service.applyEmulationLocked(handle, false); err != nil {
+			return nil, err
+		}
+	}
+	return service.emulationLocked(ref)
+}
+
+// ResetEmulation drops the emulation of the tab ref selects, or of the
+// context and all its tabs when ref is empty, and restores the defaults
+// underneath.
+func (service *Service) ResetEmulation(ref TabRef) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return err
+	}
+
+	handles, err := service.emulationTargetsLocked(ref)
+	if err != nil {
+		return err
+	}
+	if ref == "" {
+		service.emulation = EmulationOptions{}
+	}
+	for _, handle := range handles {
+		handle.state.emulation = EmulationOptions{}
+		if err := service.applyEmulationLocked(handle, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Emulation returns the settings in effect on the tab ref selects, the
+// active tab when ref is empty.
+func (service *Service) Emulation(ref TabRef) (*EmulationOptions, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	return service.emulationLocked(ref)
+}
+
+func (service *Service) emulationLocked(ref TabRef) (*EmulationOptions, error) {
+	effective := service.emulation
+	if ref != "" {
+		index, err := service.tabIndexLocked(ref)
+		if err != nil {
+			return nil, err
+		}
+		effective = effective.merge(service.tabs[index].state.emulation)
+	} else if state := service.activeTabStateLocked(); state != nil {
+		effective = effective.merge(state.emulation)
+	}
+	return &effective, nil
+}
+
+func (service *Service) emulationTargetsLocked(ref TabRef) ([]tabHandle, error) {
+	if ref == "" {
+		return service.tabs, nil
+	}
+	index, err := service.tabIndexLocked(ref)
+	if err != nil {
+		return nil, err
+	}
+	return []tabHandle{service.tabs[index]}, nil
+}
+
+// applyEmulationLocked sends the effective settings of handle to its tab.
+// reset first clears every override, which otherwise stays in place for
+// fields that are not set.
+func (service *Service) applyEmulationLocked(handle tabHandle, reset bool) error {
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return fmt.Errorf("%w: tab unavailable", ErrTabNotFound)
+	}
+	options := service.emulation.merge(handle.state.emulation)
+	return runWithTimeout(handle.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			if reset {
+				if err := service.clearEmulation(ctx); err != nil {
+					return err
+				}
+			}
+			return service.applyEmulation(ctx, options)
+		}))
+	})
+}
+
+func (service *Service) clearEmulation(ctx context.Context) error {
+	permissions := browser.ResetPermissions()
+	if service.browserContextID != "" {
+		permissions = permissions.WithBrowserContextID(service.browserContextID)
+	}
+	actions := []chromedp.Action{
+		emulation.ClearDeviceMetricsOverride(),
+		emulation.SetTouchEmulationEnabled(false),
+		emulation.SetUserAgentOverride(""),
+		emulation.SetLocaleOverride(),
+		emulation.SetTimezoneOverride(""),
+		emulation.ClearGeolocationOverride(),
+		permissions,
+		emulation.SetEmulatedMedia().WithFeatures([]*emulation.MediaFeature{}),
+		network.EmulateNetworkConditions(false, 0, -1, -1),
+	}
+	for _, action := range actions {
+		if err := action.Do(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *Service) applyEmulation(ctx context.Context, options EmulationOptions) error {
+	var device Device
+	if options.Device != "" {
+		device = devices[options.Device]
+	}
+	width, height := device.Width, device.Height
+	if options.Viewport != nil {
+		width, height = options.Viewport.Width, options.Viewport.Height
+	}
+	scale := device.DeviceScaleFactor
+	if options.DeviceScaleFactor > 0 {
+		scale = options.DeviceScaleFactor
+	}
+	mobile, touch := device.Mobile, device.Touch
+	if options.Mobile != nil {
+		mobile = *options.Mobile
+	}
+	if options.Touch != nil {
+		touch = *options.Touch
+	}
+	userAgent := device.UserAgent
+	if options.UserAgent != "" {
+		userAgent = options.UserAgent
+	}
+
+	if width > 0 && height > 0 {
+		if scale == 0 {
+			scale = 1
+		}
+		err := emulation.SetDeviceMetricsOverride(int64(width), int64(height), scale, mobile).
+			WithScreenWidth(int64(width)).
+			WithScreenHeight(int64(height)).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if options.Device != "" || options.Touch != nil {
+		if err := emulation.SetTouchEmulationEnabled(touch).WithMaxTouchPoints(5).Do(ctx); err != nil {
+			return err
+		}
+	}
+	if userAgent != "" || options.Locale != "" {
+		if userAgent == "" {
+			// Accept-Language can only be set together with a user agent.
+			_, _, _, current, _, err := browser.GetVersion().Do(ctx)
+			if err != nil {
+				return err
+			}
+			userAgent = current
+		}
+		if err := emulation.SetUserAgentOverride(userAgent).WithAcceptLanguage(options.Locale).Do(ctx); err != nil {
+			return err
+		}
+	}
+	// Chrome refuses a second locale or timezone override, so the current
+	// one is dropped first.
+	if options.Locale != "" {
+		if err := emulation.SetLocaleOverride().Do(ctx); err != nil {
+			return err
+		}
+		if err := emulation.SetLocaleOverride().WithLocale(options.Locale).Do(ctx); err != nil {
+			return fmt.Errorf("%w: locale %q: %v", ErrInvalidOption, options.Locale, err)
+		}
+	}
+	if options.Timezone != "" {
+		if err := emulation.SetTimezoneOverride("").Do(ctx); err != nil {
+			return err
+		}
+		if err := emulation.SetTimezoneOverride(options.Timezone).Do(ctx); err != nil {
+			return fmt.Errorf("%w: timezone %q: %v", ErrInvalidOption, options.Timezone, err)
+		}
+	}
+	if geo := options.Geolocation; geo != nil {
+		accuracy := geo.Accuracy
+		if accuracy == 0 {
+			accuracy = 10
+		}
+		permissions := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation})
+		if service.browserContextID != "" {
+			permissions = permissions.WithBrowserContextID(service.browserContextID)
+		}
+		if err := permissions.Do(ctx); err != nil {
+			return err
+		}
+		if err := emulation.SetGeolocationOverride().WithLatitude(geo.Latitude).WithLongitude(geo.Longitude).WithAccuracy(accuracy).Do(ctx); err != nil {
+			return err
+		}
+	}
+	if options.ColorScheme != "" || options.ReducedMotion != "" {
+		var features []*emulation.MediaFeature
+		if options.ColorScheme != "" {
+			features = append(features, &emulation.MediaFeature{Name: "prefers-color-scheme", Value: options.ColorScheme})
+		}
+		if options.ReducedMotion != "" {
+			features = append(features, &emulation.MediaFeature{Name: "prefers-reduced-motion", Value: options.ReducedMotion})
+		}
+		if err := emulation.SetEmulatedMedia().WithFeatures(features).Do(ctx); err != nil {
+			return err
+		}
+	}
+	if options.Offline != nil || options.Network != "" {
+		offline := options.Offline != nil && *options.Offline
+		profile, throttled := networkProfiles[options.Network]
+		conditions := network.EmulateNetworkConditions(offline, 0, -1, -1)
+		if throttled {
+			conditions = network.EmulateNetworkConditions(offline, profile.latency, profile.download, profile.upload)
+		}
+		if err := conditions.Do(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
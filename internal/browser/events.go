@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	EventTabCreated       = "tab.created"
+	EventTabClosed        = "tab.closed"
+	EventTabActivated     = "tab.activated"
+	EventTabNavigated     = "tab.navigated"
+	EventTabTitleChanged  = "tab.title_changed"
+	EventDownloadStarted  = "download.started"
+	EventDownloadFinished = "download.finished"
+	EventDialogOpened     = "dialog.opened"
+	EventDialogClosed     = "dialog.closed"
+
+	eventBufferSize = 64
+)
+
+// Event reports a change to the tabs, downloads or dialogs of a context.
+type Event struct {
+	Type     string        `json:"type"`
+	Context  string        `json:"context"`
+	TabID    string        `json:"tab_id,omitempty"`
+	OpenerID string        `json:"opener_id,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	Title    string        `json:"title,omitempty"`
+	Download *DownloadInfo `json:"download,omitempty"`
+	Dialog   *Dialog       `json:"dialog,omitempty"`
+	Time     time.Time     `json:"time"`
+}
+
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]string
+}
+
+// Subscribe returns events of this context until cancel is called. Events
+// are dropped for subscribers that fall behind rather than blocking the
+// browser.
+func (service *Service) Subscribe() (<-chan Event, func()) {
+	events := make(chan Event, eventBufferSize)
+	hub := &service.events
+	hub.mu.Lock()
+	if hub.subscribers == nil {
+		hub.subscribers = make(map[chan Event]string)
+	}
+	hub.subscribers[events] = service.name
+	hub.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			hub.mu.Lock()
+			delete(hub.subscribers, events)
+			hub.mu.Unlock()
+			close(events)
+		})
+	}
+	return events, cancel
+}
+
+func (service *Service) publish(event Event) {
+	event.Context = service.name
+	event.Time = time.Now()
+	hub := &service.events
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for subscriber, name := range hub.subscribers {
+		if name != service.name {
+			continue
+		}
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HAR is an HTTP Archive 1.2 document.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string        `json:"startedDateTime"`
+	Time            float64       `json:"time"`
+	Request         HARRequest    `json:"request"`
+	Response        HARResponse   `json:"response"`
+	Cache           struct{}      `json:"cache"`
+	Timings         NetworkTiming `json:"timings"`
+	ServerIPAddress string        `json:"serverIPAddress,omitempty"`
+	Comment         string        `json:"comment,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int64          `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARExportOptions struct {
+	Path string
+	Tab  TabRef
+	// IncludeBodies fetches each response body from the browser, which is
+	// slow for large pages.
+	IncludeBodies bool
+}
+
+type HARExportResult struct {
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+}
+
+// BuildHAR converts recorded entries into a HAR document. bodies is keyed
+// by request ID and may be nil.
+func BuildHAR(entries []NetworkEntry, bodies map[string]ResponseBody) HAR {
+	har := HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "open-sandbox", Version: "dev"},
+		Entries: make([]HAREntry, 0, len(entries)),
+	}}
+	for _, entry := range entries {
+		harEntry := HAREntry{
+			StartedDateTime: entry.StartedAt.UTC().Format(time.RFC3339Nano),
+			Time:            entry.DurationMS,
+			Request: HARRequest{
+				Method:      entry.Method,
+				URL:         entry.URL,
+				HTTPVersion: harHTTPVersion(entry.Protocol),
+				Cookies:     []HARNameValue{},
+				Headers:     harHeaders(entry.RequestHeaders),
+				QueryString: harQueryString(entry.URL),
+				HeadersSize: -1,
+				BodySize:    -1,
+			},
+			Response: HARResponse{
+				Status:      entry.Status,
+				StatusText:  entry.StatusText,
+				HTTPVersion: harHTTPVersion(entry.Protocol),
+				Cookies:     []HARNameValue{},
+				Headers:     harHeaders(entry.ResponseHeaders),
+				Content:     HARContent{Size: int(entry.Size), MimeType: entry.MimeType},
+				RedirectURL: entry.RedirectURL,
+				HeadersSize: -1,
+				BodySize:    entry.EncodedSize,
+			},
+			Timings:         NetworkTiming{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Send: 0, Wait: entry.DurationMS, Receive: 0},
+			ServerIPAddress: strings.Trim(entry.RemoteIP, "[]"),
+		}
+		if entry.Timing != nil {
+			harEntry.Timings = *entry.Timing
+		}
+		if entry.Failed {
+			harEntry.Comment = entry.ErrorText
+			harEntry.Response.BodySize = -1
+		}
+		if body, ok := bodies[entry.RequestID]; ok && entry.RedirectURL == "" {
+			harEntry.Response.Content.Size = body.Size
+			harEntry.Response.Content.Text = body.Body
+			if body.Base64 {
+				harEntry.Response.Content.Encoding = "base64"
+			}
+		}
+		har.Log.Entries = append(har.Log.Entries, harEntry)
+	}
+	return har
+}
+
+// ExportHAR writes the requests recorded for a tab to options.Path.
+func (service *Service) ExportHAR(options HARExportOptions) (*HARExportResult, error) {
+	if options.Path == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidOption)
+	}
+	state, err := service.tabStateFor(options.Tab)
+	if err != nil {
+		return nil, err
+	}
+	entries := []NetworkEntry{}
+	if state != nil {
+		entries, _, _ = state.network.ring.since(0, 0, nil)
+	}
+
+	var bodies map[string]ResponseBody
+	if options.IncludeBodies {
+		bodies = make(map[string]ResponseBody)
+		for _, entry := range entries {
+			if !entry.Finished || entry.Failed || entry.RedirectURL != "" {
+				continue
+			}
+			if body, err := service.ResponseBody(entry.RequestID, options.Tab); err == nil {
+				bodies[entry.RequestID] = *body
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(BuildHAR(entries, bodies), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(options.Path, data, 0644); err != nil {
+		return nil, err
+	}
+	return &HARExportResult{Path: options.Path, Entries: len(entries)}, nil
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return "HTTP/1.1"
+	case "h2":
+		return "HTTP/2"
+	case "h3":
+		return "HTTP/3"
+	}
+	return strings.ToUpper(protocol)
+}
+
+func harHeaders(headers map[string]string) []HARNameValue {
+	result := make([]HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		// Chrome joins repeated headers with newlines.
+		for _, part := range strings.Split(value, "\n") {
+			result = append(result, HARNameValue{Name: name, Value: part})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func harQueryString(rawURL string) []HARNameValue {
+	result := []HARNameValue{}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return result
+	}
+	for name, values := range parsed.Query() {
+		for _, value := range values {
+			result = append(result, HARNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
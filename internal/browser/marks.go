@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrStaleMark reports an element number that no longer points at a live
+// element, either because the page changed or a newer screenshot replaced it.
+var ErrStaleMark = fmt.Errorf("%w: stale element number, take a new marked screenshot", ErrInvalidLocator)
+
+// Mark describes one numbered element of a set-of-marks screenshot. The box
+// is in the same coordinates as the screenshot: the viewport, or the page
+// for full-page captures.
+type Mark struct {
+	Number   int     `json:"number"`
+	Selector string  `json:"selector"`
+	Role     string  `json:"role"`
+	Name     string  `json:"name,omitempty"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+}
+
+// markElements numbers the visible interactive elements and draws the
+// overlay. The marks replace those of the previous marked screenshot and
+// are resolved by Locator.Element until the next one.
+func (service *Service) markElements(ctx context.Context, fullPage bool) ([]Mark, error) {
+	result, exception, err := runtime.Evaluate("(" + markElementsJS + ")(" + strconv.FormatBool(fullPage) + ")").Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, fmt.Errorf("mark elements: %s", exceptionText(exception))
+	}
+	defer func() {
+		_ = runtime.ReleaseObject(result.ObjectID).Do(ctx)
+	}()
+
+	var marks []Mark
+	if err := callElementInto(ctx, result.ObjectID, describeMarksJS, &marks, fullPage); err != nil {
+		return nil, err
+	}
+	for i := range marks {
+		marks[i].Name = truncateName(marks[i].Name)
+	}
+	properties, _, _, _, err := runtime.GetProperties(result.ObjectID).WithOwnProperties(true).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]cdp.BackendNodeID, len(marks))
+	for _, property := range properties {
+		index, err := strconv.Atoi(property.Name)
+		if err != nil || property.Value == nil || property.Value.ObjectID == "" {
+			continue
+		}
+		node, err := dom.DescribeNode().WithObjectID(property.Value.ObjectID).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nodes[strconv.Itoa(index+1)] = node.BackendNodeID
+	}
+
+	refs := elementRefs{nodes: nodes}
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+		refs.target = c.Target.TargetID
+	}
+	service.refsMu.Lock()
+	service.marks = refs
+	service.refsMu.Unlock()
+	return marks, nil
+}
+
+func clearMarks(ctx context.Context) error {
+	return evaluateInto(ctx, clearMarksJS, nil)
+}
+
+// lookupMark returns the DOM node numbered number by the latest marked
+// screenshot of the tab that ctx runs against.
+func (service *Service) lookupMark(ctx context.Context, number int) (cdp.BackendNodeID, error) {
+	service.refsMu.Lock()
+	marks := service.marks
+	service.refsMu.Unlock()
+
+	backendID, ok := marks.nodes[strconv.Itoa(number)]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown element %d, take a new marked screenshot", ErrInvalidLocator, number)
+	}
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil && c.Target.TargetID != marks.target {
+		return 0, ErrStaleMark
+	}
+	return backendID, nil
+}
+
+const marksOverlayID = "__open_sandbox_marks"
+
+// markElementsJS returns the marked elements in number order. Elements
+// covered by others are skipped so every number points at something the
+// model can actually see.
+const markElementsJS = `function(fullPage) {
+` + domHelpersJS + `
+  const interactive = new Set(['button', 'checkbox', 'combobox', 'link', 'listbox', 'menuitem',
+    'menuitemcheckbox', 'menuitemradio', 'option', 'radio', 'searchbox', 'slider', 'spinbutton',
+    'switch', 'tab', 'textbox', 'treeitem']);
+  const old = document.getElementById('` + marksOverlayID + `');
+  if (old) old.remove();
+
+  const candidates = document.body ? document.body.querySelectorAll(
+    'a[href], button, input, select, textarea, summary, [role], [onclick], [contenteditable=""], [contenteditable="true"], [tabindex]:not([tabindex="-1"])') : [];
+  const marked = [];
+  for (const el of candidates) {
+    if (!interactive.has(roleOf(el)) && !el.isContentEditable && !el.hasAttribute('onclick') && !el.hasAttribute('tabindex')) continue;
+    if (!isVisible(el) || el.closest('[aria-hidden="true"], [inert]')) continue;
+    if (marked.some((parent) => parent.contains(el))) continue;
+    const rect = el.getBoundingClientRect();
+    if (!fullPage) {
+      if (rect.bottom <= 0 || rect.right <= 0 || rect.top >= innerHeight || rect.left >= innerWidth) continue;
+      const x = Math.min(Math.max(rect.left + rect.width / 2, 0), innerWidth - 1);
+      const y = Math.min(Math.max(rect.top + rect.height / 2, 0), innerHeight - 1);
+      const top = document.elementFromPoint(x, y);
+      if (top && top !== el && !el.contains(top) && !top.contains(el)) continue;
+    }
+    marked.push(el);
+  }
+
+  const overlay = document.createElement('div');
+  overlay.id = '` + marksOverlayID + `';
+  overlay.style.cssText = 'position:absolute;left:0;top:0;width:0;height:0;pointer-events:none;z-index:2147483647;';
+  const colors = ['#e6194b', '#3cb44b', '#4363d8', '#f58231', '#911eb4', '#008080', '#9a6324', '#800000'];
+  marked.forEach((el, index) => {
+    const rect = el.getBoundingClientRect();
+    const color = colors[index % colors.length];
+    const box = document.createElement('div');
+    box.style.cssText = 'position:absolute;box-sizing:border-box;border:2px solid ' + color + ';' +
+      'left:' + (rect.left + scrollX) + 'px;top:' + (rect.top + scrollY) + 'px;' +
+      'width:' + rect.width + 'px;height:' + rect.height + 'px;';
+    const label = document.createElement('span');
+    label.textContent = String(index + 1);
+    label.style.cssText = 'position:absolute;left:-2px;top:-2px;transform:translateY(-100%);' +
+      'background:' + color + ';color:#fff;font:bold 12px/14px monospace;padding:0 3px;';
+    if (rect.top < 14) label.style.transform = 'none';
+    box.appendChild(label);
+    overlay.appendChild(box);
+  });
+  document.documentElement.appendChild(overlay);
+  return marked;
+}`
+
+// describeMarksJS runs with the array from markElementsJS as this.
+const describeMarksJS = `function(fullPage) {
+` + domHelpersJS + `
+  const selectorOf = (el) => {
+    if (el.id && document.querySelectorAll('#' + CSS.escape(el.id)).length === 1) return '#' + CSS.escape(el.id);
+    const parts = [];
+    for (let node = el; node && node.nodeType === Node.ELEMENT_NODE && node !== document.documentElement; node = node.parentElement) {
+      if (node.id && document.querySelectorAll('#' + CSS.escape(node.id)).length === 1) {
+        parts.unshift('#' + CSS.escape(node.id));
+        break;
+      }
+      let part = node.tagName.toLowerCase();
+      const siblings = node.parentElement ? Array.from(node.parentElement.children).filter((s) => s.tagName === node.tagName) : [];
+      if (siblings.length > 1) part += ':nth-of-type(' + (siblings.indexOf(node) + 1) + ')';
+      parts.unshift(part);
+    }
+    return parts.join(' > ');
+  };
+  return Array.from(this, (el, index) => {
+    const rect = el.getBoundingClientRect();
+    return {
+      number: index + 1,
+      selector: selectorOf(el),
+      role: roleOf(el) || el.tagName.toLowerCase(),
+      name: nameOf(el),
+      x: rect.left + (fullPage ? scrollX : 0),
+      y: rect.top + (fullPage ? scrollY : 0),
+      width: rect.width,
+      height: rect.height,
+    };
+  });
+}`
+
+const clearMarksJS = `function() {
+  const overlay = document.getElementById('` + marksOverlayID + `');
+  if (overlay) overlay.remove();
+}`
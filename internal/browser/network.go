@@ -0,0 +1,353 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+)
+
+const (
+	networkBufferSize   = 1000
+	DefaultNetworkLimit = 200
+)
+
+type NetworkEntry struct {
+	Seq             uint64            `json:"seq"`
+	RequestID       string            `json:"request_id"`
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	ResourceType    string            `json:"type,omitempty"`
+	Status          int64             `json:"status,omitempty"`
+	StatusText      string            `json:"status_text,omitempty"`
+	MimeType        string            `json:"mime_type,omitempty"`
+	Protocol        string            `json:"protocol,omitempty"`
+	RemoteIP        string            `json:"remote_ip,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	DurationMS      float64           `json:"duration_ms"`
+	// EncodedSize is the number of bytes received over the wire and Size
+	// the decoded body length.
+	EncodedSize int64          `json:"encoded_size"`
+	Size        int64          `json:"size"`
+	FromCache   bool           `json:"from_cache,omitempty"`
+	Finished    bool           `json:"finished"`
+	Failed      bool           `json:"failed,omitempty"`
+	ErrorText   string         `json:"error,omitempty"`
+	RedirectURL string         `json:"redirect_url,omitempty"`
+	Timing      *NetworkTiming `json:"timing,omitempty"`
+
+	start time.Time
+	// headersEnd is when response headers arrived, in milliseconds after
+	// start; it splits wait from receive time.
+	headersEnd float64
+}
+
+// NetworkTiming breaks a request down the way HAR does, in milliseconds.
+// Phases that did not happen are -1.
+type NetworkTiming struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type NetworkQuery struct {
+	Tab TabRef
+	// URL matches entries whose URL contains it.
+	URL          string
+	Method       string
+	ResourceType string
+	// Failed keeps only network failures and responses with status >= 400.
+	Failed bool
+	Since  uint64
+	Limit  int
+}
+
+type NetworkLog struct {
+	Entries []NetworkEntry `json:"entries"`
+	Cursor  uint64         `json:"cursor"`
+	Dropped bool           `json:"dropped"`
+}
+
+type ResponseBody struct {
+	RequestID string `json:"request_id"`
+	MimeType  string `json:"mime_type,omitempty"`
+	Body      string `json:"body"`
+	// Base64 is set when the body is not valid UTF-8 and Body holds its
+	// base64 encoding.
+	Base64 bool `json:"base64"`
+	Size   int  `json:"size"`
+}
+
+// networkLog tracks requests for one tab. The index maps in-flight request
+// IDs to ring sequence numbers so later events update the same entry.
+type networkLog struct {
+	mu    sync.Mutex
+	ring  *ringBuffer[NetworkEntry]
+	index map[network.RequestID]uint64
+}
+
+func newNetworkLog() *networkLog {
+	return &networkLog{
+		ring:  newRingBuffer[NetworkEntry](networkBufferSize),
+		index: make(map[network.RequestID]uint64),
+	}
+}
+
+func (requests *networkLog) handleEvent(ev any) {
+	requests.mu.Lock()
+	defer requests.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if e.RedirectResponse != nil {
+			requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+				applyResponse(entry, e.RedirectResponse)
+				entry.RedirectURL = e.Request.URL
+				finishEntry(entry, monotonic(e.Timestamp))
+			})
+		}
+		requests.startLocked(e)
+	case *network.EventResponseReceived:
+		requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+			if entry.ResourceType == "" {
+				entry.ResourceType = string(e.Type)
+			}
+			applyResponse(entry, e.Response)
+		})
+	case *network.EventRequestServedFromCache:
+		requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+			entry.FromCache = true
+		})
+	case *network.EventDataReceived:
+		requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+			entry.Size += e.DataLength
+		})
+	case *network.EventLoadingFinished:
+		requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+			entry.EncodedSize = int64(e.EncodedDataLength)
+			finishEntry(entry, monotonic(e.Timestamp))
+		})
+		delete(requests.index, e.RequestID)
+	case *network.EventLoadingFailed:
+		requests.updateLocked(e.RequestID, func(entry *NetworkEntry) {
+			entry.Failed = true
+			entry.ErrorText = e.ErrorText
+			if e.BlockedReason != "" {
+				entry.ErrorText += " (" + string(e.BlockedReason) + ")"
+			}
+			finishEntry(entry, monotonic(e.Timestamp))
+		})
+		delete(requests.index, e.RequestID)
+	}
+}
+
+func (requests *networkLog) startLocked(e *network.EventRequestWillBeSent) {
+	if e.Request == nil {
+		return
+	}
+	entry := NetworkEntry{
+		RequestID:      string(e.RequestID),
+		URL:            e.Request.URL + e.Request.URLFragment,
+		Method:         e.Request.Method,
+		ResourceType:   string(e.Type),
+		RequestHeaders: headerMap(e.Request.Headers),
+		StartedAt:      time.Now(),
+		start:          monotonic(e.Timestamp),
+	}
+	if e.WallTime != nil {
+		entry.StartedAt = e.WallTime.Time()
+	}
+	var seq uint64
+	requests.ring.push(func(next uint64) NetworkEntry {
+		seq = next
+		entry.Seq = next
+		return entry
+	})
+	requests.index[e.RequestID] = seq
+
+	// Requests that never finish (long polls, aborted navigations) would
+	// otherwise pin their index slot after the ring has evicted them.
+	if len(requests.index) > 2*networkBufferSize {
+		oldest := requests.ring.oldest()
+		for id, entrySeq := range requests.index {
+			if entrySeq < oldest {
+				delete(requests.index, id)
+			}
+		}
+	}
+}
+
+func (requests *networkLog) updateLocked(id network.RequestID, fn func(entry *NetworkEntry)) {
+	seq, ok := requests.index[id]
+	if !ok {
+		return
+	}
+	if !requests.ring.update(seq, fn) {
+		delete(requests.index, id)
+	}
+}
+
+func applyResponse(entry *NetworkEntry, response *network.Response) {
+	if response == nil {
+		return
+	}
+	entry.Status = response.Status
+	entry.StatusText = response.StatusText
+	entry.MimeType = response.MimeType
+	entry.Protocol = response.Protocol
+	entry.RemoteIP = response.RemoteIPAddress
+	entry.ResponseHeaders = headerMap(response.Headers)
+	entry.FromCache = entry.FromCache || response.FromDiskCache || response.FromPrefetchCache
+	if response.EncodedDataLength > 0 {
+		entry.EncodedSize = int64(response.EncodedDataLength)
+	}
+	if timing := response.Timing; timing != nil {
+		entry.headersEnd = timing.ReceiveHeadersEnd
+		entry.Timing = &NetworkTiming{
+			Blocked: firstNonNegative(timing.DNSStart, timing.ConnectStart, timing.SendStart),
+			DNS:     phase(timing.DNSStart, timing.DNSEnd),
+			Connect: phase(timing.ConnectStart, timing.ConnectEnd),
+			SSL:     phase(timing.SslStart, timing.SslEnd),
+			Send:    phase(timing.SendStart, timing.SendEnd),
+			Wait:    phase(timing.SendEnd, timing.ReceiveHeadersEnd),
+			Receive: 0,
+		}
+	}
+}
+
+func finishEntry(entry *NetworkEntry, end time.Time) {
+	entry.Finished = true
+	if entry.start.IsZero() || end.IsZero() {
+		return
+	}
+	entry.DurationMS = float64(end.Sub(entry.start).Microseconds()) / 1000
+	if entry.Timing != nil && entry.DurationMS > entry.headersEnd {
+		// Copy rather than mutate: readers may hold the previous pointer.
+		timing := *entry.Timing
+		timing.Receive = entry.DurationMS - entry.headersEnd
+		entry.Timing = &timing
+	}
+}
+
+func phase(start, end float64) float64 {
+	if start < 0 || end < 0 {
+		return -1
+	}
+	return end - start
+}
+
+func firstNonNegative(values ...float64) float64 {
+	for _, value := range values {
+		if value >= 0 {
+			return value
+		}
+	}
+	return -1
+}
+
+func monotonic(timestamp *cdp.MonotonicTime) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.Time()
+}
+
+func headerMap(headers network.Headers) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(headers))
+	for name, value := range headers {
+		result[name] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// NetworkRequests lists requests recorded for a tab, oldest first.
+func (service *Service) NetworkRequests(query NetworkQuery) (*NetworkLog, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultNetworkLimit
+	}
+	state, err := service.tabStateFor(query.Tab)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return &NetworkLog{Entries: []NetworkEntry{}, Cursor: query.Since}, nil
+	}
+	urlFilter := strings.ToLower(query.URL)
+	entries, cursor, dropped := state.network.ring.since(query.Since, query.Limit, func(entry NetworkEntry) bool {
+		switch {
+		case urlFilter != "" && !strings.Contains(strings.ToLower(entry.URL), urlFilter):
+			return false
+		case query.Method != "" && !strings.EqualFold(entry.Method, query.Method):
+			return false
+		case query.ResourceType != "" && !strings.EqualFold(entry.ResourceType, query.ResourceType):
+			return false
+		case query.Failed && !entry.Failed && entry.Status < 400:
+			return false
+		}
+		return true
+	})
+	return &NetworkLog{Entries: entries, Cursor: cursor, Dropped: dropped}, nil
+}
+
+// ResponseBody fetches a response body from the browser. Chrome keeps
+// bodies only while the page that loaded them is alive.
+func (service *Service) ResponseBody(requestID string, tab TabRef) (*ResponseBody, error) {
+	if requestID == "" {
+		return nil, fmt.Errorf("%w: request_id is required", ErrInvalidOption)
+	}
+	state, err := service.tabStateFor(tab)
+	if err != nil {
+		return nil, err
+	}
+	result := &ResponseBody{RequestID: requestID}
+	if state != nil {
+		if entry, ok := state.network.find(requestID); ok {
+			result.MimeType = entry.MimeType
+		}
+	}
+
+	var body []byte
+	err = service.runOnTab(tab, service.config.NavigateTimeout, func(ctx context.Context) error {
+		var err error
+		body, err = network.GetResponseBody(network.RequestID(requestID)).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("response body unavailable: %w", err)
+	}
+	result.Size = len(body)
+	if utf8.Valid(body) {
+		result.Body = string(body)
+	} else {
+		result.Body = base64.StdEncoding.EncodeToString(body)
+		result.Base64 = true
+	}
+	return result, nil
+}
+
+// find returns the newest entry for requestID; redirects share an ID, and
+// the final hop is the one with a body.
+func (requests *networkLog) find(requestID string) (NetworkEntry, bool) {
+	entries, _, _ := requests.ring.since(0, 0, func(entry NetworkEntry) bool {
+		return entry.RequestID == requestID
+	})
+	if len(entries) == 0 {
+		return NetworkEntry{}, false
+	}
+	return entries[len(entries)-1], true
+}
@@ -0,0 +1,151 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// paperSizes are width and height in inches.
+var paperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"ledger":  {17, 11},
+	"a0":      {33.1, 46.8},
+	"a1":      {23.4, 33.1},
+	"a2":      {16.54, 23.4},
+	"a3":      {11.7, 16.54},
+	"a4":      {8.27, 11.7},
+	"a5":      {5.83, 8.27},
+	"a6":      {4.13, 5.83},
+}
+
+var pageRangesPattern = regexp.MustCompile(`^\s*\d+(\s*-\s*\d*)?(\s*,\s*\d+(\s*-\s*\d*)?)*\s*$`)
+
+// PDFOptions controls Page.printToPDF. Lengths are in inches. Width and
+// Height override Format, which defaults to letter. Header and footer
+// templates are HTML and may use the pageNumber, totalPages, title, url and
+// date classes.
+type PDFOptions struct {
+	Path              string
+	Format            string
+	Width             float64
+	Height            float64
+	Margin            *PDFMargin
+	Landscape         bool
+	PageRanges        string
+	HeaderTemplate    string
+	FooterTemplate    string
+	PrintBackground   bool
+	Scale             float64
+	PreferCSSPageSize bool
+}
+
+type PDFMargin struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+type PDFResult struct {
+	Path  string `json:"path"`
+	Bytes int    `json:"bytes"`
+}
+
+func (options *PDFOptions) validate() error {
+	if options.Path == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidOption)
+	}
+	if options.Format != "" {
+		if _, ok := paperSizes[strings.ToLower(options.Format)]; !ok {
+			return fmt.Errorf("%w: unknown paper format %q", ErrInvalidOption, options.Format)
+		}
+	}
+	if options.Width < 0 || options.Height < 0 {
+		return fmt.Errorf("%w: width and height must be positive", ErrInvalidOption)
+	}
+	if margin := options.Margin; margin != nil && (margin.Top < 0 || margin.Right < 0 || margin.Bottom < 0 || margin.Left < 0) {
+		return fmt.Errorf("%w: margins must not be negative", ErrInvalidOption)
+	}
+	if options.PageRanges != "" && !pageRangesPattern.MatchString(options.PageRanges) {
+		return fmt.Errorf("%w: page_ranges must look like 1-3, 5", ErrInvalidOption)
+	}
+	if options.Scale != 0 && (options.Scale < 0.1 || options.Scale > 2) {
+		return fmt.Errorf("%w: scale must be between 0.1 and 2", ErrInvalidOption)
+	}
+	return nil
+}
+
+// PrintToPDF renders the active tab as a PDF and writes it to options.Path.
+func (service *Service) PrintToPDF(options PDFOptions) (*PDFResult, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	size := paperSizes["letter"]
+	if options.Format != "" {
+		size = paperSizes[strings.ToLower(options.Format)]
+	}
+	if options.Width > 0 {
+		size[0] = options.Width
+	}
+	if options.Height > 0 {
+		size[1] = options.Height
+	}
+
+	params := page.PrintToPDF().
+		WithPaperWidth(size[0]).
+		WithPaperHeight(size[1]).
+		WithLandscape(options.Landscape).
+		WithPrintBackground(options.PrintBackground).
+		WithPreferCSSPageSize(options.PreferCSSPageSize).
+		WithPageRanges(options.PageRanges)
+	if options.Scale > 0 {
+		params = params.WithScale(options.Scale)
+	}
+	if margin := options.Margin; margin != nil {
+		params = params.
+			WithMarginTop(margin.Top).
+			WithMarginRight(margin.Right).
+			WithMarginBottom(margin.Bottom).
+			WithMarginLeft(margin.Left)
+	}
+	if options.HeaderTemplate != "" || options.FooterTemplate != "" {
+		// Chrome prints its own header or footer for an empty template.
+		header, footer := options.HeaderTemplate, options.FooterTemplate
+		if header == "" {
+			header = "<span></span>"
+		}
+		if footer == "" {
+			footer = "<span></span>"
+		}
+		params = params.WithDisplayHeaderFooter(true).WithHeaderTemplate(header).WithFooterTemplate(footer)
+	}
+
+	var data []byte
+	err := service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			data, _, err = params.Do(ctx)
+			return err
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(options.Path, data, 0644); err != nil {
+		return nil, err
+	}
+	return &PDFResult{Path: options.Path, Bytes: len(data)}, nil
+}
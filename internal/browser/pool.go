@@ -0,0 +1,279 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBrowserID = "default"
+
+	DefaultPoolMaxInstances = 4
+	DefaultPoolIdleTimeout  = 10 * time.Minute
+)
+
+var (
+	ErrBrowserNotFound = errors.New("browser not found")
+	ErrPoolExhausted   = errors.New("browser pool exhausted")
+)
+
+// browserPool holds the browsers leased next to the default one. Each has
+// its own engine, so calls on one never wait for another.
+type browserPool struct {
+	mu        sync.Mutex
+	instances map[string]*poolInstance
+	seq       int
+	reaper    *idleReaper
+}
+
+type poolInstance struct {
+	id          string
+	session     string
+	service     *Service
+	idleTimeout time.Duration
+	leasedAt    time.Time
+	lastUsed    time.Time
+}
+
+type LeaseOptions struct {
+	// Session gets back the browser it already leased instead of a new one.
+	Session string
+	// IdleTimeout overrides Config.PoolIdleTimeout for this browser.
+	IdleTimeout time.Duration
+}
+
+// BrowserInfo describes a browser of the pool. The default browser is
+// never reclaimed and has no lease.
+type BrowserInfo struct {
+	ID        string        `json:"id"`
+	Session   string        `json:"session,omitempty"`
+	LeasedAt  *time.Time    `json:"leased_at,omitempty"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	Status    BrowserStatus `json:"status"`
+}
+
+// Browser returns the main context of the browser id names; empty selects
+// the default browser. Using a leased browser keeps it from being
+// reclaimed.
+func (service *Service) Browser(id string) (*Service, error) {
+	service.sweepBrowsers()
+	if id == "" || id == DefaultBrowserID {
+		return service.primary, nil
+	}
+	pool := service.pool
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	instance, ok := pool.instances[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrBrowserNotFound, id)
+	}
+	instance.lastUsed = time.Now()
+	return instance.service, nil
+}
+
+// LeaseBrowser starts a browser of its own for a session, with a separate
+// process, user data dir and CDP port. It fails with ErrPoolExhausted
+// once Config.PoolMaxInstances browsers run, counting the default one.
+func (service *Service) LeaseBrowser(options LeaseOptions) (*BrowserInfo, error) {
+	service.sweepBrowsers()
+	if options.Session != "" && !contextNamePattern.MatchString(options.Session) {
+		return nil, fmt.Errorf("%w: session must be 1-64 letters, digits, '.', '_' or '-'", ErrInvalidOption)
+	}
+	config := service.primary.config
+	pool := service.pool
+
+	pool.mu.Lock()
+	if options.Session != "" {
+		for _, instance := range pool.instances {
+			if instance.session == options.Session {
+				instance.lastUsed = time.Now()
+				info := instance.info()
+				pool.mu.Unlock()
+				return &info, nil
+			}
+		}
+	}
+	if len(pool.instances)+1 >= config.PoolMaxInstances {
+		pool.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d browsers running", ErrPoolExhausted, config.PoolMaxInstances)
+	}
+	pool.seq++
+	id := "browser-" + strconv.Itoa(pool.seq)
+	idleTimeout := options.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = config.PoolIdleTimeout
+	}
+	leasedConfig, err := instanceConfig(config, id)
+	if err != nil {
+		pool.mu.Unlock()
+		return nil, err
+	}
+	now := time.Now()
+	instance := &poolInstance{
+		id:          id,
+		session:     options.Session,
+		service:     newService(leasedConfig, pool, service.primary),
+		idleTimeout: idleTimeout,
+		leasedAt:    now,
+		lastUsed:    now,
+	}
+	pool.instances[id] = instance
+	pool.mu.Unlock()
+	pool.reaper.poke()
+
+	if err := instance.service.Start(); err != nil {
+		pool.mu.Lock()
+		delete(pool.instances, id)
+		pool.mu.Unlock()
+		instance.service.release()
+		return nil, err
+	}
+	pool.mu.Lock()
+	info := instance.info()
+	pool.mu.Unlock()
+	return &info, nil
+}
+
+// ReleaseBrowser closes a leased browser and everything in it.
+func (service *Service) ReleaseBrowser(id string) error {
+	if id == "" || id == DefaultBrowserID {
+		return fmt.Errorf("%w: the default browser cannot be released", ErrInvalidOption)
+	}
+	pool := service.pool
+	pool.mu.Lock()
+	instance, ok := pool.instances[id]
+	delete(pool.instances, id)
+	pool.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrBrowserNotFound, id)
+	}
+	instance.service.release()
+	return nil
+}
+
+// release shuts a leased browser down for good, so callers still holding
+// it get ErrBrowserNotFound instead of a new process, and deletes its
+// profile.
+func (service *Service) release() {
+	service.mu.Lock()
+	service.released = true
+	service.resetLocked()
+	service.mu.Unlock()
+	_ = os.RemoveAll(service.config.UserDataDir)
+}
+
+// Browsers lists the default browser followed by leased ones in lease
+// order.
+func (service *Service) Browsers() []BrowserInfo {
+	service.sweepBrowsers()
+	pool := service.pool
+	pool.mu.Lock()
+	instances := make([]*poolInstance, 0, len(pool.instances))
+	infos := make([]BrowserInfo, 0, len(pool.instances)+1)
+	for _, instance := range pool.instances {
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].leasedAt.Before(instances[j].leasedAt) ||
+			(instances[i].leasedAt.Equal(instances[j].leasedAt) && instances[i].id < instances[j].id)
+	})
+	for _, instance := range instances {
+		infos = append(infos, instance.info())
+	}
+	pool.mu.Unlock()
+	return append([]BrowserInfo{{ID: DefaultBrowserID, Status: service.primary.Status()}}, infos...)
+}
+
+// info needs pool.mu.
+func (instance *poolInstance) info() BrowserInfo {
+	leasedAt := instance.leasedAt
+	info := BrowserInfo{
+		ID:       instance.id,
+		Session:  instance.session,
+		LeasedAt: &leasedAt,
+		Status:   instance.service.Status(),
+	}
+	if instance.idleTimeout > 0 {
+		expires := instance.lastUsed.Add(instance.idleTimeout)
+		info.ExpiresAt = &expires
+	}
+	return info
+}
+
+// sweepBrowsers releases leased browsers that have been idle too long and
+// returns when the next one expires. It runs on lookups and from the pool
+// reaper.
+func (service *Service) sweepBrowsers() (time.Time, bool) {
+	now := time.Now()
+	var expired []*poolInstance
+	var next time.Time
+	pool := service.pool
+	pool.mu.Lock()
+	for id, instance := range pool.instances {
+		if instance.idleTimeout <= 0 {
+			continue
+		}
+		expires := instance.lastUsed.Add(instance.idleTimeout)
+		if now.After(expires) {
+			delete(pool.instances, id)
+			expired = append(expired, instance)
+		} else if next.IsZero() || expires.Before(next) {
+			next = expires
+		}
+	}
+	pool.mu.Unlock()
+	for _, instance := range expired {
+		instance.service.release()
+	}
+	return next, !next.IsZero()
+}
+
+// releaseBrowsers releases every leased browser, as when the default one
+// is closed.
+func (service *Service) releaseBrowsers() {
+	pool := service.pool
+	pool.mu.Lock()
+	instances := pool.instances
+	pool.instances = make(map[string]*poolInstance)
+	pool.mu.Unlock()
+	for _, instance := range instances {
+		instance.service.release()
+	}
+}
+
+// instanceConfig derives the config of a leased browser from the default
+// one. It always launches its own process on a free port, with downloads,
+// recordings and logs kept apart and a fresh profile, since ids are reused
+// across server restarts.
+func instanceConfig(config Config, id string) (Config, error) {
+	config.ExistingWebSocketDebug = ""
+	config.RemoteDebuggingPort = 0
+	userDataDir := config.UserDataDir
+	if userDataDir == "" {
+		userDataDir = filepath.Join(os.TempDir(), "open-sandbox-chrome")
+	}
+	if err := os.MkdirAll(filepath.Dir(userDataDir), 0755); err != nil {
+		return config, err
+	}
+	profile, err := os.MkdirTemp(filepath.Dir(userDataDir), filepath.Base(userDataDir)+"-"+id+"-")
+	if err != nil {
+		return config, err
+	}
+	config.UserDataDir = profile
+	if config.DownloadDir != "" {
+		config.DownloadDir = filepath.Join(config.DownloadDir, "browsers", id)
+	}
+	if config.RecordingDir != "" {
+		config.RecordingDir = filepath.Join(config.RecordingDir, "browsers", id)
+	}
+	if config.LogDir != "" {
+		config.LogDir = filepath.Join(config.LogDir, "browsers", id)
+	}
+	return config, nil
+}
@@ -0,0 +1,357 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	chromeLogName    = "chrome.log"
+	chromeLogMaxSize = 10 << 20
+
+	// A browser that ran this long before exiting is not crash looping,
+	// so its exit does not add to the restart backoff.
+	stableUptime      = time.Minute
+	minRestartBackoff = 500 * time.Millisecond
+	maxRestartBackoff = 10 * time.Second
+	stopTimeout       = 5 * time.Second
+)
+
+// binaryNames are looked up on PATH in this order.
+var binaryNames = []string{
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"chrome",
+	"chrome-headless-shell",
+}
+
+var binaryLocations = map[string][]string{
+	"windows": {
+		`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files\Chromium\Application\chrome.exe`,
+	},
+	"darwin": {
+		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		"/Applications/Chromium.app/Contents/MacOS/Chromium",
+	},
+	"linux": {
+		"/usr/bin/chromium",
+		"/usr/bin/chromium-browser",
+		"/usr/bin/google-chrome",
+		"/usr/bin/google-chrome-stable",
+		"/snap/bin/chromium",
+		"/opt/google/chrome/chrome",
+		"/opt/chromium/chrome",
+	},
+}
+
+// playwrightGlobs find browsers installed by `playwright install`, which
+// is how many images ship Chromium.
+var playwrightGlobs = []string{
+	"chromium-*/chrome-linux/chrome",
+	"chromium_headless_shell-*/chrome-linux/headless_shell",
+}
+
+// chromeProcess is a browser started by this service. exited is closed
+// once it has been waited for.
+type chromeProcess struct {
+	cmd       *exec.Cmd
+	binary    string
+	logPath   string
+	startedAt time.Time
+	stopping  bool
+	exited    chan struct{}
+}
+
+type processStatus struct {
+	version            string
+	connectedAt        time.Time
+	launches           int
+	crashes            int
+	consecutiveCrashes int
+	lastCrash          time.Time
+	lastExit           string
+}
+
+// BrowserStatus describes the browser process. PID, Binary and LogPath are
+// empty when connected to an existing browser.
+type BrowserStatus struct {
+	Running       bool       `json:"running"`
+	Remote        bool       `json:"remote"`
+	PID           int        `json:"pid,omitempty"`
+	Binary        string     `json:"binary,omitempty"`
+	Version       string     `json:"version,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	UptimeSeconds float64    `json:"uptime_seconds"`
+	Restarts      int        `json:"restarts"`
+	Crashes       int        `json:"crashes"`
+	LastExit      string     `json:"last_exit,omitempty"`
+	LogPath       string     `json:"log_path,omitempty"`
+}
+
+// DetectBinary returns the first Chrome or Chromium found on PATH, in a
+// standard install location or in the Playwright cache, or "" if there is
+// none.
+func DetectBinary() string {
+	for _, name := range binaryNames {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	for _, candidate := range binaryLocations[goruntime.GOOS] {
+		if isExecutable(candidate) {
+			return candidate
+		}
+	}
+	var roots []string
+	if value := os.Getenv("PLAYWRIGHT_BROWSERS_PATH"); value != "" {
+		roots = append(roots, value)
+	}
+	if cache, err := os.UserCacheDir(); err == nil {
+		roots = append(roots, filepath.Join(cache, "ms-playwright"))
+	}
+	for _, root := range roots {
+		for _, pattern := range playwrightGlobs {
+			// Glob sorts its matches, so the newest revision comes last.
+			matches, _ := filepath.Glob(filepath.Join(root, pattern))
+			for i := len(matches) - 1; i >= 0; i-- {
+				if isExecutable(matches[i]) {
+					return matches[i]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return goruntime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// Status reports the browser process without starting it.
+func (service *Service) Status() BrowserStatus {
+	service.processMu.Lock()
+	defer service.processMu.Unlock()
+	status := BrowserStatus{
+		Remote:   service.config.ExistingWebSocketDebug != "",
+		Version:  service.status.version,
+		Crashes:  service.status.crashes,
+		LastExit: service.status.lastExit,
+	}
+	if service.status.launches > 1 {
+		status.Restarts = service.status.launches - 1
+	}
+	connected := !service.status.connectedAt.IsZero()
+	startedAt := service.status.connectedAt
+	if proc := service.process; proc != nil {
+		status.PID = proc.cmd.Process.Pid
+		status.Binary = proc.binary
+		status.LogPath = proc.logPath
+		startedAt = proc.startedAt
+		select {
+		case <-proc.exited:
+			connected = false
+		default:
+		}
+	}
+	if connected {
+		status.Running = true
+		status.StartedAt = &startedAt
+		status.UptimeSeconds = time.Since(startedAt).Seconds()
+	}
+	return status
+}
+
+func (service *Service) startChromeProcess(binary string, userDataDir string) error {
+	args := []string{
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-background-networking",
+		"--disable-client-side-phishing-detection",
+		"--disable-component-update",
+		"--disable-default-apps",
+		"--disable-sync",
+		"--disable-translate",
+		"--disable-popup-blocking",
+		"--remote-debugging-address=" + service.config.RemoteDebuggingHost,
+		"--remote-debugging-port=" + fmt.Sprintf("%d", service.config.RemoteDebuggingPort),
+		"--user-data-dir=" + userDataDir,
+		"--disable-gpu",
+	}
+	if service.config.Headless {
+		args = append(args, "--headless=new")
+	}
+	// Chrome refuses to run as root without this, which is the norm in
+	// containers.
+	if goruntime.GOOS == "linux" && os.Geteuid() == 0 {
+		args = append(args, "--no-sandbox")
+	}
+	args = append(args, service.config.ExtraArgs...)
+	args = append(args, "about:blank")
+
+	service.waitRestartBackoff()
+
+	cmd := exec.Command(binary, args...)
+	var logFile *os.File
+	logPath := ""
+	if service.config.LogDir != "" {
+		var err error
+		logPath = filepath.Join(service.config.LogDir, chromeLogName)
+		if logFile, err = openChromeLog(logPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(logFile, "=== %s starting %s\n", time.Now().Format(time.RFC3339), binary)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	}
+	if err := cmd.Start(); err != nil {
+		if logFile != nil {
+			logFile.Close()
+		}
+		return err
+	}
+
+	proc := &chromeProcess{
+		cmd:       cmd,
+		binary:    binary,
+		logPath:   logPath,
+		startedAt: time.Now(),
+		exited:    make(chan struct{}),
+	}
+	service.processMu.Lock()
+	service.process = proc
+	service.status.launches++
+	service.processMu.Unlock()
+	go service.watchChromeProcess(proc, logFile)
+	return nil
+}
+
+// openChromeLog appends to the log, moving it aside first once it grows
+// past chromeLogMaxSize.
+func openChromeLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > chromeLogMaxSize {
+		_ = os.Rename(path, path+".1")
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// watchChromeProcess waits for the browser to exit. An exit nobody asked
+// for is a crash; the next tab action sees it and restarts the browser.
+func (service *Service) watchChromeProcess(proc *chromeProcess, logFile *os.File) {
+	err := proc.cmd.Wait()
+	exit := "exited"
+	if err != nil {
+		exit = err.Error()
+	}
+
+	service.processMu.Lock()
+	crashed := !proc.stopping
+	if crashed {
+		if time.Since(proc.startedAt) < stableUptime {
+			service.status.consecutiveCrashes++
+		} else {
+			service.status.consecutiveCrashes = 1
+		}
+		service.status.crashes++
+		service.status.lastCrash = time.Now()
+		exit = "crashed: " + exit
+	}
+	service.status.lastExit = exit
+	service.processMu.Unlock()
+
+	if logFile != nil {
+		fmt.Fprintf(logFile, "=== %s %s\n", time.Now().Format(time.RFC3339), exit)
+		logFile.Close()
+	}
+	close(proc.exited)
+}
+
+// waitRestartBackoff delays a restart after consecutive crashes,
+// doubling from minRestartBackoff up to maxRestartBackoff.
+func (service *Service) waitRestartBackoff() {
+	service.processMu.Lock()
+	crashes := service.status.consecutiveCrashes
+	lastCrash := service.status.lastCrash
+	service.processMu.Unlock()
+	if crashes == 0 {
+		return
+	}
+	backoff := maxRestartBackoff
+	if crashes < 6 {
+		backoff = min(minRestartBackoff<<(crashes-1), maxRestartBackoff)
+	}
+	if wait := time.Until(lastCrash.Add(backoff)); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (service *Service) processExited() bool {
+	service.processMu.Lock()
+	proc := service.process
+	service.processMu.Unlock()
+	if proc == nil {
+		return false
+	}
+	select {
+	case <-proc.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+func (service *Service) stopChromeProcess() {
+	service.processMu.Lock()
+	proc := service.process
+	service.process = nil
+	if proc != nil {
+		proc.stopping = true
+	}
+	service.processMu.Unlock()
+	if proc == nil {
+		return
+	}
+	_ = proc.cmd.Process.Kill()
+	select {
+	case <-proc.exited:
+	case <-time.After(stopTimeout):
+	}
+}
+
+// recordConnected notes the version of the browser just connected to.
+func (service *Service) recordConnected(ctx context.Context) {
+	var product string
+	_ = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		_, product, _, _, _, err = browser.GetVersion().Do(ctx)
+		return err
+	}))
+	service.processMu.Lock()
+	service.status.version = product
+	service.status.connectedAt = time.Now()
+	service.processMu.Unlock()
+}
+
+func (service *Service) recordDisconnected() {
+	service.processMu.Lock()
+	service.status.connectedAt = time.Time{}
+	service.processMu.Unlock()
+}
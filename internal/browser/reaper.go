@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"sync"
+	"time"
+)
+
+// idleReaper runs sweep in the background whenever the earliest deadline
+// it last reported passes, so idle contexts and browsers are closed even
+// when nobody looks them up. It stops once sweep reports nothing left to
+// expire; poke starts it again or makes it pick up a sooner deadline.
+type idleReaper struct {
+	mu      sync.Mutex
+	running bool
+	wake    chan struct{}
+	sweep   func() (time.Time, bool)
+}
+
+func newIdleReaper(sweep func() (time.Time, bool)) *idleReaper {
+	return &idleReaper{wake: make(chan struct{}, 1), sweep: sweep}
+}
+
+func (reaper *idleReaper) poke() {
+	reaper.mu.Lock()
+	defer reaper.mu.Unlock()
+	if !reaper.running {
+		reaper.running = true
+		go reaper.run()
+		return
+	}
+	select {
+	case reaper.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (reaper *idleReaper) run() {
+	for {
+		next, ok := reaper.sweep()
+		if !ok {
+			reaper.mu.Lock()
+			select {
+			case <-reaper.wake:
+				// Poked after the sweep, so there may be something new.
+				reaper.mu.Unlock()
+				continue
+			default:
+			}
+			reaper.running = false
+			reaper.mu.Unlock()
+			return
+		}
+		// Expiry is checked with a strict comparison, so wake just after it.
+		timer := time.NewTimer(time.Until(next) + time.Millisecond)
+		select {
+		case <-timer.C:
+		case <-reaper.wake:
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	RecordingFormatWebM   = "webm"
+	RecordingFormatMP4    = "mp4"
+	RecordingFormatGIF    = "gif"
+	RecordingFormatFrames = "frames"
+
+	RecordingStateRecording = "recording"
+	RecordingStateFinished  = "finished"
+	RecordingStateFailed    = "failed"
+
+	defaultRecordingQuality = 80
+	// minFrameDuration keeps frames that arrive in bursts visible for at
+	// least one GIF tick.
+	minFrameDuration = 10 * time.Millisecond
+
+	// The gif fallback holds every frame in memory until it is encoded, so
+	// frames are sampled and scaled down, and recordings that would still
+	// need more than gifMaxFrames are kept as a frame sequence instead.
+	gifFrameInterval = 200 * time.Millisecond
+	gifMaxFrames     = 300
+	gifMaxWidth      = 640
+)
+
+// RecordingOptions configures StartRecording. Path defaults to a file
+// under Config.RecordingDir. Formats that need ffmpeg fall back to gif
+// when it is missing; the frames format keeps the JPEG frames in a
+// directory with a frames.json index.
+type RecordingOptions struct {
+	Path      string
+	Format    string
+	Tab       TabRef
+	Quality   int
+	MaxWidth  int
+	MaxHeight int
+}
+
+type RecordingInfo struct {
+	ID         string    `json:"id"`
+	Context    string    `json:"context"`
+	TabID      string    `json:"tab_id"`
+	Format     string    `json:"format"`
+	Path       string    `json:"path"`
+	State      string    `json:"state"`
+	Frames     int       `json:"frames"`
+	DurationMS int64     `json:"duration_ms"`
+	Bytes      int64     `json:"bytes,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+type recordedFrame struct {
+	File      string  `json:"file"`
+	Timestamp float64 `json:"timestamp"`
+}
+
+type recording struct {
+	mu       sync.Mutex
+	info     RecordingInfo
+	dir      string
+	frames   []recordedFrame
+	stopped  bool
+	tabCtx   context.Context
+	tabState *tabState
+}
+
+func (options *RecordingOptions) validate() error {
+	switch options.Format {
+	case "":
+		options.Format = RecordingFormatWebM
+	case RecordingFormatWebM, RecordingFormatMP4, RecordingFormatGIF, RecordingFormatFrames:
+	default:
+		return fmt.Errorf("%w: format must be webm, mp4, gif or frames", ErrInvalidOption)
+	}
+	if options.Quality < 0 || options.Quality > 100 {
+		return fmt.Errorf("%w: quality must be between 0 and 100", ErrInvalidOption)
+	}
+	if options.Quality == 0 {
+		options.Quality = defaultRecordingQuality
+	}
+	if options.MaxWidth < 0 || options.MaxHeight < 0 {
+		return fmt.Errorf("%w: max_width and max_height must be positive", ErrInvalidOption)
+	}
+	return nil
+}
+
+// StartRecording starts a screencast of a tab, the active one by default.
+// A context records one tab at a time.
+func (service *Service) StartRecording(options RecordingOptions) (*RecordingInfo, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	service.recordingsMu.Lock()
+	defer service.recordingsMu.Unlock()
+	if service.activeRecording != nil {
+		return nil, fmt.Errorf("%w: a recording is already running", ErrInvalidOption)
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return nil, err
+	}
+	index, err := service.tabIndexLocked(options.Tab)
+	if err != nil {
+		return nil, err
+	}
+	handle := service.tabs[index]
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return nil, errors.New("tab unavailable")
+	}
+
+	service.recordingSeq++
+	id := fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), service.recordingSeq)
+	path := options.Path
+	if path == "" {
+		path = filepath.Join(service.recordingDir(), id+recordingExtension(options.Format))
+	}
+	rec := &recording{
+		info: RecordingInfo{
+			ID:        id,
+			Context:   service.name,
+			TabID:     string(handle.targetID),
+			Format:    options.Format,
+			Path:      path,
+			State:     RecordingStateRecording,
+			StartedAt: time.Now(),
+		},
+		tabCtx:   handle.ctx,
+		tabState: handle.state,
+	}
+	if options.Format == RecordingFormatFrames {
+		rec.dir = path
+		err = os.MkdirAll(path, 0755)
+	} else {
+		rec.dir, err = stageRecording(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	handle.state.setRecorder(rec)
+	screencast := page.StartScreencast().
+		WithFormat(page.ScreencastFormatJpeg).
+		WithQuality(int64(options.Quality))
+	if options.MaxWidth > 0 {
+		screencast = screencast.WithMaxWidth(int64(options.MaxWidth))
+	}
+	if options.MaxHeight > 0 {
+		screencast = screencast.WithMaxHeight(int64(options.MaxHeight))
+	}
+	if err := runWithTimeout(handle.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, screencast)
+	}); err != nil {
+		handle.state.setRecorder(nil)
+		if options.Format != RecordingFormatFrames {
+			_ = os.RemoveAll(rec.dir)
+		}
+		return nil, err
+	}
+
+	service.activeRecording = rec
+	service.recordings = append(service.recordings, rec)
+	info := rec.info
+	return &info, nil
+}
+
+// StopRecording ends the running screencast and encodes it. Encoding
+// failures leave the frames in place and are reported in the result.
+func (service *Service) StopRecording() (*RecordingInfo, error) {
+	service.recordingsMu.Lock()
+	rec := service.activeRecording
+	service.activeRecording = nil
+	service.recordingsMu.Unlock()
+	if rec == nil {
+		return nil, fmt.Errorf("%w: no recording is running", ErrInvalidOption)
+	}
+
+	// The tab may already be gone, in which case the frames so far are kept.
+	if rec.tabCtx.Err() == nil {
+		_ = runWithTimeout(rec.tabCtx, service.config.NavigateTimeout, func(ctx context.Context) error {
+			return chromedp.Run(ctx, page.StopScreencast())
+		})
+	}
+	rec.tabState.setRecorder(nil)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.stopped = true
+	finished := time.Now()
+	rec.info.FinishedAt = finished
+	rec.info.DurationMS = finished.Sub(rec.info.StartedAt).Milliseconds()
+	rec.info.Frames = len(rec.frames)
+	sort.Slice(rec.frames, func(i, j int) bool { return rec.frames[i].Timestamp < rec.frames[j].Timestamp })
+
+	if err := rec.encodeLocked(service.config.FFmpegPath, finished); err != nil {
+		rec.info.State = RecordingStateFailed
+		rec.info.Error = err.Error()
+	} else {
+		rec.info.State = RecordingStateFinished
+		if stat, err := os.Stat(rec.info.Path); err == nil && !stat.IsDir() {
+			rec.info.Bytes = stat.Size()
+		}
+	}
+	info := rec.info
+	return &info, nil
+}
+
+// Recordings lists the recordings of this context, oldest first.
+func (service *Service) Recordings() []RecordingInfo {
+	service.recordingsMu.Lock()
+	defer service.recordingsMu.Unlock()
+	results := make([]RecordingInfo, 0, len(service.recordings))
+	for _, rec := range service.recordings {
+		rec.mu.Lock()
+		info := rec.info
+		info.Frames = len(rec.frames)
+		rec.mu.Unlock()
+		results = append(results, info)
+	}
+	return results
+}
+
+func (service *Service) recordingDir() string {
+	dir := service.config.RecordingDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "open-sandbox-recordings")
+	}
+	if service != service.main {
+		dir = filepath.Join(dir, "contexts", service.name)
+	}
+	return dir
+}
+
+// stageRecording creates the hidden directory the frames are captured in
+// until they are encoded. It sits next to path so keeping the frames is a
+// rename on the same filesystem.
+func stageRecording(path string) (string, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, "."+filepath.Base(path)+"-")
+}
+
+func recordingExtension(format string) string {
+	if format == RecordingFormatFrames {
+		return ""
+	}
+	return "." + format
+}
+
+func (state *tabState) setRecorder(rec *recording) {
+	state.recorderMu.Lock()
+	state.recorder = rec
+	state.recorderMu.Unlock()
+}
+
+// handleScreencastFrame acknowledges a frame so Chrome sends the next one
+// and stores it with the tab's recording.
+func (state *tabState) handleScreencastFrame(ctx context.Context, e *page.EventScreencastFrame) {
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+		_ = page.ScreencastFrameAck(e.SessionID).Do(cdp.WithExecutor(ctx, c.Target))
+	}
+	state.recorderMu.Lock()
+	rec := state.recorder
+	state.recorderMu.Unlock()
+	if rec != nil {
+		rec.addFrame(e)
+	}
+}
+
+func (rec *recording) addFrame(e *page.EventScreencastFrame) {
+	data, err := base64.StdEncoding.DecodeString(e.Data)
+	if err != nil {
+		return
+	}
+	timestamp := float64(time.Now().UnixNano()) / float64(time.Second)
+	if e.Metadata != nil && e.Metadata.Timestamp != nil {
+		timestamp = float64(e.Metadata.Timestamp.Time().UnixNano()) / float64(time.Second)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.stopped {
+		return
+	}
+	name := fmt.Sprintf("%06d.jpg", len(rec.frames)+1)
+	if err := os.WriteFile(filepath.Join(rec.dir, name), data, 0644); err != nil {
+		return
+	}
+	rec.frames = append(rec.frames, recordedFrame{File: name, Timestamp: timestamp})
+}
+
+// frameDurations returns how long each frame stays on screen; the last one
+// lasts until the recording stopped.
+func (rec *recording) frameDurations(stopped time.Time) []time.Duration {
+	durations := make([]time.Duration, len(rec.frames))
+	end := float64(stopped.UnixNano()) / float64(time.Second)
+	for i, frame := range rec.frames {
+		next := end
+		if i+1 < len(rec.frames) {
+			next = rec.frames[i+1].Timestamp
+		}
+		durations[i] = max(time.Duration((next-frame.Timestamp)*float64(time.Second)), minFrameDuration)
+	}
+	return durations
+}
+
+func (rec *recording) encodeLocked(ffmpegPath string, stopped time.Time) error {
+	if rec.info.Format == RecordingFormatFrames {
+		return rec.writeFrameIndexLocked()
+	}
+	if len(rec.frames) == 0 {
+		_ = os.RemoveAll(rec.dir)
+		return errors.New("no frames were captured")
+	}
+	if rec.info.Format != RecordingFormatGIF {
+		if ffmpegPath == "" {
+			ffmpegPath, _ = exec.LookPath("ffmpeg")
+		}
+		if ffmpegPath == "" {
+			rec.info.Format = RecordingFormatGIF
+			rec.info.Path = strings.TrimSuffix(rec.info.Path, filepath.Ext(rec.info.Path)) + ".gif"
+		}
+	}
+
+	var err error
+	if rec.info.Format == RecordingFormatGIF {
+		frames := rec.gifFramesLocked(stopped)
+		if len(frames) > gifMaxFrames {
+			return rec.keepFramesLocked()
+		}
+		err = rec.encodeGIFLocked(frames)
+	} else {
+		err = rec.encodeFFmpegLocked(ffmpegPath, stopped)
+	}
+	if err != nil {
+		_ = rec.keepFramesLocked()
+		return err
+	}
+	return os.RemoveAll(rec.dir)
+}
+
+// keepFramesLocked moves what was captured to a frame sequence next to the
+// target.
+func (rec *recording) keepFramesLocked() error {
+	frames := strings.TrimSuffix(rec.info.Path, filepath.Ext(rec.info.Path)) + ".frames"
+	if err := os.Rename(rec.dir, frames); err != nil {
+		return err
+	}
+	rec.dir = frames
+	rec.info.Format = RecordingFormatFrames
+	rec.info.Path = frames
+	return rec.writeFrameIndexLocked()
+}
+
+func (rec *recording) writeFrameIndexLocked() error {
+	data, err := json.MarshalIndent(rec.frames, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(rec.dir, "frames.json"), data, 0644)
+}
+
+func (rec *recording) encodeFFmpegLocked(ffmpegPath string, stopped time.Time) error {
+	var list strings.Builder
+	list.WriteString("ffconcat version 1.0\n")
+	durations := rec.frameDurations(stopped)
+	for i, frame := range rec.frames {
+		fmt.Fprintf(&list, "file '%s'\nduration %.3f\n", frame.File, durations[i].Seconds())
+	}
+	// The concat demuxer ignores the duration of the last entry.
+	fmt.Fprintf(&list, "file '%s'\n", rec.frames[len(rec.frames)-1].File)
+	listPath := filepath.Join(rec.dir, "frames.ffconcat")
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return err
+	}
+
+	args := []string{"-y", "-loglevel", "error", "-f", "concat", "-safe", "0", "-i", listPath,
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2,format=yuv420p", "-fps_mode", "vfr"}
+	if rec.info.Format == RecordingFormatMP4 {
+		args = append(args, "-c:v", "libx264", "-preset", "veryfast", "-movflags", "+faststart")
+	} else {
+		args = append(args, "-c:v", "libvpx-vp9", "-b:v", "0", "-crf", "32", "-deadline", "realtime")
+	}
+	args = append(args, rec.info.Path)
+	output, err := exec.Command(ffmpegPath, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type gifFrame struct {
+	file  string
+	delay time.Duration
+}
+
+// gifFramesLocked samples the captured frames to at most one per
+// gifFrameInterval; a dropped frame's time goes to the frame before it.
+func (rec *recording) gifFramesLocked(stopped time.Time) []gifFrame {
+	durations := rec.frameDurations(stopped)
+	interval := gifFrameInterval.Seconds()
+	var frames []gifFrame
+	var next float64
+	for i, frame := range rec.frames {
+		if len(frames) > 0 && frame.Timestamp < next {
+			frames[len(frames)-1].delay += durations[i]
+			continue
+		}
+		frames = append(frames, gifFrame{file: frame.File, delay: durations[i]})
+		next = frame.Timestamp + interval
+	}
+	return frames
+}
+
+func (rec *recording) encodeGIFLocked(frames []gifFrame) error {
+	animation := &gif.GIF{}
+	for _, frame := range frames {
+		data, err := os.ReadFile(filepath.Join(rec.dir, frame.file))
+		if err != nil {
+			return err
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		img = scaleToWidth(img, gifMaxWidth)
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.Draw(paletted, paletted.Rect, img, img.Bounds().Min, draw.Src)
+		animation.Image = append(animation.Image, paletted)
+		animation.Delay = append(animation.Delay, int(frame.delay/minFrameDuration))
+	}
+	out, err := os.Create(rec.info.Path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(out, animation); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// scaleToWidth shrinks img to at most width pixels across with
+// nearest-neighbour sampling.
+func scaleToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		return img
+	}
+	height := max(bounds.Dy()*width/bounds.Dx(), 1)
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		sy := bounds.Min.Y + y*bounds.Dy()/height
+		for x := range width {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, sy))
+		}
+	}
+	return scaled
+}
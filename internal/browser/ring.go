@@ -0,0 +1,95 @@
+package browser
+
+import "sync"
+
+// ringBuffer keeps the newest entries up to a fixed capacity and numbers
+// them with a monotonically increasing sequence so readers can resume from
+// a cursor.
+type ringBuffer[T any] struct {
+	mu       sync.Mutex
+	items    []ringItem[T]
+	capacity int
+	start    int
+	seq      uint64
+}
+
+type ringItem[T any] struct {
+	seq  uint64
+	item T
+}
+
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	return &ringBuffer[T]{capacity: capacity}
+}
+
+// push stores the value built for the next sequence number.
+func (ring *ringBuffer[T]) push(build func(seq uint64) T) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	ring.seq++
+	item := ringItem[T]{seq: ring.seq, item: build(ring.seq)}
+	if len(ring.items) < ring.capacity {
+		ring.items = append(ring.items, item)
+		return
+	}
+	ring.items[ring.start] = item
+	ring.start = (ring.start + 1) % ring.capacity
+}
+
+// update applies fn to the entry with seq in place and reports whether it
+// is still buffered.
+func (ring *ringBuffer[T]) update(seq uint64, fn func(item *T)) bool {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if len(ring.items) == 0 {
+		return false
+	}
+	oldest := ring.items[ring.start].seq
+	if seq < oldest || seq > ring.seq {
+		return false
+	}
+	fn(&ring.items[(ring.start+int(seq-oldest))%len(ring.items)].item)
+	return true
+}
+
+// oldest returns the sequence of the oldest buffered entry, or zero.
+func (ring *ringBuffer[T]) oldest() uint64 {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if len(ring.items) == 0 {
+		return 0
+	}
+	return ring.items[ring.start].seq
+}
+
+// since returns up to limit entries newer than cursor that pass keep, the
+// cursor to resume from, and whether entries after cursor were evicted
+// before they could be read. A cursor from a previous buffer (for example
+// before the browser restarted) reads from the beginning.
+func (ring *ringBuffer[T]) since(cursor uint64, limit int, keep func(T) bool) ([]T, uint64, bool) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if cursor > ring.seq {
+		cursor = 0
+	}
+	result := []T{}
+	next := cursor
+	dropped := false
+	if len(ring.items) > 0 && ring.items[ring.start].seq > cursor+1 {
+		dropped = true
+	}
+	for i := range ring.items {
+		entry := ring.items[(ring.start+i)%len(ring.items)]
+		if entry.seq <= cursor {
+			continue
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		next = entry.seq
+		if keep == nil || keep(entry.item) {
+			result = append(result, entry.item)
+		}
+	}
+	return result, next, dropped
+}
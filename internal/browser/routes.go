@@ -0,0 +1,321 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	RouteActionFulfill  = "fulfill"
+	RouteActionAbort    = "abort"
+	RouteActionContinue = "continue"
+
+	maxRouteBodyBytes = 10 << 20
+)
+
+var ErrRouteNotFound = errors.New("route not found")
+
+// RouteRule intercepts requests whose URL matches the URL glob. Fulfill
+// answers with Status, Headers and Body (or the contents of BodyFile);
+// abort fails the request with ErrorReason; continue sends it on with
+// Headers merged into the request headers and RemoveHeaders dropped.
+type RouteRule struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Method        string            `json:"method,omitempty"`
+	ResourceType  string            `json:"resource_type,omitempty"`
+	Action        string            `json:"action"`
+	Status        int               `json:"status,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	BodyFile      string            `json:"body_file,omitempty"`
+	ErrorReason   string            `json:"error_reason,omitempty"`
+	// Times limits how often the rule applies; zero means unlimited.
+	Times int `json:"times,omitempty"`
+	Hits  int `json:"hits"`
+
+	pattern *regexp.Regexp
+	body    []byte
+}
+
+// MatchURLGlob reports whether url matches pattern, where * matches any run
+// of characters (including /) and ? matches one character.
+func MatchURLGlob(pattern, url string) bool {
+	re, err := compileURLGlob(pattern)
+	return err == nil && re.MatchString(url)
+}
+
+func compileURLGlob(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+func (rule *RouteRule) prepare() error {
+	if rule.URL == "" {
+		return fmt.Errorf("%w: url is required", ErrInvalidOption)
+	}
+	pattern, err := compileURLGlob(rule.URL)
+	if err != nil {
+		return fmt.Errorf("%w: url: %v", ErrInvalidOption, err)
+	}
+	rule.pattern = pattern
+	rule.Method = strings.ToUpper(rule.Method)
+
+	switch rule.Action {
+	case "":
+		rule.Action = RouteActionFulfill
+		fallthrough
+	case RouteActionFulfill:
+		if rule.Status == 0 {
+			rule.Status = http.StatusOK
+		}
+		if rule.Status < 100 || rule.Status > 599 {
+			return fmt.Errorf("%w: invalid status %d", ErrInvalidOption, rule.Status)
+		}
+		rule.body = []byte(rule.Body)
+		if rule.BodyFile != "" {
+			info, err := os.Stat(rule.BodyFile)
+			if err != nil {
+				return fmt.Errorf("%w: body_file: %v", ErrInvalidOption, err)
+			}
+			if info.Size() > maxRouteBodyBytes {
+				return fmt.Errorf("%w: body_file exceeds %d bytes", ErrInvalidOption, maxRouteBodyBytes)
+			}
+			if rule.body, err = os.ReadFile(rule.BodyFile); err != nil {
+				return err
+			}
+		}
+	case RouteActionAbort:
+		if rule.ErrorReason == "" {
+			rule.ErrorReason = string(network.ErrorReasonBlockedByClient)
+		}
+		var reason network.ErrorReason
+		if err := reason.UnmarshalJSON([]byte(strconv.Quote(rule.ErrorReason))); err != nil {
+			return fmt.Errorf("%w: unknown error_reason %q", ErrInvalidOption, rule.ErrorReason)
+		}
+	case RouteActionContinue:
+	default:
+		return fmt.Errorf("%w: action must be fulfill, abort or continue", ErrInvalidOption)
+	}
+	return nil
+}
+
+func (rule *RouteRule) matches(request *network.Request, resourceType network.ResourceType) bool {
+	if rule.Times > 0 && rule.Hits >= rule.Times {
+		return false
+	}
+	if rule.Method != "" && rule.Method != request.Method {
+		return false
+	}
+	if rule.ResourceType != "" && !strings.EqualFold(rule.ResourceType, string(resourceType)) {
+		return false
+	}
+	return rule.pattern.MatchString(request.URL + request.URLFragment)
+}
+
+// AddRoute installs rule on every open tab and on tabs created later.
+// Rules are checked in the order they were added; the first match wins.
+func (service *Service) AddRoute(rule RouteRule) (RouteRule, error) {
+	if err := rule.prepare(); err != nil {
+		return RouteRule{}, err
+	}
+	service.routesMu.Lock()
+	service.routeSeq++
+	rule.ID = "route-" + strconv.Itoa(service.routeSeq)
+	rule.Hits = 0
+	service.routes = append(service.routes, &rule)
+	added := rule
+	service.routesMu.Unlock()
+
+	service.syncInterception()
+	return added, nil
+}
+
+func (service *Service) Routes() []RouteRule {
+	service.routesMu.Lock()
+	defer service.routesMu.Unlock()
+	rules := make([]RouteRule, 0, len(service.routes))
+	for _, rule := range service.routes {
+		rules = append(rules, *rule)
+	}
+	return rules
+}
+
+func (service *Service) RemoveRoute(id string) error {
+	service.routesMu.Lock()
+	index := -1
+	for i, rule := range service.routes {
+		if rule.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		service.routesMu.Unlock()
+		return ErrRouteNotFound
+	}
+	service.routes = append(service.routes[:index], service.routes[index+1:]...)
+	service.routesMu.Unlock()
+
+	service.syncInterception()
+	return nil
+}
+
+func (service *Service) ClearRoutes() {
+	service.routesMu.Lock()
+	service.routes = nil
+	service.routesMu.Unlock()
+	service.syncInterception()
+}
+
+func (service *Service) hasRoutes() bool {
+	service.routesMu.Lock()
+	defer service.routesMu.Unlock()
+	return len(service.routes) > 0
+}
+
+// syncInterception turns Fetch interception on or off for every open tab
+// in every context, so requests are only paused while there is a rule to
+// apply. It never starts the browser.
+func (service *Service) syncInterception() {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	enabled := service.hasRoutes()
+	for _, browserContext := range service.allContextsLocked() {
+		for _, tab := range browserContext.tabs {
+			if tab.ctx == nil || tab.ctx.Err() != nil {
+				continue
+			}
+			_ = runWithTimeout(tab.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+				return chromedp.Run(ctx, setInterception(enabled))
+			})
+		}
+	}
+}
+
+func setInterception(enabled bool) chromedp.Action {
+	if !enabled {
+		return fetch.Disable()
+	}
+	return fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*", RequestStage: fetch.RequestStageRequest}})
+}
+
+// handleRequestPaused answers a paused request. It runs outside the event
+// loop because CDP calls made from a listener would deadlock it.
+func (service *Service) handleRequestPaused(ctx context.Context, e *fetch.EventRequestPaused) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	ctx = cdp.WithExecutor(ctx, c.Target)
+
+	var rule *RouteRule
+	service.routesMu.Lock()
+	for _, candidate := range service.routes {
+		if candidate.matches(e.Request, e.ResourceType) {
+			candidate.Hits++
+			copied := *candidate
+			rule = &copied
+			break
+		}
+	}
+	service.routesMu.Unlock()
+
+	if rule == nil {
+		_ = fetch.ContinueRequest(e.RequestID).Do(ctx)
+		return
+	}
+	var err error
+	switch rule.Action {
+	case RouteActionFulfill:
+		err = fetch.FulfillRequest(e.RequestID, int64(rule.Status)).
+			WithResponseHeaders(fulfillHeaders(rule)).
+			WithBody(base64.StdEncoding.EncodeToString(rule.body)).
+			Do(ctx)
+	case RouteActionAbort:
+		err = fetch.FailRequest(e.RequestID, network.ErrorReason(rule.ErrorReason)).Do(ctx)
+	default:
+		err = fetch.ContinueRequest(e.RequestID).WithHeaders(continueHeaders(e.Request.Headers, rule)).Do(ctx)
+	}
+	if err != nil {
+		// Never leave the page hanging on a rule that could not be applied.
+		_ = fetch.ContinueRequest(e.RequestID).Do(ctx)
+	}
+}
+
+func fulfillHeaders(rule *RouteRule) []*fetch.HeaderEntry {
+	headers := make([]*fetch.HeaderEntry, 0, len(rule.Headers)+1)
+	hasContentType := false
+	for name, value := range rule.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			hasContentType = true
+		}
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+	}
+	if !hasContentType {
+		contentType := ""
+		if rule.BodyFile != "" {
+			contentType = mime.TypeByExtension(filepath.Ext(rule.BodyFile))
+		}
+		if contentType == "" && len(rule.body) > 0 {
+			contentType = http.DetectContentType(rule.body)
+		}
+		if contentType != "" {
+			headers = append(headers, &fetch.HeaderEntry{Name: "Content-Type", Value: contentType})
+		}
+	}
+	return headers
+}
+
+func continueHeaders(original network.Headers, rule *RouteRule) []*fetch.HeaderEntry {
+	merged := make(map[string]string, len(original)+len(rule.Headers))
+	canonical := make(map[string]string, len(original))
+	for name, value := range original {
+		merged[name] = fmt.Sprint(value)
+		canonical[strings.ToLower(name)] = name
+	}
+	for name, value := range rule.Headers {
+		if existing, ok := canonical[strings.ToLower(name)]; ok {
+			delete(merged, existing)
+		}
+		merged[name] = value
+		canonical[strings.ToLower(name)] = name
+	}
+	for _, name := range rule.RemoveHeaders {
+		if existing, ok := canonical[strings.ToLower(name)]; ok {
+			delete(merged, existing)
+		}
+	}
+	headers := make([]*fetch.HeaderEntry, 0, len(merged))
+	for name, value := range merged {
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+	}
+	return headers
+}
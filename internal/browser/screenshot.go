@@ -0,0 +1,206 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	ScreenshotFormatPNG  = "png"
+	ScreenshotFormatJPEG = "jpeg"
+	ScreenshotFormatWebP = "webp"
+
+	maxScreenshotScale = 4
+)
+
+// ScreenshotOptions selects what to capture: the viewport by default, the
+// whole page with FullPage, a rectangle in page coordinates with Clip, or
+// the element Element points at. Scale multiplies the device pixel ratio.
+// Marks numbers the visible interactive elements on the image.
+type ScreenshotOptions struct {
+	Path     string
+	Format   string
+	Quality  int
+	FullPage bool
+	Clip     *ClipRect
+	Element  Locator
+	Scale    float64
+	Marks    bool
+}
+
+type ClipRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type ScreenshotResult struct {
+	Path     string `json:"path,omitempty"`
+	Format   string `json:"format"`
+	MimeType string `json:"mime_type"`
+	Bytes    int    `json:"bytes"`
+	Marks    []Mark `json:"marks,omitempty"`
+	Data     []byte `json:"-"`
+}
+
+func (options *ScreenshotOptions) validate() error {
+	switch options.Format {
+	case "":
+		options.Format = ScreenshotFormatPNG
+	case "jpg":
+		options.Format = ScreenshotFormatJPEG
+	case ScreenshotFormatPNG, ScreenshotFormatJPEG, ScreenshotFormatWebP:
+	default:
+		return fmt.Errorf("%w: format must be png, jpeg or webp", ErrInvalidOption)
+	}
+	if options.Quality < 0 || options.Quality > 100 {
+		return fmt.Errorf("%w: quality must be between 0 and 100", ErrInvalidOption)
+	}
+	if options.Quality > 0 && options.Format == ScreenshotFormatPNG {
+		return fmt.Errorf("%w: quality only applies to jpeg and webp", ErrInvalidOption)
+	}
+	if options.Scale < 0 || options.Scale > maxScreenshotScale {
+		return fmt.Errorf("%w: scale must be between 0 and %d", ErrInvalidOption, maxScreenshotScale)
+	}
+	if options.Scale == 0 {
+		options.Scale = 1
+	}
+	targets := 0
+	if options.FullPage {
+		targets++
+	}
+	if options.Clip != nil {
+		targets++
+		if options.Clip.Width <= 0 || options.Clip.Height <= 0 {
+			return fmt.Errorf("%w: clip width and height must be positive", ErrInvalidOption)
+		}
+	}
+	if !options.Element.IsZero() {
+		targets++
+		if err := options.Element.validate(); err != nil {
+			return err
+		}
+	}
+	if targets > 1 {
+		return fmt.Errorf("%w: full_page, clip and element are mutually exclusive", ErrInvalidOption)
+	}
+	if options.Marks && !options.Element.IsZero() {
+		return fmt.Errorf("%w: marks cannot be combined with element", ErrInvalidOption)
+	}
+	return nil
+}
+
+func (options ScreenshotOptions) mimeType() string {
+	return "image/" + options.Format
+}
+
+// Screenshot writes a PNG of the viewport to path.
+func (service *Service) Screenshot(path string) error {
+	_, err := service.CaptureScreenshot(ScreenshotOptions{Path: path})
+	return err
+}
+
+// ScreenshotPNG returns a PNG of the viewport.
+func (service *Service) ScreenshotPNG() ([]byte, error) {
+	result, err := service.CaptureScreenshot(ScreenshotOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// CaptureScreenshot captures the active tab and also writes the image to
+// options.Path when it is set.
+func (service *Service) CaptureScreenshot(options ScreenshotOptions) (*ScreenshotResult, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	var data []byte
+	var marks []Mark
+	var err error
+	if options.Element.IsZero() {
+		err = service.runTabAction(service.config.ScreenshotTimeout, func(ctx context.Context) error {
+			return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+				var err error
+				if options.Marks {
+					if marks, err = service.markElements(ctx, options.FullPage); err != nil {
+						return err
+					}
+					defer func() {
+						_ = clearMarks(ctx)
+					}()
+				}
+				data, err = captureScreenshot(ctx, options)
+				return err
+			}))
+		})
+	} else {
+		err = service.withElement(options.Element, false, func(ctx context.Context, element runtime.RemoteObjectID) error {
+			var rect ClipRect
+			if err := callElementInto(ctx, element, elementPageRectJS, &rect); err != nil {
+				return err
+			}
+			options.Clip = &rect
+			data, err = captureScreenshot(ctx, options)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScreenshotResult{Format: options.Format, MimeType: options.mimeType(), Bytes: len(data), Marks: marks, Data: data}
+	if options.Marks && result.Marks == nil {
+		result.Marks = []Mark{}
+	}
+	if options.Path != "" {
+		if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(options.Path, data, 0644); err != nil {
+			return nil, err
+		}
+		result.Path = options.Path
+	}
+	return result, nil
+}
+
+func captureScreenshot(ctx context.Context, options ScreenshotOptions) ([]byte, error) {
+	capture := page.CaptureScreenshot().WithFormat(page.CaptureScreenshotFormat(options.Format))
+	if options.Quality > 0 {
+		capture = capture.WithQuality(int64(options.Quality))
+	}
+
+	clip := options.Clip
+	if options.FullPage || (clip == nil && options.Scale != 1) {
+		_, _, _, _, visual, content, err := page.GetLayoutMetrics().Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if options.FullPage {
+			clip = &ClipRect{Width: content.Width, Height: content.Height}
+		} else {
+			clip = &ClipRect{X: visual.PageX, Y: visual.PageY, Width: visual.ClientWidth, Height: visual.ClientHeight}
+		}
+	}
+	if clip != nil {
+		// Areas outside the viewport are only painted when asked for.
+		capture = capture.
+			WithClip(&page.Viewport{X: clip.X, Y: clip.Y, Width: clip.Width, Height: clip.Height, Scale: options.Scale}).
+			WithCaptureBeyondViewport(true)
+	}
+	return capture.Do(ctx)
+}
+
+const elementPageRectJS = `function() {
+  this.scrollIntoView({ block: 'center', inline: 'center', behavior: 'instant' });
+  const rect = this.getBoundingClientRect();
+  return { x: rect.left + window.scrollX, y: rect.top + window.scrollY, width: rect.width, height: rect.height };
+}`
@@ -0,0 +1,804 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	ScriptRunPassed = "passed"
+	ScriptRunFailed = "failed"
+
+	ScriptSourceAgent = "agent"
+	ScriptSourceHuman = "human"
+
+	scriptBinding = "__sandboxScriptRecord"
+	// agentQuietPeriod drops page events that an agent action caused but
+	// that arrive just after it returned, so they are not recorded twice.
+	agentQuietPeriod = 300 * time.Millisecond
+)
+
+var ErrScriptNotFound = errors.New("script not found")
+
+// scriptBindingFields lists the fields kept for each action type that
+// scriptRecorderJS reports. The first one is required.
+var scriptBindingFields = map[string][]string{
+	"CLICK":   {"selector"},
+	"TYPING":  {"selector", "text", "clear"},
+	"SELECT":  {"selector", "value"},
+	"CHECK":   {"selector"},
+	"UNCHECK": {"selector"},
+	"PRESS":   {"key"},
+}
+
+var scriptVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Script is a saved sequence of /v1/browser/actions steps. String values
+// of steps may reference {{variables}}; Variables holds their defaults and
+// variables without one must be passed to every run.
+type Script struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Steps       []ScriptStep      `json:"steps"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// ScriptStep is one action, checked by Assert once it has run. Source
+// tells recorded agent actions from human input.
+type ScriptStep struct {
+	Action json.RawMessage   `json:"action"`
+	Assert []ScriptAssertion `json:"assert,omitempty"`
+	Source string            `json:"source,omitempty"`
+}
+
+// ScriptAssertion checks that an element exists, or with Exists false that
+// it does not, or that its text or value contains TextContains. It waits
+// up to the locator's timeout for that to hold.
+type ScriptAssertion struct {
+	Locator
+	Exists       *bool  `json:"exists,omitempty"`
+	TextContains string `json:"text_contains,omitempty"`
+}
+
+type ScriptInfo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Steps       int       `json:"steps"`
+	Variables   []string  `json:"variables"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ScriptRecordOptions struct {
+	Name        string
+	Description string
+}
+
+type ScriptRunOptions struct {
+	Variables map[string]string
+}
+
+// ActionExecutor performs one step in the /v1/browser/actions format and
+// returns the action type it performed.
+type ActionExecutor func(service *Service, action json.RawMessage) (string, error)
+
+type ScriptRun struct {
+	Script     string             `json:"script"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Steps      []ScriptStepResult `json:"steps"`
+	StartedAt  time.Time          `json:"started_at"`
+	DurationMS int64              `json:"duration_ms"`
+}
+
+// ScriptStepResult reports one step of a run. Screenshot is the page at
+// the time the step failed.
+type ScriptStepResult struct {
+	Index      int    `json:"index"`
+	Action     string `json:"action"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Screenshot string `json:"screenshot,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type scriptRecorder struct {
+	mu         sync.Mutex
+	script     Script
+	agentBusy  int
+	quietUntil time.Time
+	secrets    int
+}
+
+func (script *Script) validate() error {
+	if !contextNamePattern.MatchString(script.Name) {
+		return fmt.Errorf("%w: name must be 1-64 letters, digits, '.', '_' or '-'", ErrInvalidOption)
+	}
+	for i, step := range script.Steps {
+		var envelope struct {
+			ActionType string `json:"action_type"`
+		}
+		if err := json.Unmarshal(step.Action, &envelope); err != nil || envelope.ActionType == "" {
+			return fmt.Errorf("%w: step %d needs an action with an action_type", ErrInvalidOption, i)
+		}
+		if err := checkScriptAction(step.Action); err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		for _, assertion := range step.Assert {
+			if err := assertion.validate(); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkScriptAction rejects actions that locate elements by ref or
+// element, for the same reason as assertions.
+func checkScriptAction(action json.RawMessage) error {
+	var locator struct {
+		Ref     any `json:"ref"`
+		Element any `json:"element"`
+	}
+	if err := json.Unmarshal(action, &locator); err != nil {
+		return nil
+	}
+	for _, value := range []any{locator.Ref, locator.Element} {
+		if value != nil && value != "" && value != float64(0) {
+			return fmt.Errorf("%w: scripts cannot use ref or element, locate the element by selector", ErrInvalidLocator)
+		}
+	}
+	return nil
+}
+
+// Refs and marks point at nodes of one page load, so scripts locate
+// elements by selector, xpath, text or role only.
+func (assertion ScriptAssertion) validate() error {
+	if assertion.Ref != "" || assertion.Element != 0 {
+		return fmt.Errorf("%w: assertions cannot use ref or element", ErrInvalidLocator)
+	}
+	return assertion.Locator.validate()
+}
+
+// Expand returns the steps with every {{name}} replaced by variables[name]
+// or else the script's default. It fails when a variable has neither.
+func (script *Script) Expand(variables map[string]string) ([]ScriptStep, error) {
+	missing := map[string]bool{}
+	lookup := func(name string) (string, bool) {
+		if value, ok := variables[name]; ok {
+			return value, true
+		}
+		value, ok := script.Variables[name]
+		return value, ok
+	}
+	steps := make([]ScriptStep, 0, len(script.Steps))
+	for i, step := range script.Steps {
+		data, err := json.Marshal(step)
+		if err != nil {
+			return nil, err
+		}
+		var tree any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+		tree = mapStrings(tree, func(value string) string {
+			return scriptVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+				name := scriptVariablePattern.FindStringSubmatch(match)[1]
+				replacement, ok := lookup(name)
+				if !ok {
+					missing[name] = true
+				}
+				return replacement
+			})
+		})
+		if data, err = json.Marshal(tree); err != nil {
+			return nil, err
+		}
+		var expanded ScriptStep
+		if err := json.Unmarshal(data, &expanded); err != nil {
+			return nil, fmt.Errorf("%w: step %d: %v", ErrInvalidOption, i, err)
+		}
+		steps = append(steps, expanded)
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: missing variables: %s", ErrInvalidOption, strings.Join(names, ", "))
+	}
+	return steps, nil
+}
+
+// VariableNames lists the variables the steps reference.
+func (script *Script) VariableNames() []string {
+	seen := map[string]bool{}
+	for _, step := range script.Steps {
+		data, err := json.Marshal(step)
+		if err != nil {
+			continue
+		}
+		for _, match := range scriptVariablePattern.FindAllStringSubmatch(string(data), -1) {
+			seen[match[1]] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mapStrings applies fn to every string value of a decoded JSON tree.
+func mapStrings(value any, fn func(string) string) any {
+	switch typed := value.(type) {
+	case string:
+		return fn(typed)
+	case []any:
+		for i := range typed {
+			typed[i] = mapStrings(typed[i], fn)
+		}
+	case map[string]any:
+		for key := range typed {
+			typed[key] = mapStrings(typed[key], fn)
+		}
+	}
+	return value
+}
+
+// SaveScript writes script to Config.ScriptDir, replacing one of the same
+// name.
+func (service *Service) SaveScript(script *Script) error {
+	if err := script.validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	if script.CreatedAt.IsZero() {
+		script.CreatedAt = now
+	}
+	script.UpdatedAt = now
+	if script.Steps == nil {
+		script.Steps = []ScriptStep{}
+	}
+	data, err := json.MarshalIndent(script, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := service.scriptDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// Write then rename so a run never reads half a script.
+	tmp, err := os.CreateTemp(dir, "."+script.Name+"-*.json")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, script.Name+".json"))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (service *Service) LoadScript(name string) (*Script, error) {
+	if !contextNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrScriptNotFound, name)
+	}
+	data, err := os.ReadFile(filepath.Join(service.scriptDir(), name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrScriptNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidOption, name, err)
+	}
+	script.Name = name
+	return &script, nil
+}
+
+// Scripts lists the saved scripts by name, skipping files that do not
+// parse.
+func (service *Service) Scripts() ([]ScriptInfo, error) {
+	entries, err := os.ReadDir(service.scriptDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []ScriptInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	infos := []ScriptInfo{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !contextNamePattern.MatchString(name) {
+			continue
+		}
+		script, err := service.LoadScript(name)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, ScriptInfo{
+			Name:        script.Name,
+			Description: script.Description,
+			Steps:       len(script.Steps),
+			Variables:   script.VariableNames(),
+			UpdatedAt:   script.UpdatedAt,
+		})
+	}
+	return infos, nil
+}
+
+func (service *Service) DeleteScript(name string) error {
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrScriptNotFound, name)
+	}
+	err := os.Remove(filepath.Join(service.scriptDir(), name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %q", ErrScriptNotFound, name)
+	}
+	return err
+}
+
+// Scripts are shared by every context and browser, unlike recordings.
+func (service *Service) scriptDir() string {
+	if service.config.ScriptDir != "" {
+		return service.config.ScriptDir
+	}
+	return filepath.Join(os.TempDir(), "open-sandbox-scripts")
+}
+
+// RunScript replays a saved script on the active tab, stopping at the
+// first step whose action or assertions fail. A failed run is reported in
+// the result rather than as an error.
+func (service *Service) RunScript(name string, options ScriptRunOptions, execute ActionExecutor) (*ScriptRun, error) {
+	script, err := service.LoadScript(name)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := script.Expand(options.Variables)
+	if err != nil {
+		return nil, err
+	}
+	run := &ScriptRun{Script: name, Status: ScriptRunPassed, Steps: []ScriptStepResult{}, StartedAt: time.Now()}
+	for i, step := range steps {
+		started := time.Now()
+		result := ScriptStepResult{Index: i, Status: ScriptRunPassed}
+		action, err := execute(service, step.Action)
+		result.Action = action
+		if result.Action == "" {
+			var envelope struct {
+				ActionType string `json:"action_type"`
+			}
+			_ = json.Unmarshal(step.Action, &envelope)
+			result.Action = envelope.ActionType
+		}
+		for j := 0; err == nil && j < len(step.Assert); j++ {
+			if err = service.CheckAssertion(step.Assert[j]); err != nil {
+				err = fmt.Errorf("assertion %d: %w", j, err)
+			}
+		}
+		result.DurationMS = time.Since(started).Milliseconds()
+		if err != nil {
+			result.Status = ScriptRunFailed
+			result.Error = err.Error()
+			result.Screenshot = service.captureScriptFailure(name, i)
+			run.Status = ScriptRunFailed
+			run.Error = fmt.Sprintf("step %d: %s", i, err)
+		}
+		run.Steps = append(run.Steps, result)
+		if err != nil {
+			break
+		}
+	}
+	run.DurationMS = time.Since(run.StartedAt).Milliseconds()
+	return run, nil
+}
+
+// captureScriptFailure saves a screenshot next to the scripts and returns
+// its path, or "" when the page cannot be captured.
+func (service *Service) captureScriptFailure(name string, step int) string {
+	dir := filepath.Join(service.scriptDir(), "failures")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-step%d.png", name, time.Now().Format("20060102-150405"), step))
+	if _, err := service.CaptureScreenshot(ScreenshotOptions{Path: path}); err != nil {
+		return ""
+	}
+	return path
+}
+
+// CheckAssertion waits up to the locator's timeout for assertion to hold
+// on the active tab.
+func (service *Service) CheckAssertion(assertion ScriptAssertion) error {
+	if err := assertion.validate(); err != nil {
+		return err
+	}
+	if assertion.TextContains == "" {
+		state := ElementStateAttached
+		if assertion.Exists != nil && !*assertion.Exists {
+			state = ElementStateDetached
+		}
+		return service.WaitForSelector(assertion.Locator, state)
+	}
+	wait := assertion.timeout()
+	return service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			find := locateElement(assertion.Locator)
+			reason := "element not found"
+			err := pollUntil(ctx, wait, "", func() (bool, error) {
+				element, err := find(ctx)
+				if err != nil || element == "" {
+					return false, err
+				}
+				defer func() {
+					_ = runtime.ReleaseObject(element).Do(ctx)
+				}()
+				var text string
+				if err := callElementInto(ctx, element, elementTextJS, &text); err != nil {
+					return false, err
+				}
+				reason = "text is " + strconv.Quote(text)
+				return strings.Contains(text, assertion.TextContains), nil
+			})
+			// pollUntil's reason is fixed up front; report what was seen last.
+			if errors.Is(err, ErrWaitTimeout) {
+				return fmt.Errorf("%w: expected text containing %q, %s", ErrWaitTimeout, assertion.TextContains, reason)
+			}
+			return err
+		}))
+	})
+}
+
+// StartScriptRecording records the agent actions passed to
+// RecordScriptAction and what a person does in the context's tabs, e.g.
+// over VNC, until StopScriptRecording saves the script. It starts with a
+// step that navigates to the active tab's page.
+func (service *Service) StartScriptRecording(options ScriptRecordOptions) (*Script, error) {
+	if !contextNamePattern.MatchString(options.Name) {
+		return nil, fmt.Errorf("%w: name must be 1-64 letters, digits, '.', '_' or '-'", ErrInvalidOption)
+	}
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return nil, err
+	}
+
+	service.scriptMu.Lock()
+	if service.scriptRecorder != nil {
+		service.scriptMu.Unlock()
+		return nil, fmt.Errorf("%w: a script recording is already running", ErrInvalidOption)
+	}
+	rec := &scriptRecorder{script: Script{
+		Name:        options.Name,
+		Description: options.Description,
+		Steps:       []ScriptStep{},
+		CreatedAt:   time.Now(),
+	}}
+	if state := service.activeTabStateLocked(); state != nil {
+		state.infoMu.Lock()
+		url := state.url
+		state.infoMu.Unlock()
+		if url != "" && url != "about:blank" {
+			action, _ := json.Marshal(map[string]string{"action_type": "NAVIGATE", "url": url, "wait_until": WaitUntilLoad})
+			rec.script.Steps = append(rec.script.Steps, ScriptStep{Action: action})
+		}
+	}
+	service.scriptRecorder = rec
+	service.scriptMu.Unlock()
+
+	for _, handle := range service.tabs {
+		if err := installScriptRecorder(handle, service.config.NavigateTimeout); err != nil {
+			service.scriptMu.Lock()
+			service.scriptRecorder = nil
+			service.scriptMu.Unlock()
+			for _, installed := range service.tabs {
+				uninstallScriptRecorder(installed, service.config.NavigateTimeout)
+			}
+			return nil, err
+		}
+	}
+	return rec.snapshot(), nil
+}
+
+// StopScriptRecording ends the recording and saves the script.
+func (service *Service) StopScriptRecording() (*Script, error) {
+	service.scriptMu.Lock()
+	rec := service.scriptRecorder
+	service.scriptRecorder = nil
+	service.scriptMu.Unlock()
+	if rec == nil {
+		return nil, fmt.Errorf("%w: no script recording is running", ErrInvalidOption)
+	}
+
+	service.mu.Lock()
+	for _, handle := range service.tabs {
+		uninstallScriptRecorder(handle, service.config.NavigateTimeout)
+	}
+	service.mu.Unlock()
+
+	script := rec.snapshot()
+	if err := service.SaveScript(script); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// RecordScriptAction runs an agent action and, while a script recording
+// is running, adds it as a step once it succeeds. The page events it
+// causes are not recorded again as human input. Actions by ref or element
+// are refused while recording since a replay could not find them.
+func (service *Service) RecordScriptAction(action json.RawMessage, run func() error) error {
+	service.scriptMu.Lock()
+	rec := service.scriptRecorder
+	service.scriptMu.Unlock()
+	if rec == nil {
+		return run()
+	}
+	if err := checkScriptAction(action); err != nil {
+		return err
+	}
+
+	rec.mu.Lock()
+	rec.agentBusy++
+	rec.mu.Unlock()
+	err := run()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.agentBusy--
+	rec.quietUntil = time.Now().Add(agentQuietPeriod)
+	if err == nil {
+		rec.script.Steps = append(rec.script.Steps, ScriptStep{Action: append(json.RawMessage(nil), action...), Source: ScriptSourceAgent})
+	}
+	return err
+}
+
+// handleScriptBinding records an action reported by scriptRecorderJS. It
+// runs on the tab's listener and must not block.
+func (service *Service) handleScriptBinding(payload string) {
+	service.scriptMu.Lock()
+	rec := service.scriptRecorder
+	service.scriptMu.Unlock()
+	if rec == nil {
+		return
+	}
+	var event struct {
+		Action map[string]any `json:"action"`
+		Secret bool           `json:"secret"`
+	}
+	if err := json.Unmarshal([]byte(payload), &event); err != nil || event.Action == nil {
+		return
+	}
+	// The binding is callable by any script on the page, so only the
+	// actions scriptRecorderJS sends are kept, with their known fields.
+	actionType, _ := event.Action["action_type"].(string)
+	fields, ok := scriptBindingFields[actionType]
+	if !ok {
+		return
+	}
+	kept := map[string]any{"action_type": actionType}
+	for _, field := range fields {
+		switch value := event.Action[field].(type) {
+		case string:
+			if field != "clear" {
+				kept[field] = value
+			}
+		case bool:
+			if field == "clear" {
+				kept[field] = value
+			}
+		}
+	}
+	if key := fields[0]; kept[key] == nil || kept[key] == "" {
+		return
+	}
+	event.Action = kept
+	event.Secret = event.Secret && actionType == "TYPING"
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.agentBusy > 0 || time.Now().Before(rec.quietUntil) {
+		return
+	}
+	steps := rec.script.Steps
+	// A field edited again replaces the earlier edit, as typing fills it
+	// from scratch.
+	if event.Action["action_type"] == "TYPING" && len(steps) > 0 && steps[len(steps)-1].Source == ScriptSourceHuman {
+		var last map[string]any
+		if json.Unmarshal(steps[len(steps)-1].Action, &last) == nil && last["action_type"] == "TYPING" && last["selector"] == event.Action["selector"] {
+			if text, _ := last["text"].(string); scriptVariablePattern.MatchString(text) {
+				event.Action["text"] = text
+				event.Secret = false
+			}
+			steps = steps[:len(steps)-1]
+		}
+	}
+	// Secrets such as passwords are not stored; the script asks for them
+	// as variables instead.
+	if event.Secret {
+		rec.secrets++
+		name := "password"
+		if rec.secrets > 1 {
+			name += "_" + strconv.Itoa(rec.secrets)
+		}
+		event.Action["text"] = "{{" + name + "}}"
+	}
+	action, err := json.Marshal(event.Action)
+	if err != nil {
+		return
+	}
+	rec.script.Steps = append(steps, ScriptStep{Action: action, Source: ScriptSourceHuman})
+}
+
+func (rec *scriptRecorder) snapshot() *Script {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	script := rec.script
+	script.Steps = append([]ScriptStep{}, rec.script.Steps...)
+	return &script
+}
+
+// activeScriptRecorder reports whether new tabs should record too.
+func (service *Service) activeScriptRecorder() *scriptRecorder {
+	service.scriptMu.Lock()
+	defer service.scriptMu.Unlock()
+	return service.scriptRecorder
+}
+
+// installScriptRecorder needs the service's mu.
+func installScriptRecorder(handle tabHandle, timeout time.Duration) error {
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return nil
+	}
+	return runWithTimeout(handle.ctx, timeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := runtime.AddBinding(scriptBinding).Do(ctx); err != nil {
+				return err
+			}
+			id, err := page.AddScriptToEvaluateOnNewDocument(scriptRecorderJS).Do(ctx)
+			if err != nil {
+				return err
+			}
+			handle.state.scriptRecorderID = id
+			_, _, err = runtime.Evaluate(scriptRecorderJS).Do(ctx)
+			return err
+		}))
+	})
+}
+
+// uninstallScriptRecorder needs the service's mu. It is best effort since
+// the tab may be gone.
+func uninstallScriptRecorder(handle tabHandle, timeout time.Duration) {
+	id := handle.state.scriptRecorderID
+	handle.state.scriptRecorderID = ""
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return
+	}
+	_ = runWithTimeout(handle.ctx, timeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			_ = runtime.RemoveBinding(scriptBinding).Do(ctx)
+			if id != "" {
+				_ = page.RemoveScriptToEvaluateOnNewDocument(id).Do(ctx)
+			}
+			_, _, err := runtime.Evaluate(`window.__sandboxScriptRecorder && window.__sandboxScriptRecorder.stop()`).Do(ctx)
+			return err
+		}))
+	})
+}
+
+const elementTextJS = `function() {
+  if (this instanceof HTMLInputElement || this instanceof HTMLTextAreaElement || this instanceof HTMLSelectElement) {
+    return this.value;
+  }
+  return this.innerText || this.textContent || '';
+}`
+
+// scriptRecorderJS reports trusted clicks, field changes and Enter or
+// Escape presses in the top frame as actions. Elements are located by id,
+// test id, name, label or placeholder when unique, else by a CSS path.
+const scriptRecorderJS = `(() => {
+  if (window !== window.top) return;
+  if (window.__sandboxScriptRecorder) {
+    window.__sandboxScriptRecorder.start();
+    return;
+  }
+  let active = true;
+  const send = (action, secret) => {
+    const binding = window.` + scriptBinding + `;
+    if (active && typeof binding === 'function') binding(JSON.stringify({ action, secret: !!secret }));
+  };
+  const escape = (value) => (window.CSS && CSS.escape) ? CSS.escape(value) : value.replace(/[^a-zA-Z0-9_-]/g, '\\$&');
+  const unique = (selector) => {
+    try { return document.querySelectorAll(selector).length === 1; } catch (e) { return false; }
+  };
+  const selectorFor = (el) => {
+    if (el.id && unique('#' + escape(el.id))) return '#' + escape(el.id);
+    for (const attr of ['data-testid', 'data-test', 'data-qa', 'name', 'aria-label', 'placeholder']) {
+      const value = el.getAttribute(attr);
+      if (!value) continue;
+      const selector = el.localName + '[' + attr + '="' + value.replace(/["\\]/g, '\\$&') + '"]';
+      if (unique(selector)) return selector;
+    }
+    const parts = [];
+    for (let node = el; node && node.nodeType === 1 && node !== document.documentElement; node = node.parentElement) {
+      if (node !== el && node.id && unique('#' + escape(node.id))) {
+        parts.unshift('#' + escape(node.id));
+        break;
+      }
+      let part = node.localName;
+      const parent = node.parentElement;
+      if (parent) {
+        const same = Array.from(parent.children).filter((child) => child.localName === node.localName);
+        if (same.length > 1) part += ':nth-of-type(' + (same.indexOf(node) + 1) + ')';
+      }
+      parts.unshift(part);
+    }
+    return parts.join(' > ');
+  };
+  const nonText = ['checkbox', 'radio', 'button', 'submit', 'reset', 'file', 'image', 'range', 'color'];
+  const isTextField = (el) => el instanceof HTMLTextAreaElement || (el instanceof HTMLInputElement && !nonText.includes(el.type));
+  const recorded = new WeakMap();
+  const flush = (el) => {
+    if (!isTextField(el) || el.value === '' || recorded.get(el) === el.value) return;
+    recorded.set(el, el.value);
+    send({ action_type: 'TYPING', selector: selectorFor(el), text: el.value, clear: true }, el.type === 'password');
+  };
+  const interactive = 'a,button,input,select,textarea,label,summary,[role=button],[role=link],[role=checkbox],[role=tab],[role=menuitem],[onclick]';
+  window.addEventListener('click', (event) => {
+    if (!event.isTrusted || !(event.target instanceof Element)) return;
+    const el = event.target.closest(interactive) || event.target;
+    // Fields and labelled controls are recorded from their change events.
+    if (el instanceof HTMLSelectElement || el instanceof HTMLOptionElement || el instanceof HTMLTextAreaElement) return;
+    if (el instanceof HTMLInputElement && el.type !== 'button' && el.type !== 'submit' && el.type !== 'reset' && el.type !== 'image') return;
+    if (el instanceof HTMLLabelElement && el.control) return;
+    if (document.activeElement) flush(document.activeElement);
+    send({ action_type: 'CLICK', selector: selectorFor(el) });
+  }, true);
+  window.addEventListener('change', (event) => {
+    const el = event.target;
+    if (!event.isTrusted || !(el instanceof Element)) return;
+    if (el instanceof HTMLSelectElement) {
+      send({ action_type: 'SELECT', selector: selectorFor(el), value: el.value });
+    } else if (el instanceof HTMLInputElement && (el.type === 'checkbox' || el.type === 'radio')) {
+      if (el.type === 'checkbox' || el.checked) send({ action_type: el.checked ? 'CHECK' : 'UNCHECK', selector: selectorFor(el) });
+    } else {
+      flush(el);
+    }
+  }, true);
+  window.addEventListener('keydown', (event) => {
+    if (!event.isTrusted || event.ctrlKey || event.metaKey || event.altKey) return;
+    if (event.key !== 'Enter' && event.key !== 'Escape') return;
+    if (event.key === 'Enter' && event.target instanceof HTMLTextAreaElement) return;
+    if (event.target instanceof Element) flush(event.target);
+    send({ action_type: 'PRESS', key: event.key });
+  }, true);
+  window.__sandboxScriptRecorder = {
+    start() { active = true; },
+    stop() { active = false; },
+  };
+})()`
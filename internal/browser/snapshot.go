@@ -0,0 +1,382 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+const snapshotMaxNameLength = 120
+
+// ErrStaleRef reports a ref that no longer points at a live element, either
+// because the page changed or because a newer snapshot replaced it.
+var ErrStaleRef = fmt.Errorf("%w: stale element ref, take a new snapshot", ErrInvalidLocator)
+
+// interactiveRoles are the accessibility roles that receive a ref.
+var interactiveRoles = map[string]bool{
+	"button":           true,
+	"checkbox":         true,
+	"combobox":         true,
+	"link":             true,
+	"listbox":          true,
+	"menuitem":         true,
+	"menuitemcheckbox": true,
+	"menuitemradio":    true,
+	"option":           true,
+	"radio":            true,
+	"searchbox":        true,
+	"slider":           true,
+	"spinbutton":       true,
+	"switch":           true,
+	"tab":              true,
+	"textbox":          true,
+	"treeitem":         true,
+}
+
+// structuralRoles carry no meaning for an agent unless they are named, so
+// unnamed ones are replaced by their children.
+var structuralRoles = map[string]bool{
+	"generic":       true,
+	"none":          true,
+	"presentation":  true,
+	"group":         true,
+	"paragraph":     true,
+	"div":           true,
+	"LineBreak":     true,
+	"InlineTextBox": true,
+	"LayoutTable":   true,
+	"Section":       true,
+}
+
+type SnapshotOptions struct {
+	// InteractiveOnly drops everything except nodes that received a ref.
+	InteractiveOnly bool
+}
+
+type SnapshotNode struct {
+	Ref         string          `json:"ref,omitempty"`
+	Role        string          `json:"role"`
+	Name        string          `json:"name,omitempty"`
+	Value       string          `json:"value,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Checked     string          `json:"checked,omitempty"`
+	Level       int             `json:"level,omitempty"`
+	Disabled    bool            `json:"disabled,omitempty"`
+	Focused     bool            `json:"focused,omitempty"`
+	Selected    bool            `json:"selected,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Expanded    *bool           `json:"expanded,omitempty"`
+	Children    []*SnapshotNode `json:"children,omitempty"`
+
+	backendID cdp.BackendNodeID
+}
+
+type Snapshot struct {
+	URL   string        `json:"url"`
+	Title string        `json:"title"`
+	Refs  int           `json:"refs"`
+	Tree  *SnapshotNode `json:"tree"`
+	// Text renders Tree as an indented outline, which is far more compact
+	// than the JSON form when handed to a language model.
+	Text string `json:"text"`
+
+	backendIDs map[string]cdp.BackendNodeID
+}
+
+// elementRefs maps snapshot refs to DOM nodes of the tab they were taken on.
+type elementRefs struct {
+	target target.ID
+	nodes  map[string]cdp.BackendNodeID
+}
+
+// Snapshot captures a pruned accessibility tree of the active tab. Every
+// interactive node gets a ref that Locator.Ref accepts until the next
+// snapshot.
+func (service *Service) Snapshot(options SnapshotOptions) (*Snapshot, error) {
+	var snapshot *Snapshot
+	err := service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		var url, title string
+		var nodes []*accessibility.Node
+		err := chromedp.Run(ctx,
+			chromedp.Location(&url),
+			chromedp.Title(&title),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				var err error
+				nodes, err = accessibility.GetFullAXTree().Do(ctx)
+				return err
+			}),
+		)
+		if err != nil {
+			return err
+		}
+		snapshot = BuildSnapshot(nodes, options)
+		snapshot.URL = url
+		snapshot.Title = title
+
+		refs := elementRefs{nodes: snapshot.backendIDs}
+		if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+			refs.target = c.Target.TargetID
+		}
+		service.refsMu.Lock()
+		service.refs = refs
+		service.refsMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// BuildSnapshot prunes a full accessibility tree and assigns refs in
+// document order.
+func BuildSnapshot(nodes []*accessibility.Node, options SnapshotOptions) *Snapshot {
+	builder := snapshotBuilder{
+		nodes:   make(map[accessibility.NodeID]*accessibility.Node, len(nodes)),
+		options: options,
+		refs:    make(map[string]cdp.BackendNodeID),
+	}
+	var root *accessibility.Node
+	for _, node := range nodes {
+		builder.nodes[node.NodeID] = node
+		if root == nil && node.ParentID == "" {
+			root = node
+		}
+	}
+
+	snapshot := &Snapshot{Tree: &SnapshotNode{Role: "document"}, backendIDs: builder.refs}
+	if root != nil {
+		converted := builder.convert(root)
+		if len(converted) == 1 && converted[0].Role == "RootWebArea" {
+			snapshot.Tree = converted[0]
+			snapshot.Tree.Role = "document"
+		} else {
+			snapshot.Tree.Children = converted
+		}
+	}
+	builder.assignRefs(snapshot.Tree)
+	snapshot.Refs = len(builder.refs)
+
+	var text strings.Builder
+	writeSnapshotText(&text, snapshot.Tree, 0)
+	snapshot.Text = text.String()
+	return snapshot
+}
+
+type snapshotBuilder struct {
+	nodes   map[accessibility.NodeID]*accessibility.Node
+	options SnapshotOptions
+	refs    map[string]cdp.BackendNodeID
+}
+
+// convert returns the kept nodes for node: itself with its pruned children,
+// or just the children when node is not worth showing.
+func (builder *snapshotBuilder) convert(node *accessibility.Node) []*SnapshotNode {
+	var children []*SnapshotNode
+	for _, childID := range node.ChildIDs {
+		if child := builder.nodes[childID]; child != nil {
+			children = append(children, builder.convert(child)...)
+		}
+	}
+	if node.Ignored {
+		return children
+	}
+
+	role := axValueString(node.Role)
+	name := truncateName(axValueString(node.Name))
+	interactive := interactiveRoles[role] && node.BackendDOMNodeID != 0
+
+	if role == "StaticText" {
+		if builder.options.InteractiveOnly || name == "" {
+			return nil
+		}
+		return []*SnapshotNode{{Role: "text", Name: name}}
+	}
+	if builder.options.InteractiveOnly && !interactive {
+		return children
+	}
+	if !interactive && name == "" && structuralRoles[role] {
+		return children
+	}
+
+	result := &SnapshotNode{
+		Role:        role,
+		Name:        name,
+		Description: truncateName(axValueString(node.Description)),
+	}
+	if interactive {
+		result.backendID = node.BackendDOMNodeID
+	}
+	if value := axValueString(node.Value); value != "" && value != name {
+		result.Value = truncateName(value)
+	}
+	applyAXProperties(result, node.Properties)
+	result.Children = dropEchoedText(children, name)
+	return []*SnapshotNode{result}
+}
+
+// assignRefs numbers interactive nodes in document order once pruning is
+// done, so the outline reads e1, e2, e3 from top to bottom.
+func (builder *snapshotBuilder) assignRefs(node *SnapshotNode) {
+	if node.backendID != 0 {
+		node.Ref = "e" + strconv.Itoa(len(builder.refs)+1)
+		builder.refs[node.Ref] = node.backendID
+	}
+	for _, child := range node.Children {
+		builder.assignRefs(child)
+	}
+}
+
+func applyAXProperties(node *SnapshotNode, properties []*accessibility.Property) {
+	for _, property := range properties {
+		value := axValueString(property.Value)
+		switch property.Name {
+		case accessibility.PropertyNameChecked, accessibility.PropertyNamePressed:
+			if value != "" && value != "false" {
+				node.Checked = value
+			}
+		case accessibility.PropertyNameDisabled:
+			node.Disabled = value == "true"
+		case accessibility.PropertyNameFocused:
+			node.Focused = value == "true"
+		case accessibility.PropertyNameSelected:
+			node.Selected = value == "true"
+		case accessibility.PropertyNameRequired:
+			node.Required = value == "true"
+		case accessibility.PropertyNameExpanded:
+			expanded := value == "true"
+			node.Expanded = &expanded
+		case accessibility.PropertyNameLevel:
+			node.Level, _ = strconv.Atoi(value)
+		}
+	}
+}
+
+// dropEchoedText removes text children that only repeat the parent's name,
+// which is how Chrome exposes the label of most buttons and links.
+func dropEchoedText(children []*SnapshotNode, name string) []*SnapshotNode {
+	if name == "" {
+		return children
+	}
+	kept := children[:0]
+	for _, child := range children {
+		if child.Role == "text" && strings.Contains(name, child.Name) {
+			continue
+		}
+		kept = append(kept, child)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func axValueString(value *accessibility.Value) string {
+	if value == nil || len(value.Value) == 0 {
+		return ""
+	}
+	var decoded any
+	if err := json.Unmarshal(value.Value, &decoded); err != nil {
+		return ""
+	}
+	switch v := decoded.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func truncateName(value string) string {
+	if utf8.RuneCountInString(value) <= snapshotMaxNameLength {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:snapshotMaxNameLength]) + "…"
+}
+
+func writeSnapshotText(out *strings.Builder, node *SnapshotNode, depth int) {
+	out.WriteString(strings.Repeat("  ", depth))
+	out.WriteString("- ")
+	out.WriteString(node.Role)
+	if node.Name != "" {
+		out.WriteString(" " + strconv.Quote(node.Name))
+	}
+	if node.Ref != "" {
+		out.WriteString(" [ref=" + node.Ref + "]")
+	}
+	if node.Level > 0 {
+		out.WriteString(" [level=" + strconv.Itoa(node.Level) + "]")
+	}
+	if node.Checked != "" {
+		out.WriteString(" [checked=" + node.Checked + "]")
+	}
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{node.Disabled, "disabled"},
+		{node.Focused, "focused"},
+		{node.Selected, "selected"},
+		{node.Required, "required"},
+		{node.Expanded != nil && *node.Expanded, "expanded"},
+	} {
+		if flag.set {
+			out.WriteString(" [" + flag.name + "]")
+		}
+	}
+	if node.Value != "" {
+		out.WriteString(": " + strconv.Quote(node.Value))
+	}
+	out.WriteString("\n")
+	for _, child := range node.Children {
+		writeSnapshotText(out, child, depth+1)
+	}
+}
+
+// lookupRef returns the DOM node behind ref, provided it was captured on the
+// tab that ctx runs against.
+func (service *Service) lookupRef(ctx context.Context, ref string) (cdp.BackendNodeID, error) {
+	service.refsMu.Lock()
+	refs := service.refs
+	service.refsMu.Unlock()
+
+	backendID, ok := refs.nodes[ref]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown ref %q, take a new snapshot", ErrInvalidLocator, ref)
+	}
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil && c.Target.TargetID != refs.target {
+		return 0, ErrStaleRef
+	}
+	return backendID, nil
+}
+
+func resolveRef(backendID cdp.BackendNodeID) elementFinder {
+	return func(ctx context.Context) (runtime.RemoteObjectID, error) {
+		object, err := dom.ResolveNode().WithBackendNodeID(backendID).Do(ctx)
+		if err != nil || object.ObjectID == "" {
+			return "", ErrStaleRef
+		}
+		var connected bool
+		if err := callElementInto(ctx, object.ObjectID, `function() { return this.isConnected; }`, &connected); err != nil {
+			return "", err
+		}
+		if !connected {
+			_ = runtime.ReleaseObject(object.ObjectID).Do(ctx)
+			return "", ErrStaleRef
+		}
+		return object.ObjectID, nil
+	}
+}
@@ -0,0 +1,484 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	StorageKindLocal   = "local"
+	StorageKindSession = "session"
+)
+
+// Cookie uses Playwright's field names so storage state files can be
+// shared between the two. Expires is seconds since the epoch, -1 for
+// session cookies. URL is only used when setting a cookie, in place of
+// Domain and Path.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	URL      string  `json:"url,omitempty"`
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// CookieFilter selects cookies to clear; empty fields match everything.
+type CookieFilter struct {
+	Name   string `json:"name,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+type WebStorage struct {
+	Origin string            `json:"origin"`
+	Kind   string            `json:"kind"`
+	Items  map[string]string `json:"items"`
+}
+
+// StorageUpdate changes web storage of the page in a tab. Clear runs
+// first, then Remove, then Items are set.
+type StorageUpdate struct {
+	Kind   string            `json:"kind"`
+	Items  map[string]string `json:"items,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+	Clear  bool              `json:"clear,omitempty"`
+	Tab    TabRef            `json:"tab,omitempty"`
+}
+
+// StorageState matches Playwright's storageState format.
+type StorageState struct {
+	Cookies []Cookie      `json:"cookies"`
+	Origins []OriginState `json:"origins"`
+}
+
+type OriginState struct {
+	Origin       string        `json:"origin"`
+	LocalStorage []StorageItem `json:"localStorage"`
+}
+
+type StorageItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type StorageStateResult struct {
+	Path    string `json:"path,omitempty"`
+	Cookies int    `json:"cookies"`
+	Origins int    `json:"origins"`
+}
+
+// ParseStorageState decodes and validates a storage state document.
+func ParseStorageState(data []byte) (*StorageState, error) {
+	var state StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%w: storage state: %v", ErrInvalidOption, err)
+	}
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (state *StorageState) Validate() error {
+	for _, cookie := range state.Cookies {
+		if err := validateCookie(cookie); err != nil {
+			return err
+		}
+	}
+	for _, origin := range state.Origins {
+		if _, err := storageOrigin(origin.Origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cookies returns every cookie in the browser, or only those that would be
+// sent to one of urls.
+func (service *Service) Cookies(urls []string) ([]Cookie, error) {
+	var raw []*network.Cookie
+	err := service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			if len(urls) > 0 {
+				raw, err = network.GetCookies().WithUrls(urls).Do(ctx)
+			} else {
+				raw, err = service.getAllCookies().Do(ctx)
+			}
+			return err
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]Cookie, 0, len(raw))
+	for _, cookie := range raw {
+		cookies = append(cookies, fromNetworkCookie(cookie))
+	}
+	sort.SliceStable(cookies, func(i, j int) bool {
+		if cookies[i].Domain != cookies[j].Domain {
+			return cookies[i].Domain < cookies[j].Domain
+		}
+		return cookies[i].Name < cookies[j].Name
+	})
+	return cookies, nil
+}
+
+func (service *Service) SetCookies(cookies []Cookie) error {
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, cookie := range cookies {
+		if err := validateCookie(cookie); err != nil {
+			return err
+		}
+		params = append(params, toCookieParam(cookie))
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, network.SetCookies(params))
+	})
+}
+
+// ClearCookies deletes the cookies matching filter and reports how many
+// were removed.
+func (service *Service) ClearCookies(filter CookieFilter) (int, error) {
+	removed := 0
+	err := service.runTabAction(service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			cookies, err := service.getAllCookies().Do(ctx)
+			if err != nil {
+				return err
+			}
+			if filter == (CookieFilter{}) {
+				removed = len(cookies)
+				clear := storage.ClearCookies()
+				if service.browserContextID != "" {
+					clear = clear.WithBrowserContextID(service.browserContextID)
+				}
+				return clear.Do(ctx)
+			}
+			for _, cookie := range cookies {
+				if !filter.matches(cookie) {
+					continue
+				}
+				if err := network.DeleteCookies(cookie.Name).WithDomain(cookie.Domain).WithPath(cookie.Path).Do(ctx); err != nil {
+					return err
+				}
+				removed++
+			}
+			return nil
+		}))
+	})
+	return removed, err
+}
+
+// getAllCookies reads the cookie jar of this context rather than that of
+// the default browser context.
+func (service *Service) getAllCookies() *storage.GetCookiesParams {
+	params := storage.GetCookies()
+	if service.browserContextID != "" {
+		params = params.WithBrowserContextID(service.browserContextID)
+	}
+	return params
+}
+
+func (filter CookieFilter) matches(cookie *network.Cookie) bool {
+	if filter.Name != "" && filter.Name != cookie.Name {
+		return false
+	}
+	if filter.Path != "" && filter.Path != cookie.Path {
+		return false
+	}
+	if filter.Domain != "" {
+		want := strings.ToLower(strings.TrimPrefix(filter.Domain, "."))
+		have := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+		if have != want && !strings.HasSuffix(have, "."+want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Storage reads localStorage or sessionStorage of the page in a tab.
+func (service *Service) Storage(kind string, tab TabRef) (*WebStorage, error) {
+	return service.UpdateStorage(StorageUpdate{Kind: kind, Tab: tab})
+}
+
+// UpdateStorage applies update and returns the resulting storage contents.
+func (service *Service) UpdateStorage(update StorageUpdate) (*WebStorage, error) {
+	switch update.Kind {
+	case "":
+		update.Kind = StorageKindLocal
+	case StorageKindLocal, StorageKindSession:
+	default:
+		return nil, fmt.Errorf("%w: kind must be local or session", ErrInvalidOption)
+	}
+	var result WebStorage
+	err := service.runOnTab(update.Tab, service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := evaluateInto(ctx, webStorageJS, &result, update); err != nil {
+				return fmt.Errorf("storage unavailable on this page: %w", err)
+			}
+			return nil
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Kind = update.Kind
+	if result.Items == nil {
+		result.Items = map[string]string{}
+	}
+	return &result, nil
+}
+
+// StorageState collects all cookies plus the localStorage of every origin
+// open in a tab.
+func (service *Service) StorageState() (*StorageState, error) {
+	cookies, err := service.Cookies(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cookies {
+		if cookies[i].SameSite == "" {
+			cookies[i].SameSite = string(network.CookieSameSiteLax)
+		}
+	}
+	state := &StorageState{Cookies: cookies, Origins: []OriginState{}}
+
+	service.mu.Lock()
+	tabs := append([]tabHandle(nil), service.tabs...)
+	service.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, tab := range tabs {
+		if tab.ctx == nil || tab.ctx.Err() != nil {
+			continue
+		}
+		var web WebStorage
+		err := runWithTimeout(tab.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+			return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+				return evaluateInto(ctx, webStorageJS, &web, StorageUpdate{Kind: StorageKindLocal})
+			}))
+		})
+		// Pages without storage access (about:blank, opaque origins) are
+		// simply not part of the state.
+		if err != nil || web.Origin == "" || web.Origin == "null" || seen[web.Origin] {
+			continue
+		}
+		seen[web.Origin] = true
+		origin := OriginState{Origin: web.Origin, LocalStorage: []StorageItem{}}
+		for name, value := range web.Items {
+			origin.LocalStorage = append(origin.LocalStorage, StorageItem{Name: name, Value: value})
+		}
+		sort.Slice(origin.LocalStorage, func(i, j int) bool { return origin.LocalStorage[i].Name < origin.LocalStorage[j].Name })
+		state.Origins = append(state.Origins, origin)
+	}
+	return state, nil
+}
+
+// SaveStorageState writes StorageState to path as JSON.
+func (service *Service) SaveStorageState(path string) (*StorageStateResult, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidOption)
+	}
+	state, err := service.StorageState()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// The file holds session credentials.
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+	return &StorageStateResult{Path: path, Cookies: len(state.Cookies), Origins: len(state.Origins)}, nil
+}
+
+// LoadStorageStateFile reads a state written by SaveStorageState or by
+// Playwright and applies it.
+func (service *Service) LoadStorageStateFile(path string) (*StorageStateResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	state, err := ParseStorageState(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := service.LoadStorageState(state)
+	if err != nil {
+		return nil, err
+	}
+	result.Path = path
+	return result, nil
+}
+
+// LoadStorageState sets the cookies in state and writes each origin's
+// localStorage. Origins are opened in a scratch tab whose document
+// requests are answered locally, so nothing is fetched from the sites.
+func (service *Service) LoadStorageState(state *StorageState) (*StorageStateResult, error) {
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	if err := service.SetCookies(state.Cookies); err != nil {
+		return nil, err
+	}
+	result := &StorageStateResult{Cookies: len(state.Cookies), Origins: len(state.Origins)}
+	if len(state.Origins) == 0 {
+		return result, nil
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if err := service.ensureStartedLocked(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := service.newTargetLocked()
+	defer cancel()
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if e, ok := ev.(*fetch.EventRequestPaused); ok {
+			go func() {
+				c := chromedp.FromContext(ctx)
+				if c == nil || c.Target == nil {
+					return
+				}
+				_ = fetch.FulfillRequest(e.RequestID, 200).
+					WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/html"}}).
+					Do(cdp.WithExecutor(ctx, c.Target))
+			}()
+		}
+	})
+
+	timeout := service.config.NavigateTimeout * time.Duration(len(state.Origins)+1)
+	err := runWithTimeout(ctx, timeout, func(ctx context.Context) error {
+		patterns := []*fetch.RequestPattern{{URLPattern: "*", ResourceType: network.ResourceTypeDocument}}
+		if err := chromedp.Run(ctx, fetch.Enable().WithPatterns(patterns)); err != nil {
+			return err
+		}
+		for _, origin := range state.Origins {
+			target, err := storageOrigin(origin.Origin)
+			if err != nil {
+				return err
+			}
+			items := make(map[string]string, len(origin.LocalStorage))
+			for _, item := range origin.LocalStorage {
+				items[item.Name] = item.Value
+			}
+			if err := chromedp.Run(ctx,
+				chromedp.Navigate(target),
+				chromedp.ActionFunc(func(ctx context.Context) error {
+					return evaluateInto(ctx, webStorageJS, nil, StorageUpdate{Kind: StorageKindLocal, Items: items})
+				}),
+			); err != nil {
+				return fmt.Errorf("restore storage for %s: %w", origin.Origin, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func validateCookie(cookie Cookie) error {
+	if cookie.Name == "" {
+		return fmt.Errorf("%w: cookie name is required", ErrInvalidOption)
+	}
+	if cookie.URL == "" && cookie.Domain == "" {
+		return fmt.Errorf("%w: cookie %q needs url or domain", ErrInvalidOption, cookie.Name)
+	}
+	switch cookie.SameSite {
+	case "", "Strict", "Lax", "None":
+	default:
+		return fmt.Errorf("%w: cookie %q sameSite must be Strict, Lax or None", ErrInvalidOption, cookie.Name)
+	}
+	return nil
+}
+
+func fromNetworkCookie(cookie *network.Cookie) Cookie {
+	expires := cookie.Expires
+	if cookie.Session {
+		expires = -1
+	}
+	return Cookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Expires:  expires,
+		HTTPOnly: cookie.HTTPOnly,
+		Secure:   cookie.Secure,
+		SameSite: string(cookie.SameSite),
+	}
+}
+
+func toCookieParam(cookie Cookie) *network.CookieParam {
+	param := &network.CookieParam{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		URL:      cookie.URL,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HTTPOnly,
+		SameSite: network.CookieSameSite(cookie.SameSite),
+	}
+	if cookie.URL == "" && param.Path == "" {
+		param.Path = "/"
+	}
+	if cookie.Expires > 0 {
+		seconds, fraction := math.Modf(cookie.Expires)
+		expires := cdp.TimeSinceEpoch(time.Unix(int64(seconds), int64(fraction*1e9)))
+		param.Expires = &expires
+	}
+	return param
+}
+
+// storageOrigin checks that origin is a bare http(s) origin and returns a
+// URL to load it.
+func storageOrigin(origin string) (string, error) {
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%w: invalid origin %q", ErrInvalidOption, origin)
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/", nil
+}
+
+const webStorageJS = `function(spec) {
+  const store = spec.kind === 'session' ? window.sessionStorage : window.localStorage;
+  if (spec.clear) store.clear();
+  for (const name of spec.remove || []) store.removeItem(name);
+  for (const [name, value] of Object.entries(spec.items || {})) store.setItem(name, value);
+  const items = {};
+  for (let i = 0; i < store.length; i++) {
+    const name = store.key(i);
+    items[name] = store.getItem(name);
+  }
+  return { origin: location.origin, items };
+}`
@@ -0,0 +1,278 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+var ErrTabNotFound = errors.New("tab not found")
+
+// TabRef selects a tab by its CDP target ID. The empty ref is the active
+// tab. A decimal index is still accepted for older clients, but indexes
+// shift when tabs close.
+type TabRef string
+
+// TabAt refers to the tab at index.
+func TabAt(index int) TabRef {
+	return TabRef(strconv.Itoa(index))
+}
+
+// UnmarshalJSON accepts a target ID string or a numeric index.
+func (ref *TabRef) UnmarshalJSON(data []byte) error {
+	var index int
+	if err := json.Unmarshal(data, &index); err == nil {
+		*ref = TabAt(index)
+		return nil
+	}
+	var id string
+	if err := json.Unmarshal(data, &id); err != nil {
+		return errors.New("tab must be a tab id or index")
+	}
+	*ref = TabRef(id)
+	return nil
+}
+
+type TabInfo struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Active   bool   `json:"active"`
+	OpenerID string `json:"opener_id,omitempty"`
+}
+
+// NewTab opens a tab, makes it active and navigates it to url if set.
+func (service *Service) NewTab(url string) (TabInfo, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if err := service.ensureStartedLocked(); err != nil {
+		return TabInfo{}, err
+	}
+
+	handle, err := service.createTabLocked()
+	if err != nil {
+		return TabInfo{}, err
+	}
+	index := service.addTabLocked(handle)
+	service.activateTabLocked(index)
+
+	if url != "" {
+		if err := runWithTimeout(handle.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+			return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+				_, _, _, err := page.Navigate(url).Do(ctx)
+				return err
+			}))
+		}); err != nil {
+			return TabInfo{}, err
+		}
+	}
+	return TabInfo{ID: string(handle.targetID), Index: index, URL: url, Active: true}, nil
+}
+
+func (service *Service) SwitchTab(ref TabRef) (TabInfo, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if err := service.ensureStartedLocked(); err != nil {
+		return TabInfo{}, err
+	}
+	index, err := service.tabIndexLocked(ref)
+	if err != nil {
+		return TabInfo{}, err
+	}
+	handle := service.tabs[index]
+	if handle.ctx == nil || handle.ctx.Err() != nil {
+		return TabInfo{}, errors.New("tab unavailable")
+	}
+	service.activateTabLocked(index)
+	return TabInfo{ID: string(handle.targetID), Index: index, Active: true, OpenerID: string(handle.openerID)}, nil
+}
+
+// CloseTab closes a tab. Closing the last tab opens a blank one so the
+// context always has an active tab.
+func (service *Service) CloseTab(ref TabRef) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if err := service.ensureStartedLocked(); err != nil {
+		return err
+	}
+	index, err := service.tabIndexLocked(ref)
+	if err != nil {
+		return err
+	}
+	return service.removeTabLocked(index)
+}
+
+func (service *Service) TabList() ([]TabInfo, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if err := service.ensureStartedLocked(); err != nil {
+		return nil, err
+	}
+
+	results := make([]TabInfo, 0, len(service.tabs))
+	for i, tab := range service.tabs {
+		info := TabInfo{ID: string(tab.targetID), Index: i, Active: i == service.activeTab, OpenerID: string(tab.openerID)}
+		if tab.ctx != nil && tab.ctx.Err() == nil {
+			_ = runWithTimeout(tab.ctx, service.config.NavigateTimeout, func(ctx context.Context) error {
+				return chromedp.Run(ctx,
+					chromedp.Evaluate("document.title", &info.Title),
+					chromedp.Evaluate("location.href", &info.URL),
+				)
+			})
+		}
+		results = append(results, info)
+	}
+	return results, nil
+}
+
+// tabIndexLocked resolves ref to a position in service.tabs.
+func (service *Service) tabIndexLocked(ref TabRef) (int, error) {
+	if ref == "" {
+		if len(service.tabs) == 0 {
+			return -1, fmt.Errorf("%w: no open tab", ErrTabNotFound)
+		}
+		return service.activeTab, nil
+	}
+	for i, tab := range service.tabs {
+		if string(tab.targetID) == string(ref) {
+			return i, nil
+		}
+	}
+	if index, err := strconv.Atoi(string(ref)); err == nil && index >= 0 && index < len(service.tabs) {
+		return index, nil
+	}
+	return -1, fmt.Errorf("%w: %s", ErrTabNotFound, ref)
+}
+
+func (service *Service) addTabLocked(handle tabHandle) int {
+	service.tabs = append(service.tabs, handle)
+	service.publish(Event{Type: EventTabCreated, TabID: string(handle.targetID), OpenerID: string(handle.openerID)})
+	return len(service.tabs) - 1
+}
+
+func (service *Service) activateTabLocked(index int) {
+	handle := service.tabs[index]
+	changed := service.tabCtx != handle.ctx
+	service.activeTab = index
+	service.tabCtx = handle.ctx
+	service.tabCancel = handle.cancel
+	if changed {
+		service.publish(Event{Type: EventTabActivated, TabID: string(handle.targetID)})
+	}
+}
+
+// removeTabLocked closes the tab at index and keeps the active tab
+// pointing at the same tab where possible.
+func (service *Service) removeTabLocked(index int) error {
+	handle := service.tabs[index]
+	if handle.cancel != nil {
+		handle.cancel()
+	}
+	service.tabs = append(service.tabs[:index], service.tabs[index+1:]...)
+	service.publish(Event{Type: EventTabClosed, TabID: string(handle.targetID)})
+
+	if index < service.activeTab {
+		service.activeTab--
+	}
+	if len(service.tabs) == 0 {
+		replacement, err := service.createTabLocked()
+		if err != nil {
+			service.tabCtx = nil
+			service.tabCancel = nil
+			return err
+		}
+		service.addTabLocked(replacement)
+	}
+	if service.activeTab >= len(service.tabs) {
+		service.activeTab = len(service.tabs) - 1
+	}
+	service.activateTabLocked(service.activeTab)
+	return nil
+}
+
+// handleTargetEvent follows the tab's own target: pages it opens are
+// attached as new tabs, and changes to its URL, title or lifetime are
+// published. Every tab sees every target, so events about other targets
+// are ignored.
+func (service *Service) handleTargetEvent(self target.ID, state *tabState, ev interface{}) {
+	switch e := ev.(type) {
+	case *target.EventTargetCreated:
+		if e.TargetInfo.Type == "page" && e.TargetInfo.OpenerID == self {
+			go service.attachPopup(e.TargetInfo.TargetID, self)
+		}
+	case *target.EventTargetInfoChanged:
+		if e.TargetInfo.TargetID != self {
+			return
+		}
+		urlChanged, titleChanged := state.updateInfo(e.TargetInfo.URL, e.TargetInfo.Title)
+		if urlChanged {
+			service.publish(Event{Type: EventTabNavigated, TabID: string(self), URL: e.TargetInfo.URL, Title: e.TargetInfo.Title})
+		}
+		if titleChanged {
+			service.publish(Event{Type: EventTabTitleChanged, TabID: string(self), URL: e.TargetInfo.URL, Title: e.TargetInfo.Title})
+		}
+	case *target.EventTargetDestroyed:
+		if e.TargetID == self {
+			go service.forgetTab(self)
+		}
+	}
+}
+
+// attachPopup tracks a page opened by one of the tabs, e.g. through
+// window.open or target=_blank. The active tab does not change.
+func (service *Service) attachPopup(id, opener target.ID) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.closed || service.allocCtx == nil {
+		return
+	}
+	if _, err := service.tabIndexLocked(TabRef(id)); err == nil {
+		return
+	}
+	ctx, cancel := chromedp.NewContext(service.allocCtx, chromedp.WithTargetID(id))
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return
+	}
+	handle, err := service.setupTabLocked(ctx, cancel)
+	if err != nil {
+		cancel()
+		return
+	}
+	handle.openerID = opener
+	service.addTabLocked(handle)
+}
+
+// forgetTab drops a tab that was closed from the page or the browser UI.
+func (service *Service) forgetTab(id target.ID) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	for i, tab := range service.tabs {
+		if tab.targetID == id {
+			_ = service.removeTabLocked(i)
+			return
+		}
+	}
+}
+
+// updateInfo records the latest URL and title and reports which changed.
+func (state *tabState) updateInfo(url, title string) (bool, bool) {
+	state.infoMu.Lock()
+	defer state.infoMu.Unlock()
+	urlChanged := url != state.url
+	titleChanged := title != state.title
+	state.url = url
+	state.title = title
+	return urlChanged, titleChanged
+}
@@ -0,0 +1,112 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// fileChooserTimeout bounds the wait for a chooser after the click. Pages
+// open it from the click handler, so it either appears at once or never.
+const fileChooserTimeout = 5 * time.Second
+
+type fileInputInfo struct {
+	File     bool `json:"file"`
+	Multiple bool `json:"multiple"`
+}
+
+// UploadFiles sets the files of a file input. When locator points at some
+// other element, such as a styled upload button, the element is clicked and
+// the files go to the file chooser it opens instead of a native dialog.
+func (service *Service) UploadFiles(locator Locator, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("%w: at least one path is required", ErrInvalidOption)
+	}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("%w: path %q must be absolute", ErrInvalidOption, path)
+		}
+		stat, err := os.Stat(path)
+		if err != nil || !stat.Mode().IsRegular() {
+			return fmt.Errorf("%w: %q is not a file", ErrInvalidOption, path)
+		}
+	}
+
+	return service.withElement(locator, true, func(ctx context.Context, element runtime.RemoteObjectID) error {
+		var input fileInputInfo
+		if err := callElementInto(ctx, element, fileInputInfoJS, &input); err != nil {
+			return err
+		}
+		if input.File {
+			if len(paths) > 1 && !input.Multiple {
+				return fmt.Errorf("%w: file input accepts a single file", ErrInvalidOption)
+			}
+			return dom.SetFileInputFiles(paths).WithObjectID(element).Do(ctx)
+		}
+
+		state := service.activeTabStateLocked()
+		if state == nil {
+			return fmt.Errorf("%w: no open tab", ErrTabNotFound)
+		}
+		chooser := make(chan *page.EventFileChooserOpened, 1)
+		state.setFileChooser(chooser)
+		defer state.setFileChooser(nil)
+		if err := page.SetInterceptFileChooserDialog(true).Do(ctx); err != nil {
+			return err
+		}
+		defer func() {
+			_ = page.SetInterceptFileChooserDialog(false).Do(ctx)
+		}()
+
+		x, y, err := elementCenter(ctx, element)
+		if err != nil {
+			return err
+		}
+		if err := chromedp.MouseClickXY(x, y).Do(ctx); err != nil {
+			return err
+		}
+		service.setMousePos(x, y)
+
+		select {
+		case opened := <-chooser:
+			if len(paths) > 1 && opened.Mode != page.FileChooserOpenedModeSelectMultiple {
+				return fmt.Errorf("%w: file chooser accepts a single file", ErrInvalidOption)
+			}
+			return dom.SetFileInputFiles(paths).WithBackendNodeID(opened.BackendNodeID).Do(ctx)
+		case <-time.After(fileChooserTimeout):
+			return fmt.Errorf("%w: clicking the element opened no file chooser", ErrWaitTimeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func (state *tabState) setFileChooser(chooser chan *page.EventFileChooserOpened) {
+	state.fileChooserMu.Lock()
+	state.fileChooser = chooser
+	state.fileChooserMu.Unlock()
+}
+
+func (state *tabState) handleFileChooser(e *page.EventFileChooserOpened) {
+	state.fileChooserMu.Lock()
+	defer state.fileChooserMu.Unlock()
+	if state.fileChooser == nil {
+		return
+	}
+	select {
+	case state.fileChooser <- e:
+	default:
+	}
+}
+
+const fileInputInfoJS = `function() {
+  const file = this instanceof HTMLInputElement && this.type === 'file';
+  return { file, multiple: file && this.multiple };
+}`
@@ -0,0 +1,344 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// WaitUntilCommit returns once the navigation is accepted, which is how
+	// Navigate has always behaved.
+	WaitUntilCommit           = "commit"
+	WaitUntilDOMContentLoaded = "domcontentloaded"
+	WaitUntilLoad             = "load"
+	WaitUntilNetworkIdle      = "networkidle"
+
+	ElementStateAttached = "attached"
+	ElementStateDetached = "detached"
+	ElementStateVisible  = "visible"
+	ElementStateHidden   = "hidden"
+
+	defaultWaitTimeout = 30 * time.Second
+)
+
+// lifecycleEvents maps load states to the Page.lifecycleEvent names that
+// signal them.
+var lifecycleEvents = map[string]string{
+	WaitUntilDOMContentLoaded: "DOMContentLoaded",
+	WaitUntilLoad:             "load",
+	WaitUntilNetworkIdle:      "networkIdle",
+}
+
+type NavigateOptions struct {
+	WaitUntil string
+	Timeout   time.Duration
+}
+
+func validateLoadState(state string, allowCommit bool) error {
+	if _, ok := lifecycleEvents[state]; ok || (allowCommit && state == WaitUntilCommit) {
+		return nil
+	}
+	return fmt.Errorf("%w: wait_until must be commit, domcontentloaded, load or networkidle", ErrInvalidOption)
+}
+
+func waitTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return defaultWaitTimeout
+}
+
+// NavigateWithOptions navigates the active tab and waits for the main
+// frame of the new document to reach options.WaitUntil.
+func (service *Service) NavigateWithOptions(url string, options NavigateOptions) error {
+	if options.WaitUntil == "" {
+		options.WaitUntil = WaitUntilCommit
+	}
+	if err := validateLoadState(options.WaitUntil, true); err != nil {
+		return err
+	}
+	wait := waitTimeout(options.Timeout)
+	return service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		state := service.activeTabStateLocked()
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			_, loaderID, errorText, err := page.Navigate(url).Do(ctx)
+			if err != nil {
+				return err
+			}
+			if errorText != "" {
+				return fmt.Errorf("navigation failed: %s", errorText)
+			}
+			// Same-document navigations have no loader and nothing to wait for.
+			if options.WaitUntil == WaitUntilCommit || loaderID == "" || state == nil {
+				return nil
+			}
+			event := lifecycleEvents[options.WaitUntil]
+			return pollUntil(ctx, wait, "page did not reach "+options.WaitUntil, func() (bool, error) {
+				return state.reachedLifecycle(loaderID, event), nil
+			})
+		}))
+	})
+}
+
+// WaitForLoadState waits for the current document of the active tab to
+// reach state, e.g. after a click that navigates.
+func (service *Service) WaitForLoadState(state string, timeout time.Duration) error {
+	if state == "" {
+		state = WaitUntilLoad
+	}
+	if err := validateLoadState(state, false); err != nil {
+		return err
+	}
+	wait := waitTimeout(timeout)
+	return service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		tab := service.activeTabStateLocked()
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return pollUntil(ctx, wait, "page did not reach "+state, func() (bool, error) {
+				if tab != nil {
+					if reached, known := tab.currentLifecycle(lifecycleEvents[state]); known {
+						return reached, nil
+					}
+				}
+				// Tabs attached after their document loaded never saw its
+				// lifecycle events, so fall back to the ready state.
+				var readyState string
+				if err := evaluateInto(ctx, `function() { return document.readyState; }`, &readyState); err != nil {
+					return false, err
+				}
+				if state == WaitUntilDOMContentLoaded {
+					return readyState != "loading", nil
+				}
+				return readyState == "complete", nil
+			})
+		}))
+	})
+}
+
+// WaitForSelector waits until the element locator points at is in state:
+// attached to the DOM, detached from it, visible or hidden. Missing
+// elements count as hidden. The timeout is locator.TimeoutMS.
+func (service *Service) WaitForSelector(locator Locator, state string) error {
+	if state == "" {
+		state = ElementStateVisible
+	}
+	switch state {
+	case ElementStateAttached, ElementStateDetached, ElementStateVisible, ElementStateHidden:
+	default:
+		return fmt.Errorf("%w: state must be attached, detached, visible or hidden", ErrInvalidOption)
+	}
+	if err := locator.validate(); err != nil {
+		return err
+	}
+	wait := locator.timeout()
+	return service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			find := locateElement(locator)
+			if locator.Ref != "" || locator.Element > 0 {
+				var backendID cdp.BackendNodeID
+				var err error
+				if locator.Ref != "" {
+					backendID, err = service.lookupRef(ctx, locator.Ref)
+				} else {
+					backendID, err = service.lookupMark(ctx, locator.Element)
+				}
+				if err != nil {
+					return err
+				}
+				resolve := resolveRef(backendID)
+				// A stale ref means the element is gone, which is what
+				// detached and hidden wait for.
+				find = func(ctx context.Context) (runtime.RemoteObjectID, error) {
+					element, err := resolve(ctx)
+					if errors.Is(err, ErrStaleRef) {
+						return "", nil
+					}
+					return element, err
+				}
+			}
+			return pollUntil(ctx, wait, "element not "+state, func() (bool, error) {
+				element, err := find(ctx)
+				if err != nil || element == "" {
+					return element == "" && (state == ElementStateDetached || state == ElementStateHidden), err
+				}
+				defer func() {
+					_ = runtime.ReleaseObject(element).Do(ctx)
+				}()
+				var current elementState
+				if err := callElementInto(ctx, element, elementStateJS, &current); err != nil {
+					return false, err
+				}
+				switch state {
+				case ElementStateAttached:
+					return true, nil
+				case ElementStateDetached:
+					return false, nil
+				case ElementStateVisible:
+					return current.Visible, nil
+				default:
+					return !current.Visible, nil
+				}
+			})
+		}))
+	})
+}
+
+// WaitForURL waits until the active tab's URL matches pattern, a glob in
+// which * matches any characters and ? one. It returns the matching URL.
+func (service *Service) WaitForURL(pattern string, timeout time.Duration) (string, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return "", fmt.Errorf("%w: url is required", ErrInvalidOption)
+	}
+	matcher, err := compileURLGlob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	wait := waitTimeout(timeout)
+	var url string
+	err = service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return pollUntil(ctx, wait, "url did not match "+pattern, func() (bool, error) {
+				if err := evaluateInto(ctx, `function() { return location.href; }`, &url); err != nil {
+					return false, err
+				}
+				return matcher.MatchString(url), nil
+			})
+		}))
+	})
+	if err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// WaitForFunction evaluates expression until it returns a truthy value and
+// returns that value. The expression may be a function, which is called,
+// and may return a promise.
+func (service *Service) WaitForFunction(expression string, timeout time.Duration) (any, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("%w: expression is required", ErrInvalidOption)
+	}
+	wait := waitTimeout(timeout)
+	const reason = "function did not return a truthy value"
+	var value any
+	err := service.runTabAction(wait+service.config.NavigateTimeout, func(ctx context.Context) error {
+		return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			// A promise that never settles must end with the wait rather
+			// than the action deadline, which would reset the browser.
+			deadline := time.Now().Add(wait)
+			return pollUntil(ctx, wait, reason, func() (bool, error) {
+				evalCtx, cancel := context.WithDeadline(ctx, deadline)
+				defer cancel()
+				result, exception, err := runtime.Evaluate("(async () => { const value = (" + expression + "); return typeof value === 'function' ? await value() : await value; })()").
+					WithReturnByValue(true).
+					WithAwaitPromise(true).
+					Do(evalCtx)
+				if err != nil {
+					if ctx.Err() == nil && errors.Is(evalCtx.Err(), context.DeadlineExceeded) {
+						return false, fmt.Errorf("%w: %s", ErrWaitTimeout, reason)
+					}
+					return false, err
+				}
+				if exception != nil {
+					return false, fmt.Errorf("%w: %s", ErrInvalidOption, exceptionText(exception))
+				}
+				value = nil
+				if len(result.Value) > 0 {
+					if err := json.Unmarshal(result.Value, &value); err != nil {
+						return false, err
+					}
+				}
+				return truthy(value), nil
+			})
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// pollUntil calls check until it reports true, failing with ErrWaitTimeout
+// once wait has passed. Like withElement, the wait has its own deadline so
+// a timeout does not look like a dead tab.
+func pollUntil(ctx context.Context, wait time.Duration, reason string, check func() (bool, error)) error {
+	deadline := time.Now().Add(wait)
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s", ErrWaitTimeout, reason)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(elementPollInterval):
+		}
+	}
+}
+
+func (service *Service) activeTabStateLocked() *tabState {
+	if service.activeTab < 0 || service.activeTab >= len(service.tabs) {
+		return nil
+	}
+	return service.tabs[service.activeTab].state
+}
+
+// handleLifecycleEvent records the lifecycle of the main frame's current
+// document. The main frame shares the tab's target ID.
+func (state *tabState) handleLifecycleEvent(self cdp.FrameID, e *page.EventLifecycleEvent) {
+	if e.FrameID != self {
+		return
+	}
+	state.lifecycleMu.Lock()
+	defer state.lifecycleMu.Unlock()
+	if e.Name == "init" || e.LoaderID != state.loaderID {
+		state.loaderID = e.LoaderID
+		state.lifecycle = make(map[string]bool)
+	}
+	state.lifecycle[e.Name] = true
+}
+
+func (state *tabState) reachedLifecycle(loaderID cdp.LoaderID, event string) bool {
+	state.lifecycleMu.Lock()
+	defer state.lifecycleMu.Unlock()
+	return state.loaderID == loaderID && state.lifecycle[event]
+}
+
+// currentLifecycle reports whether the current document reached event,
+// and whether any lifecycle was seen for it at all.
+func (state *tabState) currentLifecycle(event string) (bool, bool) {
+	state.lifecycleMu.Lock()
+	defer state.lifecycleMu.Unlock()
+	if state.loaderID == "" {
+		return false, false
+	}
+	return state.lifecycle[event], true
+}
@@ -50,6 +50,14 @@ func MCPServersPath() string {
 	return normalizeAbs(filepath.Join(CachePath(), "mcp-servers.json"))
 }
 
+func FileHistoryPath() string {
+	return normalizeAbs(filepath.Join(CachePath(), "file-history"))
+}
+
+func SnapshotsPath() string {
+	return normalizeAbs(filepath.Join(CachePath(), "snapshots"))
+}
+
 func LogsPath() string {
 	if value := envPath("SANDBOX_LOGS_ROOT"); value != "" {
 		return value
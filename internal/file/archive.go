@@ -0,0 +1,540 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+	FormatTar   = "tar"
+
+	DefaultExtractMaxBytes   int64 = 1 << 30
+	DefaultExtractMaxEntries       = 10000
+	DefaultUploadMaxBytes    int64 = 1 << 30
+
+	// maxSkippedNames caps the skipped entries listed in a result; the
+	// rest are only counted.
+	maxSkippedNames = 100
+)
+
+var (
+	ErrArchiveTooLarge   = errors.New("archive exceeds size limit")
+	ErrArchiveTooMany    = errors.New("archive exceeds entry limit")
+	ErrArchiveExists     = errors.New("archive entry already exists")
+	ErrUnsupportedFormat = errors.New("unsupported archive format")
+	ErrUnsafeEntry       = errors.New("unsafe archive entry")
+)
+
+type ExtractOptions struct {
+	MaxBytes   int64
+	MaxEntries int
+	// Overwrite lets entries replace existing files; without it the first
+	// existing file stops the extraction. Replaced files are recorded in
+	// History when it is set.
+	Overwrite bool
+	History   *History
+}
+
+type ExtractResult struct {
+	Format  string   `json:"format"`
+	Target  string   `json:"target"`
+	Entries int      `json:"entries"`
+	Bytes   int64    `json:"bytes"`
+	Skipped []string `json:"skipped"`
+	// SkippedTruncated is set when more entries were skipped than listed.
+	SkippedTruncated bool `json:"skipped_truncated,omitempty"`
+}
+
+type ArchiveOptions struct {
+	Format  string
+	Root    string
+	Paths   []string
+	Globs   []string
+	Exclude []string
+}
+
+type ArchiveResult struct {
+	Format  string `json:"format"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Extract unpacks a zip, tar, or tar.gz archive into target. Entries that
+// would land outside target, links, and special files are never written.
+// The limits can only lower the defaults, which are also used when unset.
+func Extract(archivePath string, target string, options ExtractOptions) (ExtractResult, error) {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultExtractMaxBytes
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = DefaultExtractMaxEntries
+	}
+	options.MaxBytes = min(options.MaxBytes, DefaultExtractMaxBytes)
+	options.MaxEntries = min(options.MaxEntries, DefaultExtractMaxEntries)
+	format, err := DetectArchiveFormat(archivePath)
+	if err != nil {
+		return ExtractResult{}, err
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return ExtractResult{}, err
+	}
+
+	extractor := &extractor{target: target, options: options, result: ExtractResult{Format: format, Target: target, Skipped: []string{}}}
+	switch format {
+	case FormatZip:
+		err = extractor.zip(archivePath)
+	default:
+		err = extractor.tar(archivePath, format == FormatTarGz)
+	}
+	if err != nil {
+		return extractor.result, err
+	}
+	return extractor.result, nil
+}
+
+func DetectArchiveFormat(path string) (string, error) {
+	handle, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer handle.Close()
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(handle, header)
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return FormatZip, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return FormatTarGz, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return FormatTar, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+type extractor struct {
+	target  string
+	options ExtractOptions
+	result  ExtractResult
+}
+
+func (extractor *extractor) zip(archivePath string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		mode := entry.Mode()
+		if mode.IsDir() {
+			if err := extractor.dir(entry.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			if err := extractor.skip(entry.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		source, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = extractor.file(entry.Name, source, mode)
+		source.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (extractor *extractor) tar(archivePath string, compressed bool) error {
+	handle, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	var source io.Reader = bufio.NewReader(handle)
+	if compressed {
+		gz, err := gzip.NewReader(source)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	reader := tar.NewReader(source)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := extractor.dir(header.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractor.file(header.Name, reader, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		default:
+			if err := extractor.skip(header.Name); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (extractor *extractor) dir(name string) error {
+	dest, err := extractor.destination(name)
+	if err != nil {
+		return err
+	}
+	if err := extractor.count(); err != nil {
+		return err
+	}
+	return os.MkdirAll(dest, 0755)
+}
+
+func (extractor *extractor) file(name string, source io.Reader, mode fs.FileMode) error {
+	dest, err := extractor.destination(name)
+	if err != nil {
+		return err
+	}
+	if err := extractor.count(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	write := func() error {
+		handle, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+		if err != nil {
+			return err
+		}
+		defer handle.Close()
+
+		// Declared sizes are untrusted, so the limit is enforced on bytes read.
+		remaining := extractor.options.MaxBytes - extractor.result.Bytes
+		written, err := io.Copy(handle, io.LimitReader(source, remaining+1))
+		extractor.result.Bytes += written
+		if err != nil {
+			return err
+		}
+		if extractor.result.Bytes > extractor.options.MaxBytes {
+			return ErrArchiveTooLarge
+		}
+		return nil
+	}
+	if _, err := os.Lstat(dest); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return write()
+	}
+	if !extractor.options.Overwrite {
+		return fmt.Errorf("%w: %q", ErrArchiveExists, name)
+	}
+	if extractor.options.History != nil {
+		return extractor.options.History.Apply(dest, "extract", write)
+	}
+	return write()
+}
+
+// skip notes an entry that is not written. It still counts towards the
+// entry limit.
+func (extractor *extractor) skip(name string) error {
+	if err := extractor.count(); err != nil {
+		return err
+	}
+	if len(extractor.result.Skipped) < maxSkippedNames {
+		extractor.result.Skipped = append(extractor.result.Skipped, name)
+	} else {
+		extractor.result.SkippedTruncated = true
+	}
+	return nil
+}
+
+func (extractor *extractor) count() error {
+	extractor.result.Entries++
+	if extractor.result.Entries > extractor.options.MaxEntries {
+		return ErrArchiveTooMany
+	}
+	return nil
+}
+
+func (extractor *extractor) destination(name string) (string, error) {
+	cleaned := filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))
+	if filepath.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" {
+		return "", fmt.Errorf("%w: %q has an absolute path", ErrUnsafeEntry, name)
+	}
+	dest := filepath.Join(extractor.target, cleaned)
+	if err := ValidateWorkspacePath(dest, extractor.target); err != nil {
+		return "", fmt.Errorf("%w: %q escapes target directory", ErrUnsafeEntry, name)
+	}
+	// The path is only safe lexically; a link already under target could
+	// still redirect the write elsewhere.
+	rel, _ := filepath.Rel(extractor.target, dest)
+	current := extractor.target
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("%w: %q passes through a symlink", ErrUnsafeEntry, name)
+		}
+	}
+	return dest, nil
+}
+
+// Archive writes the files under Paths, plus the files under Root matching
+// Globs, into output. Entry names are relative to Root.
+func Archive(output io.Writer, options ArchiveOptions) (ArchiveResult, error) {
+	root := options.Root
+	files, err := collectArchiveFiles(root, options.Paths, options.Globs)
+	if err != nil {
+		return ArchiveResult{}, err
+	}
+	files = slices.DeleteFunc(files, func(name string) bool {
+		return slices.Contains(options.Exclude, name)
+	})
+	result := ArchiveResult{Format: options.Format}
+
+	switch options.Format {
+	case FormatZip:
+		writer := zip.NewWriter(output)
+		for _, name := range files {
+			if err := addZipEntry(writer, root, name, &result); err != nil {
+				return ArchiveResult{}, err
+			}
+		}
+		return result, writer.Close()
+	case FormatTarGz:
+		gz := gzip.NewWriter(output)
+		writer := tar.NewWriter(gz)
+		for _, name := range files {
+			if err := addTarEntry(writer, root, name, &result); err != nil {
+				return ArchiveResult{}, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return ArchiveResult{}, err
+		}
+		return result, gz.Close()
+	default:
+		return ArchiveResult{}, ErrUnsupportedFormat
+	}
+}
+
+// ArchiveToFile writes the archive next to output and renames it into place,
+// keeping both the partial and final file out of the archive itself.
+func ArchiveToFile(output string, options ArchiveOptions) (ArchiveResult, error) {
+	output = filepath.Clean(output)
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return ArchiveResult{}, err
+	}
+	tmp := output + ".tmp"
+	handle, err := os.Create(tmp)
+	if err != nil {
+		return ArchiveResult{}, err
+	}
+	options.Exclude = append(options.Exclude, output, tmp)
+	result, err := Archive(handle, options)
+	if closeErr := handle.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, output)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return ArchiveResult{}, err
+	}
+	return result, nil
+}
+
+func collectArchiveFiles(root string, paths []string, globs []string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, path := range paths {
+		if err := ValidateWorkspacePath(path, root); err != nil {
+			return nil, err
+		}
+		err := filepath.WalkDir(filepath.Clean(path), func(current string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				selected[current] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(globs) > 0 {
+		patterns := make([]*regexp.Regexp, 0, len(globs))
+		for _, glob := range globs {
+			pattern, err := compileGlob(glob)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern)
+		}
+		err := filepath.WalkDir(root, func(current string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(root, current)
+			if err != nil {
+				return err
+			}
+			for _, pattern := range patterns {
+				if pattern.MatchString(filepath.ToSlash(rel)) {
+					selected[current] = true
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files := make([]string, 0, len(selected))
+	for name := range selected {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// compileGlob supports path.Match syntax plus "**" spanning directories.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					builder.WriteString("(?:.*/)?")
+				} else {
+					builder.WriteString(".*")
+				}
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q", glob)
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			i += end
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
+
+func addZipEntry(writer *zip.Writer, root string, path string, result *ArchiveResult) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	header.Method = zip.Deflate
+	dest, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	return copyArchiveFile(dest, path, result)
+}
+
+func addTarEntry(writer *tar.Writer, root string, path string, result *ArchiveResult) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+	return copyArchiveFile(writer, path, result)
+}
+
+func copyArchiveFile(dest io.Writer, path string, result *ArchiveResult) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	written, err := io.Copy(dest, source)
+	if err != nil {
+		return err
+	}
+	result.Entries++
+	result.Bytes += written
+	return nil
+}
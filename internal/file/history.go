@@ -0,0 +1,249 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultHistoryLimit = 20
+
+var ErrVersionNotFound = errors.New("version not found")
+
+// historyMu serializes access to every history store; stores are cheap
+// handles over shared on-disk state and may be created per request.
+var historyMu sync.Mutex
+
+type History struct {
+	root  string
+	limit int
+}
+
+type Version struct {
+	Version   int         `json:"version"`
+	Operation string      `json:"operation"`
+	Exists    bool        `json:"exists"`
+	Size      int64       `json:"size"`
+	Mode      os.FileMode `json:"mode,omitempty"`
+	SHA256    string      `json:"sha256,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type historyIndex struct {
+	Path     string    `json:"path"`
+	Next     int       `json:"next"`
+	Versions []Version `json:"versions"`
+}
+
+func NewHistory(root string, limit int) *History {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	return &History{root: root, limit: limit}
+}
+
+// Apply runs mutate while holding the history lock and records the prior
+// content of path only if mutate succeeded and changed the file.
+func (history *History) Apply(path string, operation string, mutate func() error) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	before, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if err := mutate(); err != nil {
+		return err
+	}
+	after, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if after.exists == before.exists && bytes.Equal(after.content, before.content) {
+		return nil
+	}
+	dir := history.dirFor(path)
+	index, err := history.loadIndex(dir, path)
+	if err != nil {
+		return err
+	}
+	_, err = history.storeLocked(dir, &index, operation, before)
+	return err
+}
+
+func (history *History) List(path string) ([]Version, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	index, err := history.loadIndex(history.dirFor(path), path)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Version, 0, len(index.Versions))
+	for i := len(index.Versions) - 1; i >= 0; i-- {
+		results = append(results, index.Versions[i])
+	}
+	return results, nil
+}
+
+// Restore rolls path back to version. The content being replaced is recorded
+// first, so a restore can itself be undone.
+func (history *History) Restore(path string, version int) (Version, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	dir := history.dirFor(path)
+	index, err := history.loadIndex(dir, path)
+	if err != nil {
+		return Version{}, err
+	}
+	var target *Version
+	for i := range index.Versions {
+		if index.Versions[i].Version == version {
+			target = &index.Versions[i]
+			break
+		}
+	}
+	if target == nil {
+		return Version{}, ErrVersionNotFound
+	}
+	restored := *target
+
+	var content []byte
+	if restored.Exists {
+		content, err = os.ReadFile(history.blobPath(dir, restored.Version))
+		if err != nil {
+			return Version{}, err
+		}
+	}
+	if _, err := history.recordLocked(dir, &index, path, "restore"); err != nil {
+		return Version{}, err
+	}
+
+	if !restored.Exists {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Version{}, err
+		}
+		return restored, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Version{}, err
+	}
+	mode := restored.Mode.Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return Version{}, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return Version{}, err
+	}
+	return restored, nil
+}
+
+type snapshot struct {
+	content []byte
+	exists  bool
+	mode    os.FileMode
+}
+
+func readSnapshot(path string) (snapshot, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot{}, nil
+	}
+	if err != nil {
+		return snapshot{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot{}, err
+	}
+	return snapshot{content: content, exists: true, mode: info.Mode().Perm()}, nil
+}
+
+func (history *History) recordLocked(dir string, index *historyIndex, path string, operation string) (Version, error) {
+	current, err := readSnapshot(path)
+	if err != nil {
+		return Version{}, err
+	}
+	return history.storeLocked(dir, index, operation, current)
+}
+
+func (history *History) storeLocked(dir string, index *historyIndex, operation string, current snapshot) (Version, error) {
+	index.Next++
+	version := Version{
+		Version:   index.Next,
+		Operation: operation,
+		Exists:    current.exists,
+		Size:      int64(len(current.content)),
+		Mode:      current.mode,
+		CreatedAt: time.Now().UTC(),
+	}
+	if current.exists {
+		sum := sha256.Sum256(current.content)
+		version.SHA256 = hex.EncodeToString(sum[:])
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Version{}, err
+	}
+	if current.exists {
+		if err := os.WriteFile(history.blobPath(dir, version.Version), current.content, 0644); err != nil {
+			return Version{}, err
+		}
+	}
+	index.Versions = append(index.Versions, version)
+	for len(index.Versions) > history.limit {
+		_ = os.Remove(history.blobPath(dir, index.Versions[0].Version))
+		index.Versions = index.Versions[1:]
+	}
+	if err := history.saveIndex(dir, *index); err != nil {
+		return Version{}, err
+	}
+	return version, nil
+}
+
+func (history *History) dirFor(path string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(path)))
+	return filepath.Join(history.root, hex.EncodeToString(sum[:16]))
+}
+
+func (history *History) blobPath(dir string, version int) string {
+	return filepath.Join(dir, strconv.Itoa(version)+".bak")
+}
+
+func (history *History) loadIndex(dir string, path string) (historyIndex, error) {
+	payload, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return historyIndex{Path: filepath.Clean(path)}, nil
+	}
+	if err != nil {
+		return historyIndex{}, err
+	}
+	var index historyIndex
+	if err := json.Unmarshal(payload, &index); err != nil {
+		return historyIndex{}, err
+	}
+	return index, nil
+}
+
+func (history *History) saveIndex(dir string, index historyIndex) error {
+	payload, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "index.json.tmp")
+	if err := os.WriteFile(tmp, payload, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, "index.json"))
+}
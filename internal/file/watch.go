@@ -0,0 +1,252 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	WatchCreate = "create"
+	WatchModify = "modify"
+	WatchDelete = "delete"
+	WatchRename = "rename"
+
+	defaultWatchInterval = 250 * time.Millisecond
+	defaultWatchDebounce = 300 * time.Millisecond
+)
+
+type WatchOptions struct {
+	Recursive bool
+	Interval  time.Duration
+	Debounce  time.Duration
+	Ignore    []string
+}
+
+type WatchEvent struct {
+	Type    string    `json:"type"`
+	Path    string    `json:"path"`
+	OldPath string    `json:"old_path,omitempty"`
+	IsDir   bool      `json:"is_dir"`
+	Time    time.Time `json:"time"`
+}
+
+type watchEntry struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+type pendingChange struct {
+	kind  string
+	entry watchEntry
+}
+
+// Watch polls root and emits debounced batches of changes until ctx is done.
+// Polling keeps the watcher dependency-free and works the same on bind mounts,
+// where inotify events from the host are often missing.
+func Watch(ctx context.Context, root string, options WatchOptions) (<-chan []WatchEvent, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if options.Interval <= 0 {
+		options.Interval = defaultWatchInterval
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = defaultWatchDebounce
+	}
+	for _, pattern := range options.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New("invalid ignore pattern: " + pattern)
+		}
+	}
+
+	current := scanWatchTree(root, info.IsDir(), options)
+	events := make(chan []WatchEvent, 16)
+
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(options.Interval)
+		defer ticker.Stop()
+
+		pending := make(map[string]pendingChange)
+		var lastChange time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			next := scanWatchTree(root, info.IsDir(), options)
+			if diffWatchTrees(current, next, pending) {
+				lastChange = time.Now()
+			}
+			current = next
+
+			if len(pending) == 0 || time.Since(lastChange) < options.Debounce {
+				continue
+			}
+			batch := flushPending(pending)
+			pending = make(map[string]pendingChange)
+			if len(batch) == 0 {
+				continue
+			}
+			select {
+			case events <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func scanWatchTree(root string, isDir bool, options WatchOptions) map[string]watchEntry {
+	entries := make(map[string]watchEntry)
+	if !isDir {
+		if info, err := os.Stat(root); err == nil {
+			entries[root] = watchEntry{size: info.Size(), modTime: info.ModTime(), isDir: false}
+		}
+		return entries
+	}
+	_ = filepath.WalkDir(root, func(current string, d fs.DirEntry, err error) error {
+		if err != nil || current == root {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, current)
+		if relErr != nil {
+			return nil
+		}
+		if watchIgnored(filepath.ToSlash(rel), options.Ignore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil {
+			return nil
+		}
+		entries[current] = watchEntry{size: info.Size(), modTime: info.ModTime(), isDir: d.IsDir()}
+		if d.IsDir() && !options.Recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return entries
+}
+
+func watchIgnored(rel string, patterns []string) bool {
+	base := path.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func diffWatchTrees(previous, next map[string]watchEntry, pending map[string]pendingChange) bool {
+	changed := false
+	for name, entry := range next {
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			mergeChange(pending, name, WatchCreate, entry)
+			changed = true
+		case !entry.isDir && (old.size != entry.size || !old.modTime.Equal(entry.modTime)):
+			mergeChange(pending, name, WatchModify, entry)
+			changed = true
+		}
+	}
+	for name, entry := range previous {
+		if _, ok := next[name]; !ok {
+			mergeChange(pending, name, WatchDelete, entry)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// mergeChange coalesces a new change with one already pending for the same
+// path so a debounced batch reports the net effect only.
+func mergeChange(pending map[string]pendingChange, name, kind string, entry watchEntry) {
+	existing, ok := pending[name]
+	if !ok {
+		pending[name] = pendingChange{kind: kind, entry: entry}
+		return
+	}
+	switch {
+	case existing.kind == WatchCreate && kind == WatchDelete:
+		delete(pending, name)
+	case existing.kind == WatchCreate:
+		pending[name] = pendingChange{kind: WatchCreate, entry: entry}
+	case existing.kind == WatchDelete && kind == WatchCreate:
+		pending[name] = pendingChange{kind: WatchModify, entry: entry}
+	default:
+		pending[name] = pendingChange{kind: kind, entry: entry}
+	}
+}
+
+// flushPending turns pending changes into events, pairing a delete and a
+// create of the same size and modification time into a single rename.
+func flushPending(pending map[string]pendingChange) []WatchEvent {
+	now := time.Now().UTC()
+	var created, deleted []string
+	for name, change := range pending {
+		switch change.kind {
+		case WatchCreate:
+			created = append(created, name)
+		case WatchDelete:
+			deleted = append(deleted, name)
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(deleted)
+
+	renamed := make(map[string]bool)
+	var events []WatchEvent
+	for _, oldName := range deleted {
+		old := pending[oldName].entry
+		for _, newName := range created {
+			if renamed[newName] {
+				continue
+			}
+			entry := pending[newName].entry
+			if entry.isDir != old.isDir || entry.size != old.size || !entry.modTime.Equal(old.modTime) {
+				continue
+			}
+			renamed[newName] = true
+			renamed[oldName] = true
+			events = append(events, WatchEvent{Type: WatchRename, Path: newName, OldPath: oldName, IsDir: entry.isDir, Time: now})
+			break
+		}
+	}
+
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		if !renamed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		change := pending[name]
+		events = append(events, WatchEvent{Type: change.kind, Path: name, IsDir: change.entry.isDir, Time: now})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return strings.Compare(events[i].Path, events[j].Path) < 0
+	})
+	return events
+}
@@ -0,0 +1,725 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCommandTimeout = 30 * time.Second
+	CloneTimeout          = 5 * time.Minute
+	defaultLogLimit       = 20
+)
+
+var (
+	ErrUnavailable   = errors.New("git is not installed")
+	ErrNotRepository = errors.New("not a git repository")
+	ErrInvalidInput  = errors.New("invalid git input")
+)
+
+// CommandError carries the stderr of a git invocation that exited non-zero.
+type CommandError struct {
+	Args     []string
+	Stderr   string
+	ExitCode int
+}
+
+func (err *CommandError) Error() string {
+	message := strings.TrimSpace(err.Stderr)
+	if message == "" {
+		message = fmt.Sprintf("exit status %d", err.ExitCode)
+	}
+	return fmt.Sprintf("git %s: %s", strings.Join(err.Args, " "), message)
+}
+
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// DefaultAuthor is used for commits whose caller does not supply an author.
+// When both fields are empty, git falls back to its own configuration.
+func DefaultAuthor() Author {
+	return Author{
+		Name:  strings.TrimSpace(os.Getenv("SANDBOX_GIT_AUTHOR_NAME")),
+		Email: strings.TrimSpace(os.Getenv("SANDBOX_GIT_AUTHOR_EMAIL")),
+	}
+}
+
+type Repo struct {
+	dir      string
+	root     string
+	verified bool
+}
+
+// Open opens the repository at dir, which must be its top level or inside
+// it without git searching above dir.
+func Open(dir string) *Repo {
+	return OpenIn(dir, dir)
+}
+
+// OpenIn opens the repository containing dir. Discovery stops at root, and a
+// repository whose top level lies outside root is refused.
+func OpenIn(dir string, root string) *Repo {
+	return &Repo{dir: dir, root: root}
+}
+
+func (repo *Repo) Dir() string {
+	return repo.dir
+}
+
+func (repo *Repo) run(ctx context.Context, env []string, args ...string) (string, error) {
+	env = append([]string{"GIT_CEILING_DIRECTORIES=" + filepath.Dir(filepath.Clean(repo.root))}, env...)
+	if !repo.verified {
+		if err := repo.verify(ctx, env); err != nil {
+			return "", err
+		}
+		repo.verified = true
+	}
+	return runGit(ctx, repo.dir, defaultCommandTimeout, env, args...)
+}
+
+func (repo *Repo) verify(ctx context.Context, env []string) error {
+	output, err := runGit(ctx, repo.dir, defaultCommandTimeout, env, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	top := strings.TrimSpace(output)
+	root := filepath.Clean(repo.root)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, top)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: repository at %s is outside %s", ErrNotRepository, top, repo.root)
+	}
+	return nil
+}
+
+func runGit(ctx context.Context, dir string, timeout time.Duration, env []string, args ...string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", ErrUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Fixed options keep output parseable and stop git from prompting or
+	// running arbitrary transports on behalf of a remote caller.
+	fullArgs := append([]string{"-c", "core.quotepath=false", "-c", "color.ui=false", "-c", "protocol.ext.allow=never"}, args...)
+	cmd := exec.CommandContext(ctx, "git", fullArgs...)
+	cmd.Dir = dir
+	// The server's own GIT_DIR or GIT_WORK_TREE would point every command
+	// at a repository outside dir.
+	cmd.Env = slices.DeleteFunc(os.Environ(), func(entry string) bool {
+		return strings.HasPrefix(entry, "GIT_DIR=") || strings.HasPrefix(entry, "GIT_WORK_TREE=")
+	})
+	cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	cmd.Env = append(cmd.Env, env...)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if strings.Contains(stderr.String(), "not a git repository") {
+				return "", ErrNotRepository
+			}
+			return stdout.String(), &CommandError{Args: args, Stderr: stderr.String(), ExitCode: exitErr.ExitCode()}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+type Status struct {
+	Branch   string       `json:"branch"`
+	Commit   string       `json:"commit"`
+	Upstream string       `json:"upstream,omitempty"`
+	Ahead    int          `json:"ahead"`
+	Behind   int          `json:"behind"`
+	Clean    bool         `json:"clean"`
+	Files    []FileStatus `json:"files"`
+}
+
+// FileStatus uses git's single-letter codes for the index and worktree
+// columns ("M", "A", "D", "R", "C", "U", "?", "!" or "." for unchanged).
+type FileStatus struct {
+	Path       string `json:"path"`
+	OrigPath   string `json:"orig_path,omitempty"`
+	Index      string `json:"index"`
+	Worktree   string `json:"worktree"`
+	Staged     bool   `json:"staged"`
+	Untracked  bool   `json:"untracked"`
+	Conflicted bool   `json:"conflicted"`
+}
+
+func (repo *Repo) Status(ctx context.Context) (Status, error) {
+	output, err := repo.run(ctx, nil, "status", "--porcelain=v2", "--branch", "-z")
+	if err != nil {
+		return Status{}, err
+	}
+	return parseStatus(output), nil
+}
+
+func parseStatus(output string) Status {
+	status := Status{Files: []FileStatus{}}
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+		switch record[0] {
+		case '#':
+			fields := strings.Fields(record)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.oid":
+				if fields[2] != "(initial)" {
+					status.Commit = fields[2]
+				}
+			case "branch.head":
+				status.Branch = fields[2]
+			case "branch.upstream":
+				status.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) >= 4 {
+					status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+		case '1', '2', 'u':
+			fieldCount := map[byte]int{'1': 9, '2': 10, 'u': 11}[record[0]]
+			fields := strings.SplitN(record, " ", fieldCount)
+			if len(fields) < fieldCount {
+				continue
+			}
+			entry := FileStatus{
+				Path:       fields[fieldCount-1],
+				Index:      fields[1][:1],
+				Worktree:   fields[1][1:],
+				Conflicted: record[0] == 'u',
+			}
+			entry.Staged = entry.Index != "." && !entry.Conflicted
+			if record[0] == '2' && i+1 < len(records) {
+				i++
+				entry.OrigPath = records[i]
+			}
+			status.Files = append(status.Files, entry)
+		case '?':
+			status.Files = append(status.Files, FileStatus{Path: record[2:], Index: "?", Worktree: "?", Untracked: true})
+		}
+	}
+	status.Clean = len(status.Files) == 0
+	return status
+}
+
+type DiffOptions struct {
+	Staged  bool
+	Ref     string
+	Paths   []string
+	Context int
+}
+
+type FileDiff struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Binary    bool   `json:"binary"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Hunks     []Hunk `json:"hunks"`
+}
+
+type Hunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Header   string     `json:"header"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+type DiffLine struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+func (repo *Repo) Diff(ctx context.Context, options DiffOptions) ([]FileDiff, error) {
+	args := []string{"diff", "--no-ext-diff", "--find-renames"}
+	if options.Context > 0 {
+		args = append(args, "-U"+strconv.Itoa(options.Context))
+	}
+	if options.Staged {
+		args = append(args, "--cached")
+	}
+	if options.Ref != "" {
+		if strings.HasPrefix(options.Ref, "-") {
+			return nil, fmt.Errorf("%w: ref %q", ErrInvalidInput, options.Ref)
+		}
+		args = append(args, options.Ref)
+	}
+	args = append(args, "--")
+	args = append(args, options.Paths...)
+
+	output, err := repo.run(ctx, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(output), nil
+}
+
+func parseDiff(output string) []FileDiff {
+	files := []FileDiff{}
+	var current *FileDiff
+	var hunk *Hunk
+	oldLine, newLine := 0, 0
+
+	flush := func() {
+		if current != nil {
+			files = append(files, *current)
+		}
+		current = nil
+		hunk = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &FileDiff{Status: "modified", Hunks: []Hunk{}}
+			current.OldPath, current.Path = splitDiffHeader(strings.TrimPrefix(line, "diff --git "))
+		case current == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case hunk == nil && strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case hunk == nil && strings.HasPrefix(line, "rename from "):
+			current.Status = "renamed"
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+		case hunk == nil && strings.HasPrefix(line, "rename to "):
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case hunk == nil && strings.HasPrefix(line, "Binary files "):
+			current.Binary = true
+		case hunk == nil && strings.HasPrefix(line, "--- "):
+			if name := strings.TrimPrefix(line, "--- "); name != "/dev/null" {
+				current.OldPath = strings.TrimPrefix(name, "a/")
+			}
+		case hunk == nil && strings.HasPrefix(line, "+++ "):
+			if name := strings.TrimPrefix(line, "+++ "); name != "/dev/null" {
+				current.Path = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			current.Hunks = append(current.Hunks, parseHunkHeader(line))
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+		case hunk != nil && strings.HasPrefix(line, "+"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: "add", Content: line[1:], NewLine: newLine})
+			current.Additions++
+			newLine++
+		case hunk != nil && strings.HasPrefix(line, "-"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: "delete", Content: line[1:], OldLine: oldLine})
+			current.Deletions++
+			oldLine++
+		case hunk != nil && strings.HasPrefix(line, " "):
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: "context", Content: line[1:], OldLine: oldLine, NewLine: newLine})
+			oldLine++
+			newLine++
+		}
+	}
+	flush()
+
+	for i := range files {
+		if files[i].Status != "renamed" && files[i].OldPath == files[i].Path {
+			files[i].OldPath = ""
+		}
+		if files[i].Status == "deleted" && files[i].Path == "" {
+			files[i].Path = files[i].OldPath
+		}
+	}
+	return files
+}
+
+func splitDiffHeader(header string) (string, string) {
+	// "a/<old> b/<new>"; paths with " b/" inside are corrected later by the
+	// ---/+++ or rename lines.
+	index := strings.Index(header, " b/")
+	if index < 0 {
+		return "", ""
+	}
+	return strings.TrimPrefix(header[:index], "a/"), header[index+3:]
+}
+
+func parseHunkHeader(line string) Hunk {
+	hunk := Hunk{Lines: []DiffLine{}}
+	end := strings.Index(line[3:], " @@")
+	if end < 0 {
+		return hunk
+	}
+	ranges := strings.Fields(line[3 : 3+end])
+	hunk.Header = strings.TrimSpace(line[3+end+3:])
+	if len(ranges) == 2 {
+		hunk.OldStart, hunk.OldLines = parseRange(strings.TrimPrefix(ranges[0], "-"))
+		hunk.NewStart, hunk.NewLines = parseRange(strings.TrimPrefix(ranges[1], "+"))
+	}
+	return hunk
+}
+
+func parseRange(value string) (int, int) {
+	start, count, found := strings.Cut(value, ",")
+	startValue, _ := strconv.Atoi(start)
+	if !found {
+		return startValue, 1
+	}
+	countValue, _ := strconv.Atoi(count)
+	return startValue, countValue
+}
+
+type LogOptions struct {
+	Ref   string
+	Path  string
+	Limit int
+}
+
+type Commit struct {
+	Hash      string    `json:"hash"`
+	ShortHash string    `json:"short_hash"`
+	Author    Author    `json:"author"`
+	Date      time.Time `json:"date"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body,omitempty"`
+	Parents   []string  `json:"parents"`
+}
+
+func (repo *Repo) Log(ctx context.Context, options LogOptions) ([]Commit, error) {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = defaultLogLimit
+	}
+	args := []string{"log", "-n", strconv.Itoa(limit), "--format=%H%x1f%h%x1f%an%x1f%ae%x1f%aI%x1f%P%x1f%s%x1f%b%x1e"}
+	if options.Ref != "" {
+		if strings.HasPrefix(options.Ref, "-") {
+			return nil, fmt.Errorf("%w: ref %q", ErrInvalidInput, options.Ref)
+		}
+		args = append(args, options.Ref)
+	}
+	args = append(args, "--")
+	if options.Path != "" {
+		args = append(args, options.Path)
+	}
+
+	output, err := repo.run(ctx, nil, args...)
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "does not have any commits") {
+			return []Commit{}, nil
+		}
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		fields := strings.Split(record, "\x1f")
+		if len(fields) < 8 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[4])
+		parents := strings.Fields(fields[5])
+		if parents == nil {
+			parents = []string{}
+		}
+		commits = append(commits, Commit{
+			Hash:      fields[0],
+			ShortHash: fields[1],
+			Author:    Author{Name: fields[2], Email: fields[3]},
+			Date:      date,
+			Parents:   parents,
+			Subject:   fields[6],
+			Body:      strings.TrimSpace(fields[7]),
+		})
+	}
+	return commits, nil
+}
+
+type Branch struct {
+	Name     string `json:"name"`
+	Commit   string `json:"commit"`
+	Current  bool   `json:"current"`
+	Remote   bool   `json:"remote"`
+	Upstream string `json:"upstream,omitempty"`
+}
+
+func (repo *Repo) Branches(ctx context.Context) ([]Branch, error) {
+	output, err := repo.run(ctx, nil, "for-each-ref", "--format=%(HEAD)%1f%(refname)%1f%(objectname)%1f%(upstream:short)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	branches := []Branch{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) < 4 {
+			continue
+		}
+		branch := Branch{Current: fields[0] == "*", Commit: fields[2], Upstream: fields[3]}
+		switch {
+		case strings.HasPrefix(fields[1], "refs/heads/"):
+			branch.Name = strings.TrimPrefix(fields[1], "refs/heads/")
+		case strings.HasPrefix(fields[1], "refs/remotes/"):
+			branch.Name = strings.TrimPrefix(fields[1], "refs/remotes/")
+			branch.Remote = true
+			if strings.HasSuffix(branch.Name, "/HEAD") {
+				continue
+			}
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+func (repo *Repo) CreateBranch(ctx context.Context, name string, startPoint string) error {
+	if err := validateRefArg(name); err != nil {
+		return err
+	}
+	args := []string{"branch", name}
+	if startPoint != "" {
+		if err := validateRefArg(startPoint); err != nil {
+			return err
+		}
+		args = append(args, startPoint)
+	}
+	_, err := repo.run(ctx, nil, args...)
+	return err
+}
+
+func (repo *Repo) DeleteBranch(ctx context.Context, name string, force bool) error {
+	if err := validateRefArg(name); err != nil {
+		return err
+	}
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := repo.run(ctx, nil, "branch", flag, name)
+	return err
+}
+
+func (repo *Repo) Checkout(ctx context.Context, ref string, create bool) error {
+	if err := validateRefArg(ref); err != nil {
+		return err
+	}
+	args := []string{"checkout"}
+	if create {
+		args = append(args, "-b")
+	}
+	args = append(args, ref, "--")
+	_, err := repo.run(ctx, nil, args...)
+	return err
+}
+
+// Add stages paths, or every change in the worktree when all is set.
+func (repo *Repo) Add(ctx context.Context, paths []string, all bool) error {
+	if all {
+		_, err := repo.run(ctx, nil, "add", "--all")
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("%w: paths are required", ErrInvalidInput)
+	}
+	_, err := repo.run(ctx, nil, append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// authorEnv fills unset fields from DefaultAuthor; anything still empty is
+// left to git's own configuration.
+func authorEnv(author Author) []string {
+	defaults := DefaultAuthor()
+	if author.Name == "" {
+		author.Name = defaults.Name
+	}
+	if author.Email == "" {
+		author.Email = defaults.Email
+	}
+	var env []string
+	if author.Name != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+author.Name, "GIT_COMMITTER_NAME="+author.Name)
+	}
+	if author.Email != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+author.Email, "GIT_COMMITTER_EMAIL="+author.Email)
+	}
+	return env
+}
+
+type CommitOptions struct {
+	Message    string
+	Author     Author
+	All        bool
+	AllowEmpty bool
+}
+
+func (repo *Repo) Commit(ctx context.Context, options CommitOptions) (Commit, error) {
+	if strings.TrimSpace(options.Message) == "" {
+		return Commit{}, fmt.Errorf("%w: message is required", ErrInvalidInput)
+	}
+	args := []string{"commit", "--no-verify", "-m", options.Message}
+	if options.All {
+		args = append(args, "--all")
+	}
+	if options.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+
+	if _, err := repo.run(ctx, authorEnv(options.Author), args...); err != nil {
+		return Commit{}, err
+	}
+	commits, err := repo.Log(ctx, LogOptions{Limit: 1})
+	if err != nil {
+		return Commit{}, err
+	}
+	if len(commits) == 0 {
+		return Commit{}, errors.New("commit not found after commit")
+	}
+	return commits[0], nil
+}
+
+type StashOptions struct {
+	Action           string
+	Message          string
+	IncludeUntracked bool
+	Index            int
+}
+
+type StashEntry struct {
+	Index   int    `json:"index"`
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// Stash runs push (the default), pop, apply, drop or list and returns the
+// stash list afterwards.
+func (repo *Repo) Stash(ctx context.Context, options StashOptions) ([]StashEntry, error) {
+	ref := "stash@{" + strconv.Itoa(options.Index) + "}"
+	var args []string
+	switch options.Action {
+	case "", "push":
+		args = []string{"stash", "push"}
+		if options.IncludeUntracked {
+			args = append(args, "--include-untracked")
+		}
+		if options.Message != "" {
+			args = append(args, "-m", options.Message)
+		}
+	case "pop", "apply", "drop":
+		args = []string{"stash", options.Action, ref}
+	case "list":
+	default:
+		return nil, fmt.Errorf("%w: stash action %q", ErrInvalidInput, options.Action)
+	}
+	if args != nil {
+		// Stashing records commits, so it needs an identity too.
+		if _, err := repo.run(ctx, authorEnv(Author{}), args...); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := repo.run(ctx, nil, "stash", "list", "--format=%gd%x1f%gs")
+	if err != nil {
+		return nil, err
+	}
+	entries := []StashEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) < 2 {
+			continue
+		}
+		entries = append(entries, StashEntry{Index: len(entries), Ref: fields[0], Message: fields[1]})
+	}
+	return entries, nil
+}
+
+type CloneOptions struct {
+	URL    string
+	Dir    string
+	Branch string
+	Depth  int
+}
+
+// Clone clones URL into Dir, which must not exist or be empty.
+func Clone(ctx context.Context, options CloneOptions) (*Repo, error) {
+	if strings.TrimSpace(options.URL) == "" {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidInput)
+	}
+	if err := validateCloneURL(options.URL); err != nil {
+		return nil, err
+	}
+	parent := filepath.Dir(options.Dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-c", "protocol.file.allow=never", "clone"}
+	if options.Branch != "" {
+		if err := validateRefArg(options.Branch); err != nil {
+			return nil, err
+		}
+		args = append(args, "--branch", options.Branch)
+	}
+	if options.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(options.Depth))
+	}
+	args = append(args, "--", options.URL, options.Dir)
+	if _, err := runGit(ctx, parent, CloneTimeout, nil, args...); err != nil {
+		return nil, err
+	}
+	return Open(options.Dir), nil
+}
+
+// validateCloneURL only admits remote URLs. Local paths and file:// URLs
+// would copy any repository on the host into the workspace.
+func validateCloneURL(raw string) error {
+	lower := strings.ToLower(raw)
+	if strings.HasPrefix(raw, "-") || strings.HasPrefix(lower, "ext::") {
+		return fmt.Errorf("%w: url %q", ErrInvalidInput, raw)
+	}
+	if scheme, _, ok := strings.Cut(lower, "://"); ok {
+		switch scheme {
+		case "https", "http", "ssh", "git", "git+ssh", "ssh+git":
+			return nil
+		}
+		return fmt.Errorf("%w: unsupported url scheme %q", ErrInvalidInput, scheme)
+	}
+	// scp-like syntax: host:path, where the colon comes before any slash.
+	colon := strings.Index(raw, ":")
+	slash := strings.Index(raw, "/")
+	if colon > 1 && (slash < 0 || colon < slash) {
+		return nil
+	}
+	return fmt.Errorf("%w: local paths cannot be cloned", ErrInvalidInput)
+}
+
+func validateRefArg(ref string) error {
+	if strings.TrimSpace(ref) == "" {
+		return fmt.Errorf("%w: ref is required", ErrInvalidInput)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("%w: ref %q", ErrInvalidInput, ref)
+	}
+	return nil
+}
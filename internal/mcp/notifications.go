@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+	MethodResourceUpdated      = "notifications/resources/updated"
+)
+
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type ResourceSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// notifySession is a connection that can receive notifications. Resource
+// subscriptions belong to the session that made them and end with it.
+type notifySession struct {
+	sink          func(Notification)
+	subscriptions map[string]context.CancelFunc
+}
+
+type sessionKey struct{}
+
+type ResourceUpdatedParams struct {
+	URI    string `json:"uri"`
+	Events any    `json:"events,omitempty"`
+}
+
+// ResourceWatcher validates uri, then reports changes through notify until
+// ctx is cancelled. It must not block past validation.
+type ResourceWatcher func(ctx context.Context, uri string, notify func(ResourceUpdatedParams)) error
+
+func (server *Server) SetResourceWatcher(watcher ResourceWatcher) {
+	server.notifyMu.Lock()
+	defer server.notifyMu.Unlock()
+	server.watcher = watcher
+}
+
+func (server *Server) resourcesSupported() bool {
+	server.notifyMu.Lock()
+	defer server.notifyMu.Unlock()
+	return server.watcher != nil
+}
+
+func (server *Server) handleResourcesSubscribe(ctx context.Context, req Request) Response {
+	params, errResp := parseResourceParams(req)
+	if errResp != nil {
+		return *errResp
+	}
+	id, hasSession := ctx.Value(sessionKey{}).(int)
+
+	server.notifyMu.Lock()
+	watcher := server.watcher
+	if watcher == nil {
+		server.notifyMu.Unlock()
+		detail := NewMethodNotFoundDetail("resources not supported")
+		return NewErrorResponse(req.ID, ErrMethodNotFound, "method not found", detail)
+	}
+	session, ok := server.sessions[id]
+	if !hasSession || !ok {
+		server.notifyMu.Unlock()
+		detail := NewErrorDetail(KindInvalidRequest, "subscriptions need a streaming connection (stdio or /mcp/stream)", KindInvalidRequest)
+		return NewErrorResponse(req.ID, ErrInvalidRequest, "invalid request", detail)
+	}
+	if _, ok := session.subscriptions[params.URI]; ok {
+		server.notifyMu.Unlock()
+		return NewSuccessResponse(req.ID, map[string]any{})
+	}
+	watchCtx, cancel := context.WithCancel(context.Background())
+	session.subscriptions[params.URI] = cancel
+	server.notifyMu.Unlock()
+
+	err := watcher(watchCtx, params.URI, func(update ResourceUpdatedParams) {
+		server.notify(id, Notification{
+			JSONRPC: JSONRPCVersion,
+			Method:  MethodResourceUpdated,
+			Params:  update,
+		})
+	})
+	if err != nil {
+		server.notifyMu.Lock()
+		delete(session.subscriptions, params.URI)
+		server.notifyMu.Unlock()
+		cancel()
+		detail := NewInvalidParamsDetail(err.Error())
+		return NewErrorResponse(req.ID, ErrInvalidParams, "invalid params", detail)
+	}
+	return NewSuccessResponse(req.ID, map[string]any{})
+}
+
+func (server *Server) handleResourcesUnsubscribe(ctx context.Context, req Request) Response {
+	params, errResp := parseResourceParams(req)
+	if errResp != nil {
+		return *errResp
+	}
+	var cancel context.CancelFunc
+	server.notifyMu.Lock()
+	if id, ok := ctx.Value(sessionKey{}).(int); ok {
+		if session, ok := server.sessions[id]; ok {
+			cancel = session.subscriptions[params.URI]
+			delete(session.subscriptions, params.URI)
+		}
+	}
+	server.notifyMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return NewSuccessResponse(req.ID, map[string]any{})
+}
+
+func parseResourceParams(req Request) (ResourceSubscribeParams, *Response) {
+	var params ResourceSubscribeParams
+	if len(req.Params) == 0 || json.Unmarshal(req.Params, &params) != nil {
+		resp := NewErrorResponse(req.ID, ErrInvalidParams, "invalid params", NewInvalidParamsDetail("invalid params"))
+		return params, &resp
+	}
+	params.URI = strings.TrimSpace(params.URI)
+	if params.URI == "" {
+		resp := NewErrorResponse(req.ID, ErrInvalidParams, "invalid params", NewInvalidParamsDetail("uri is required"))
+		return params, &resp
+	}
+	return params, nil
+}
+
+// notify delivers to session id unless it has closed in the meantime.
+func (server *Server) notify(id int, notification Notification) {
+	server.notifyMu.Lock()
+	session, ok := server.sessions[id]
+	server.notifyMu.Unlock()
+	if ok {
+		session.sink(notification)
+	}
+}
+
+// openSession registers a connection that receives notifications through
+// sink. Requests handled with the returned context subscribe on its behalf;
+// closing it cancels those subscriptions.
+func (server *Server) openSession(ctx context.Context, sink func(Notification)) (context.Context, func()) {
+	server.notifyMu.Lock()
+	server.nextSession++
+	id := server.nextSession
+	server.sessions[id] = &notifySession{sink: sink, subscriptions: make(map[string]context.CancelFunc)}
+	server.notifyMu.Unlock()
+	return context.WithValue(ctx, sessionKey{}, id), func() {
+		server.notifyMu.Lock()
+		session := server.sessions[id]
+		delete(server.sessions, id)
+		server.notifyMu.Unlock()
+		for _, cancel := range session.subscriptions {
+			cancel()
+		}
+	}
+}
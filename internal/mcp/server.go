@@ -8,12 +8,18 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 )
 
 type Server struct {
 	registry *Registry
 	auth     *Authenticator
 	authErr  error
+
+	notifyMu    sync.Mutex
+	watcher     ResourceWatcher
+	sessions    map[int]*notifySession
+	nextSession int
 }
 
 func NewServer(registry *Registry, auth *Authenticator, authErr error) *Server {
@@ -21,6 +27,7 @@ func NewServer(registry *Registry, auth *Authenticator, authErr error) *Server {
 		registry: registry,
 		auth:     auth,
 		authErr:  authErr,
+		sessions: make(map[int]*notifySession),
 	}
 }
 
@@ -42,6 +49,10 @@ func (server *Server) HandleRequest(ctx context.Context, req Request) Response {
 		return server.handleToolsCall(ctx, req)
 	case MethodCapabilities:
 		return NewSuccessResponse(req.ID, BuildCapabilities(server.registry))
+	case MethodResourcesSubscribe:
+		return server.handleResourcesSubscribe(ctx, req)
+	case MethodResourcesUnsubscribe:
+		return server.handleResourcesUnsubscribe(ctx, req)
 	}
 	tool, ok := server.registry.Get(req.Method)
 	if !ok || tool.Handler == nil {
@@ -59,13 +70,17 @@ func (server *Server) handleInitialize(req Request) Response {
 	} else if ok {
 		version = parsed
 	}
+	capabilities := InitializeCapabilities{
+		Tools: &InitializeToolsCapabilities{
+			ListChanged: false,
+		},
+	}
+	if server.resourcesSupported() {
+		capabilities.Resources = &InitializeResourcesCapabilities{Subscribe: true}
+	}
 	return NewSuccessResponse(req.ID, InitializeResult{
 		ProtocolVersion: version,
-		Capabilities: InitializeCapabilities{
-			Tools: &InitializeToolsCapabilities{
-				ListChanged: false,
-			},
-		},
+		Capabilities:    capabilities,
 		ServerInfo: InitializeServerInfo{
 			Name:    ServerName,
 			Version: ServerVersion,
@@ -157,7 +172,12 @@ func newToolCallResult(result any) ToolCallResult {
 
 func (server *Server) ServeStdio(r io.Reader, w io.Writer) error {
 	decoder := json.NewDecoder(bufio.NewReader(r))
-	encoder := json.NewEncoder(w)
+	encoder := &lockedEncoder{encoder: json.NewEncoder(w)}
+
+	ctx, closeSession := server.openSession(context.Background(), func(notification Notification) {
+		_ = encoder.Encode(notification)
+	})
+	defer closeSession()
 
 	for {
 		var raw json.RawMessage
@@ -187,7 +207,7 @@ func (server *Server) ServeStdio(r io.Reader, w io.Writer) error {
 			continue
 		}
 
-		resp := server.HandleRequest(context.Background(), parsed)
+		resp := server.HandleRequest(ctx, parsed)
 		if isNotification(parsed.ID) {
 			continue
 		}
@@ -249,6 +269,16 @@ func (server *Server) ServeStreamableHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Notifications are interleaved with responses while the request body
+	// stays open, so writes to w are serialized.
+	var writeMu sync.Mutex
+	ctx, closeSession := server.openSession(r.Context(), func(notification Notification) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = writeNDJSONMessage(w, notification)
+	})
+	defer closeSession()
+
 	scanner := bufio.NewScanner(r.Body)
 	scanner.Buffer(make([]byte, 0, 1024), 1024*1024)
 	for scanner.Scan() {
@@ -256,11 +286,14 @@ func (server *Server) ServeStreamableHTTP(w http.ResponseWriter, r *http.Request
 		if line == "" {
 			continue
 		}
-		resp, notify := server.handleRawPayload(r.Context(), []byte(line))
+		resp, notify := server.handleRawPayload(ctx, []byte(line))
 		if notify {
 			continue
 		}
-		if err := writeNDJSONMessage(w, resp); err != nil {
+		writeMu.Lock()
+		err := writeNDJSONMessage(w, resp)
+		writeMu.Unlock()
+		if err != nil {
 			return
 		}
 	}
@@ -360,8 +393,8 @@ func writeNDJSONHeaders(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func writeNDJSONMessage(w io.Writer, resp Response) error {
-	payload, _ := json.Marshal(resp)
+func writeNDJSONMessage(w io.Writer, message any) error {
+	payload, _ := json.Marshal(message)
 	if _, err := w.Write(payload); err != nil {
 		return err
 	}
@@ -391,3 +424,14 @@ func isNDJSONRequest(r *http.Request) bool {
 	contentType := strings.ToLower(r.Header.Get("Content-Type"))
 	return strings.Contains(contentType, "ndjson") || strings.Contains(contentType, "jsonl")
 }
+
+type lockedEncoder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (encoder *lockedEncoder) Encode(value any) error {
+	encoder.mu.Lock()
+	defer encoder.mu.Unlock()
+	return encoder.encoder.Encode(value)
+}
@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 
 	"open-sandbox/internal/browser"
@@ -9,16 +11,43 @@ import (
 )
 
 type browserNavigateParams struct {
-	URL string `json:"url"`
+	URL       string `json:"url"`
+	WaitUntil string `json:"wait_until"`
+	TimeoutMS int    `json:"timeout_ms"`
 }
 
 type browserScreenshotParams struct {
-	Path string `json:"path"`
+	browser.Locator
+	Path     string            `json:"path"`
+	Format   string            `json:"format"`
+	Quality  int               `json:"quality"`
+	FullPage bool              `json:"full_page"`
+	Clip     *browser.ClipRect `json:"clip"`
+	Scale    float64           `json:"scale"`
+	Marks    bool              `json:"marks"`
+}
+
+type browserPDFParams struct {
+	Path              string             `json:"path"`
+	Format            string             `json:"format"`
+	Width             float64            `json:"width"`
+	Height            float64            `json:"height"`
+	Margin            *browser.PDFMargin `json:"margin"`
+	Landscape         bool               `json:"landscape"`
+	PageRanges        string             `json:"page_ranges"`
+	HeaderTemplate    string             `json:"header_template"`
+	FooterTemplate    string             `json:"footer_template"`
+	PrintBackground   bool               `json:"print_background"`
+	Scale             float64            `json:"scale"`
+	PreferCSSPageSize bool               `json:"prefer_css_page_size"`
 }
 
 type browserClickParams struct {
-	X float64 `json:"x"`
-	Y float64 `json:"y"`
+	browser.Locator
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Button     string  `json:"button"`
+	ClickCount int     `json:"click_count"`
 }
 
 type browserFormInputFillParams struct {
@@ -44,12 +73,20 @@ type browserNewTabParams struct {
 	URL string `json:"url"`
 }
 
-type browserSwitchTabParams struct {
-	Index int `json:"index"`
+// browserTabParams selects a tab by id, or by index for older clients.
+type browserTabParams struct {
+	ID    browser.TabRef `json:"id"`
+	Index *int           `json:"index"`
 }
 
-type browserCloseTabParams struct {
-	Index int `json:"index"`
+func (params browserTabParams) ref() (browser.TabRef, *mcp.ErrorDetail) {
+	switch {
+	case params.ID != "":
+		return params.ID, nil
+	case params.Index != nil:
+		return browser.TabAt(*params.Index), nil
+	}
+	return "", invalidParams("id or index is required")
 }
 
 type browserPressKeyParams struct {
@@ -68,19 +105,72 @@ func BrowserNavigate(service *browser.Service) mcp.ToolHandler {
 		if payload.URL == "" {
 			return nil, invalidParams("url is required")
 		}
-		if err := service.Navigate(payload.URL); err != nil {
+		if payload.WaitUntil != "" {
+			options := browser.NavigateOptions{WaitUntil: payload.WaitUntil, Timeout: millis(payload.TimeoutMS)}
+			if err := service.NavigateWithOptions(payload.URL, options); err != nil {
+				return nil, browserFailure(err)
+			}
+		} else if err := service.Navigate(payload.URL); err != nil {
 			return nil, toolFailure(err.Error())
 		}
 		return map[string]any{"navigated": true}, nil
 	}
 }
 
+// BrowserScreenshot returns the image as an image content block so the
+// model can look at it directly; path additionally saves it.
 func BrowserScreenshot(service *browser.Service) mcp.ToolHandler {
 	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
 		if service == nil {
 			return nil, toolFailure("browser service unavailable")
 		}
 		var payload browserScreenshotParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		if payload.Path != "" {
+			path, errDetail := resolveWorkspacePath(payload.Path)
+			if errDetail != nil {
+				return nil, errDetail
+			}
+			payload.Path = path
+		}
+		result, err := service.CaptureScreenshot(browser.ScreenshotOptions{
+			Path:     payload.Path,
+			Format:   payload.Format,
+			Quality:  payload.Quality,
+			FullPage: payload.FullPage,
+			Clip:     payload.Clip,
+			Element:  payload.Locator,
+			Scale:    payload.Scale,
+			Marks:    payload.Marks,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		summary, err := json.Marshal(result)
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return mcp.ToolCallResult{
+			Content: []mcp.ContentBlock{
+				{Type: "text", Text: string(summary)},
+				{Type: "image", Data: base64.StdEncoding.EncodeToString(result.Data), MimeType: result.MimeType},
+			},
+			StructuredContent: result,
+			Result:            result,
+		}, nil
+	}
+}
+
+func BrowserPDF(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserPDFParams
 		if err := json.Unmarshal(params, &payload); err != nil {
 			return nil, invalidParams("invalid params")
 		}
@@ -88,10 +178,24 @@ func BrowserScreenshot(service *browser.Service) mcp.ToolHandler {
 		if errDetail != nil {
 			return nil, errDetail
 		}
-		if err := service.Screenshot(path); err != nil {
-			return nil, toolFailure(err.Error())
+		result, err := service.PrintToPDF(browser.PDFOptions{
+			Path:              path,
+			Format:            payload.Format,
+			Width:             payload.Width,
+			Height:            payload.Height,
+			Margin:            payload.Margin,
+			Landscape:         payload.Landscape,
+			PageRanges:        payload.PageRanges,
+			HeaderTemplate:    payload.HeaderTemplate,
+			FooterTemplate:    payload.FooterTemplate,
+			PrintBackground:   payload.PrintBackground,
+			Scale:             payload.Scale,
+			PreferCSSPageSize: payload.PreferCSSPageSize,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
 		}
-		return map[string]any{"path": path}, nil
+		return result, nil
 	}
 }
 
@@ -104,7 +208,14 @@ func BrowserClick(service *browser.Service) mcp.ToolHandler {
 		if err := json.Unmarshal(params, &payload); err != nil {
 			return nil, invalidParams("invalid params")
 		}
-		if err := service.Click(payload.X, payload.Y); err != nil {
+		if !payload.Locator.IsZero() {
+			options := browser.ClickOptions{Button: payload.Button, Count: payload.ClickCount}
+			if err := service.ClickElement(payload.Locator, options); err != nil {
+				return nil, browserFailure(err)
+			}
+			return map[string]any{"clicked": true}, nil
+		}
+		if err := service.ClickAt(payload.X, payload.Y, cmp.Or(payload.Button, "left"), payload.ClickCount); err != nil {
 			return nil, toolFailure(err.Error())
 		}
 		return map[string]any{"clicked": true}, nil
@@ -196,11 +307,11 @@ func BrowserNewTab(service *browser.Service) mcp.ToolHandler {
 				return nil, invalidParams("invalid params")
 			}
 		}
-		index, err := service.NewTab(payload.URL)
+		tab, err := service.NewTab(payload.URL)
 		if err != nil {
 			return nil, toolFailure(err.Error())
 		}
-		return map[string]any{"index": index}, nil
+		return map[string]any{"id": tab.ID, "index": tab.Index}, nil
 	}
 }
 
@@ -209,14 +320,19 @@ func BrowserSwitchTab(service *browser.Service) mcp.ToolHandler {
 		if service == nil {
 			return nil, toolFailure("browser service unavailable")
 		}
-		var payload browserSwitchTabParams
+		var payload browserTabParams
 		if err := json.Unmarshal(params, &payload); err != nil {
 			return nil, invalidParams("invalid params")
 		}
-		if err := service.SwitchTab(payload.Index); err != nil {
-			return nil, toolFailure(err.Error())
+		ref, errDetail := payload.ref()
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		tab, err := service.SwitchTab(ref)
+		if err != nil {
+			return nil, browserFailure(err)
 		}
-		return map[string]any{"index": payload.Index}, nil
+		return map[string]any{"id": tab.ID, "index": tab.Index}, nil
 	}
 }
 
@@ -238,12 +354,16 @@ func BrowserCloseTab(service *browser.Service) mcp.ToolHandler {
 		if service == nil {
 			return nil, toolFailure("browser service unavailable")
 		}
-		var payload browserCloseTabParams
+		var payload browserTabParams
 		if err := json.Unmarshal(params, &payload); err != nil {
 			return nil, invalidParams("invalid params")
 		}
-		if err := service.CloseTab(payload.Index); err != nil {
-			return nil, toolFailure(err.Error())
+		ref, errDetail := payload.ref()
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		if err := service.CloseTab(ref); err != nil {
+			return nil, browserFailure(err)
 		}
 		return map[string]any{"closed": true}, nil
 	}
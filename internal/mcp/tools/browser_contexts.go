@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserContextParams struct {
+	BrowserID string `json:"browser_id"`
+	Context   string `json:"context"`
+}
+
+type browserContextCreateParams struct {
+	Name               string `json:"name"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+}
+
+type browserContextCloseParams struct {
+	Name string `json:"name"`
+}
+
+// InBrowserContext runs the tool built by factory against the browser
+// context named by the call's "browser_id" and "context" arguments, or the
+// default context of the default browser.
+func InBrowserContext(service *browser.Service, factory func(*browser.Service) mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return factory(nil)(ctx, params)
+		}
+		var payload browserContextParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		instance, err := service.Browser(payload.BrowserID)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		scoped, err := instance.Context(payload.Context)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return factory(scoped)(ctx, params)
+	}
+}
+
+// InBrowser is InBrowserContext for tools that act on a whole browser, such
+// as its routes and contexts.
+func InBrowser(service *browser.Service, factory func(*browser.Service) mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return factory(nil)(ctx, params)
+		}
+		var payload browserContextParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		instance, err := service.Browser(payload.BrowserID)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return factory(instance)(ctx, params)
+	}
+}
+
+// browserFailure maps the errors of the browser service to tool errors
+// for every browser tool.
+func browserFailure(err error) *mcp.ErrorDetail {
+	if errors.Is(err, browser.ErrInvalidLocator) || errors.Is(err, browser.ErrInvalidOption) || errors.Is(err, browser.ErrTabNotFound) ||
+		errors.Is(err, browser.ErrBrowserNotFound) || errors.Is(err, browser.ErrContextNotFound) {
+		return invalidParams(err.Error())
+	}
+	return toolFailure(err.Error())
+}
+
+func BrowserContextList(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		return map[string]any{"contexts": service.Contexts()}, nil
+	}
+}
+
+func BrowserContextCreate(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserContextCreateParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if strings.TrimSpace(payload.Name) == "" {
+			return nil, invalidParams("name is required")
+		}
+		if payload.IdleTimeoutSeconds < 0 {
+			return nil, invalidParams("invalid idle_timeout_seconds")
+		}
+		info, err := service.CreateContext(browser.ContextOptions{
+			Name:        payload.Name,
+			IdleTimeout: time.Duration(payload.IdleTimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return info, nil
+	}
+}
+
+func BrowserContextClose(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserContextCloseParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.CloseContext(payload.Name); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"closed": payload.Name}, nil
+	}
+}
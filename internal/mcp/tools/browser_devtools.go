@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserConsoleParams struct {
+	Level string         `json:"level"`
+	Since uint64         `json:"since"`
+	Limit int            `json:"limit"`
+	Tab   browser.TabRef `json:"tab"`
+}
+
+type browserNetworkParams struct {
+	URL    string         `json:"url"`
+	Method string         `json:"method"`
+	Type   string         `json:"type"`
+	Failed bool           `json:"failed"`
+	Since  uint64         `json:"since"`
+	Limit  int            `json:"limit"`
+	Tab    browser.TabRef `json:"tab"`
+}
+
+type browserResponseBodyParams struct {
+	RequestID string         `json:"request_id"`
+	Tab       browser.TabRef `json:"tab"`
+}
+
+type browserHARParams struct {
+	Path          string         `json:"path"`
+	Tab           browser.TabRef `json:"tab"`
+	IncludeBodies bool           `json:"include_bodies"`
+}
+
+func BrowserConsoleLogs(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserConsoleParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		logs, err := service.ConsoleLogs(browser.ConsoleQuery{
+			Tab:   payload.Tab,
+			Level: payload.Level,
+			Since: payload.Since,
+			Limit: payload.Limit,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return logs, nil
+	}
+}
+
+func BrowserNetworkRequests(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserNetworkParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		requests, err := service.NetworkRequests(browser.NetworkQuery{
+			Tab:          payload.Tab,
+			URL:          payload.URL,
+			Method:       payload.Method,
+			ResourceType: payload.Type,
+			Failed:       payload.Failed,
+			Since:        payload.Since,
+			Limit:        payload.Limit,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return requests, nil
+	}
+}
+
+func BrowserResponseBody(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserResponseBodyParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		body, err := service.ResponseBody(payload.RequestID, payload.Tab)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return body, nil
+	}
+}
+
+func BrowserExportHAR(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserHARParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		result, err := service.ExportHAR(browser.HARExportOptions{
+			Path:          path,
+			Tab:           payload.Tab,
+			IncludeBodies: payload.IncludeBodies,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserDialogPolicyParams struct {
+	Policy string `json:"policy"`
+}
+
+type browserHandleDialogParams struct {
+	Accept     *bool          `json:"accept"`
+	PromptText string         `json:"prompt_text"`
+	Tab        browser.TabRef `json:"tab"`
+}
+
+func BrowserDialogList(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		return map[string]any{"policy": service.DialogPolicy(), "dialogs": service.Dialogs()}, nil
+	}
+}
+
+func BrowserDialogPolicy(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserDialogPolicyParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.SetDialogPolicy(payload.Policy); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"policy": payload.Policy}, nil
+	}
+}
+
+func BrowserHandleDialog(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserHandleDialogParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		accept := payload.Accept == nil || *payload.Accept
+		dialog, err := service.HandleDialog(payload.Tab, accept, payload.PromptText)
+		if errors.Is(err, browser.ErrNoDialog) {
+			return nil, invalidParams(err.Error())
+		}
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return dialog, nil
+	}
+}
@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserSnapshotParams struct {
+	Interactive bool `json:"interactive"`
+}
+
+type browserTypeParams struct {
+	browser.Locator
+	Value string `json:"value"`
+	Clear bool   `json:"clear"`
+}
+
+type browserCheckParams struct {
+	browser.Locator
+	Checked *bool `json:"checked"`
+}
+
+type browserUploadParams struct {
+	browser.Locator
+	Paths []string `json:"paths"`
+}
+
+func BrowserSnapshot(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserSnapshotParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		snapshot, err := service.Snapshot(browser.SnapshotOptions{InteractiveOnly: payload.Interactive})
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return snapshot, nil
+	}
+}
+
+func BrowserContent(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var options browser.ContentOptions
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &options); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		content, err := service.Content(options)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return content, nil
+	}
+}
+
+// browserElementTool wraps a locator-only action, reporting result as true on success.
+func browserElementTool(service *browser.Service, result string, action func(browser.Locator) error) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browser.Locator
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := action(payload); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{result: true}, nil
+	}
+}
+
+func BrowserHover(service *browser.Service) mcp.ToolHandler {
+	return browserElementTool(service, "hovered", func(locator browser.Locator) error {
+		return service.HoverElement(locator)
+	})
+}
+
+func BrowserFocus(service *browser.Service) mcp.ToolHandler {
+	return browserElementTool(service, "focused", func(locator browser.Locator) error {
+		return service.FocusElement(locator)
+	})
+}
+
+func BrowserScrollIntoView(service *browser.Service) mcp.ToolHandler {
+	return browserElementTool(service, "scrolled", func(locator browser.Locator) error {
+		return service.ScrollIntoView(locator)
+	})
+}
+
+func BrowserType(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserTypeParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.TypeElement(payload.Locator, payload.Value, payload.Clear); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"typed": true}, nil
+	}
+}
+
+func BrowserCheck(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserCheckParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		checked := payload.Checked == nil || *payload.Checked
+		if err := service.CheckElement(payload.Locator, checked); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"checked": checked}, nil
+	}
+}
+
+func BrowserUploadFile(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserUploadParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if len(payload.Paths) == 0 {
+			return nil, invalidParams("paths is required")
+		}
+		paths := make([]string, 0, len(payload.Paths))
+		for _, path := range payload.Paths {
+			resolved, errDetail := resolveWorkspacePath(path)
+			if errDetail != nil {
+				return nil, errDetail
+			}
+			paths = append(paths, resolved)
+		}
+		if err := service.UploadFiles(payload.Locator, paths); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"uploaded": true}, nil
+	}
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+// browserEmulateParams clears the current emulation first when reset is
+// set, so the call can also turn settings off.
+type browserEmulateParams struct {
+	browser.EmulationOptions
+	Tab   browser.TabRef `json:"tab"`
+	Reset bool           `json:"reset"`
+}
+
+func BrowserEmulate(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserEmulateParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		if payload.Reset {
+			if err := service.ResetEmulation(payload.Tab); err != nil {
+				return nil, browserFailure(err)
+			}
+		}
+		emulation, err := service.Emulate(payload.Tab, payload.EmulationOptions)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"emulation": emulation}, nil
+	}
+}
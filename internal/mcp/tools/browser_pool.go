@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserLeaseParams struct {
+	Session            string `json:"session"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+}
+
+type browserReleaseParams struct {
+	BrowserID string `json:"browser_id"`
+}
+
+func BrowserPoolList(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		return map[string]any{"browsers": service.Browsers()}, nil
+	}
+}
+
+func BrowserPoolLease(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserLeaseParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		if payload.IdleTimeoutSeconds < 0 {
+			return nil, invalidParams("invalid idle_timeout_seconds")
+		}
+		info, err := service.LeaseBrowser(browser.LeaseOptions{
+			Session:     payload.Session,
+			IdleTimeout: time.Duration(payload.IdleTimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return info, nil
+	}
+}
+
+func BrowserPoolRelease(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserReleaseParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.ReleaseBrowser(payload.BrowserID); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"released": payload.BrowserID}, nil
+	}
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserRecordingStartParams struct {
+	Path      string         `json:"path"`
+	Format    string         `json:"format"`
+	Tab       browser.TabRef `json:"tab"`
+	Quality   int            `json:"quality"`
+	MaxWidth  int            `json:"max_width"`
+	MaxHeight int            `json:"max_height"`
+}
+
+func BrowserRecordingStart(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserRecordingStartParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		if payload.Path != "" {
+			path, errDetail := resolveWorkspacePath(payload.Path)
+			if errDetail != nil {
+				return nil, errDetail
+			}
+			payload.Path = path
+		}
+		info, err := service.StartRecording(browser.RecordingOptions{
+			Path:      payload.Path,
+			Format:    payload.Format,
+			Tab:       payload.Tab,
+			Quality:   payload.Quality,
+			MaxWidth:  payload.MaxWidth,
+			MaxHeight: payload.MaxHeight,
+		})
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return info, nil
+	}
+}
+
+func BrowserRecordingStop(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		info, err := service.StopRecording()
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return info, nil
+	}
+}
+
+func BrowserRecordingList(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		return map[string]any{"recordings": service.Recordings()}, nil
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserRouteRemoveParams struct {
+	ID  string `json:"id"`
+	All bool   `json:"all"`
+}
+
+func BrowserRouteAdd(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var rule browser.RouteRule
+		if err := json.Unmarshal(params, &rule); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if rule.BodyFile != "" {
+			path, errDetail := resolveWorkspacePath(rule.BodyFile)
+			if errDetail != nil {
+				return nil, errDetail
+			}
+			rule.BodyFile = path
+		}
+		added, err := service.AddRoute(rule)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return added, nil
+	}
+}
+
+func BrowserRouteList(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		return map[string]any{"routes": service.Routes()}, nil
+	}
+}
+
+func BrowserRouteRemove(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserRouteRemoveParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if payload.All {
+			service.ClearRoutes()
+			return map[string]any{"removed": true}, nil
+		}
+		if payload.ID == "" {
+			return nil, invalidParams("id or all is required")
+		}
+		if err := service.RemoveRoute(payload.ID); err != nil {
+			return nil, invalidParams(err.Error())
+		}
+		return map[string]any{"removed": true}, nil
+	}
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserCookiesParams struct {
+	URLs []string `json:"urls"`
+}
+
+type browserSetCookiesParams struct {
+	Cookies []browser.Cookie `json:"cookies"`
+}
+
+type browserStorageParams struct {
+	Kind string         `json:"kind"`
+	Tab  browser.TabRef `json:"tab"`
+}
+
+type browserStorageStateParams struct {
+	Path  string                `json:"path"`
+	State *browser.StorageState `json:"state"`
+}
+
+func BrowserGetCookies(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserCookiesParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		cookies, err := service.Cookies(payload.URLs)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"cookies": cookies}, nil
+	}
+}
+
+func BrowserSetCookies(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserSetCookiesParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.SetCookies(payload.Cookies); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"set": len(payload.Cookies)}, nil
+	}
+}
+
+func BrowserClearCookies(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var filter browser.CookieFilter
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &filter); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		removed, err := service.ClearCookies(filter)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"removed": removed}, nil
+	}
+}
+
+func BrowserGetStorage(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserStorageParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		storage, err := service.Storage(payload.Kind, payload.Tab)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return storage, nil
+	}
+}
+
+func BrowserSetStorage(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var update browser.StorageUpdate
+		if err := json.Unmarshal(params, &update); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		storage, err := service.UpdateStorage(update)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return storage, nil
+	}
+}
+
+func BrowserSaveStorageState(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserStorageStateParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		result, err := service.SaveStorageState(path)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return result, nil
+	}
+}
+
+func BrowserLoadStorageState(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserStorageStateParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if payload.State != nil {
+			result, err := service.LoadStorageState(payload.State)
+			if err != nil {
+				return nil, browserFailure(err)
+			}
+			return result, nil
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		result, err := service.LoadStorageStateFile(path)
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return result, nil
+	}
+}
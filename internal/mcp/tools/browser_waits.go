@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/mcp"
+)
+
+type browserWaitForSelectorParams struct {
+	browser.Locator
+	State string `json:"state"`
+}
+
+type browserWaitForURLParams struct {
+	URL       string `json:"url"`
+	TimeoutMS int    `json:"timeout_ms"`
+}
+
+type browserWaitForFunctionParams struct {
+	Expression string `json:"expression"`
+	TimeoutMS  int    `json:"timeout_ms"`
+}
+
+type browserWaitForLoadStateParams struct {
+	State     string `json:"state"`
+	TimeoutMS int    `json:"timeout_ms"`
+}
+
+func millis(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func BrowserWaitForSelector(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserWaitForSelectorParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if err := service.WaitForSelector(payload.Locator, payload.State); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"waited": true}, nil
+	}
+}
+
+func BrowserWaitForURL(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserWaitForURLParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		url, err := service.WaitForURL(payload.URL, millis(payload.TimeoutMS))
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"url": url}, nil
+	}
+}
+
+func BrowserWaitForFunction(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserWaitForFunctionParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		value, err := service.WaitForFunction(payload.Expression, millis(payload.TimeoutMS))
+		if err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"value": value}, nil
+	}
+}
+
+func BrowserWaitForLoadState(service *browser.Service) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		if service == nil {
+			return nil, toolFailure("browser service unavailable")
+		}
+		var payload browserWaitForLoadStateParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		if err := service.WaitForLoadState(payload.State, millis(payload.TimeoutMS)); err != nil {
+			return nil, browserFailure(err)
+		}
+		return map[string]any{"waited": true}, nil
+	}
+}
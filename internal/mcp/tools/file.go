@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"open-sandbox/internal/config"
 	"open-sandbox/internal/file"
 	"open-sandbox/internal/mcp"
 )
@@ -28,6 +30,26 @@ type fileReplaceParams struct {
 	Replace string `json:"replace"`
 }
 
+type fileRestoreParams struct {
+	Path    string `json:"path"`
+	Version int    `json:"version"`
+}
+
+type fileExtractParams struct {
+	Path       string `json:"path"`
+	Target     string `json:"target"`
+	MaxBytes   int64  `json:"max_bytes"`
+	MaxEntries int    `json:"max_entries"`
+	Overwrite  bool   `json:"overwrite"`
+}
+
+type fileArchiveParams struct {
+	Paths  []string `json:"paths"`
+	Globs  []string `json:"globs"`
+	Format string   `json:"format"`
+	Output string   `json:"output"`
+}
+
 func FileRead() mcp.ToolHandler {
 	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
 		var payload filePathParams
@@ -56,7 +78,10 @@ func FileWrite() mcp.ToolHandler {
 		if errDetail != nil {
 			return nil, errDetail
 		}
-		if err := file.Write(path, payload.Content); err != nil {
+		err := fileHistory().Apply(path, "write", func() error {
+			return file.Write(path, payload.Content)
+		})
+		if err != nil {
 			return nil, toolFailure(err.Error())
 		}
 		return map[string]any{"path": path}, nil
@@ -111,10 +136,128 @@ func FileReplace() mcp.ToolHandler {
 		if errDetail != nil {
 			return nil, errDetail
 		}
-		count, err := file.Replace(path, payload.Search, payload.Replace)
+		var count int
+		err := fileHistory().Apply(path, "replace", func() (err error) {
+			count, err = file.Replace(path, payload.Search, payload.Replace)
+			return err
+		})
 		if err != nil {
 			return nil, toolFailure(err.Error())
 		}
 		return map[string]any{"count": count}, nil
 	}
 }
+
+func FileHistory() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload filePathParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		versions, err := fileHistory().List(path)
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return map[string]any{"path": path, "versions": versions}, nil
+	}
+}
+
+func FileRestore() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload fileRestoreParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		version, err := fileHistory().Restore(path, payload.Version)
+		if err != nil {
+			if errors.Is(err, file.ErrVersionNotFound) {
+				return nil, invalidParams(err.Error())
+			}
+			return nil, toolFailure(err.Error())
+		}
+		return map[string]any{"path": path, "restored": version}, nil
+	}
+}
+
+func FileExtract() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload fileExtractParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		path, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		target, errDetail := resolveWorkspacePath(payload.Target)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		result, err := file.Extract(path, target, file.ExtractOptions{
+			MaxBytes:   payload.MaxBytes,
+			MaxEntries: payload.MaxEntries,
+			Overwrite:  payload.Overwrite,
+			History:    fileHistory(),
+		})
+		if err != nil {
+			if errors.Is(err, file.ErrUnsupportedFormat) || errors.Is(err, file.ErrUnsafeEntry) || errors.Is(err, file.ErrArchiveExists) {
+				return nil, invalidParams(err.Error())
+			}
+			return nil, toolFailure(err.Error())
+		}
+		return result, nil
+	}
+}
+
+func FileArchive() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload fileArchiveParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if payload.Format == "" {
+			payload.Format = file.FormatZip
+		}
+		if payload.Format != file.FormatZip && payload.Format != file.FormatTarGz {
+			return nil, invalidParams("format must be zip or tar.gz")
+		}
+		if len(payload.Paths) == 0 && len(payload.Globs) == 0 {
+			return nil, invalidParams("paths or globs are required")
+		}
+		paths := make([]string, 0, len(payload.Paths))
+		for _, raw := range payload.Paths {
+			path, errDetail := resolveWorkspacePath(raw)
+			if errDetail != nil {
+				return nil, errDetail
+			}
+			paths = append(paths, path)
+		}
+		output, errDetail := resolveWorkspacePath(payload.Output)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		result, err := file.ArchiveToFile(output, file.ArchiveOptions{
+			Format: payload.Format,
+			Root:   config.WorkspacePath(),
+			Paths:  paths,
+			Globs:  payload.Globs,
+		})
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return map[string]any{
+			"path":    output,
+			"format":  result.Format,
+			"entries": result.Entries,
+			"bytes":   result.Bytes,
+		}, nil
+	}
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"open-sandbox/internal/file"
+	"open-sandbox/internal/mcp"
+)
+
+// FileResourceWatcher maps file:// resource URIs onto workspace watches.
+// Query parameters mirror GET /v1/file/watch: recursive and ignore.
+func FileResourceWatcher() mcp.ResourceWatcher {
+	return func(ctx context.Context, uri string, notify func(mcp.ResourceUpdatedParams)) error {
+		parsed, err := url.Parse(uri)
+		if err != nil || parsed.Scheme != "file" {
+			return errors.New("uri must use the file scheme")
+		}
+		raw := parsed.Path
+		if parsed.Host != "" {
+			raw = parsed.Host + raw
+		}
+		path, errDetail := resolveWorkspacePath(raw)
+		if errDetail != nil {
+			return errors.New(errDetail.Message)
+		}
+
+		query := parsed.Query()
+		options := file.WatchOptions{
+			Recursive: query.Get("recursive") == "true" || query.Get("recursive") == "1",
+		}
+		for _, value := range query["ignore"] {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					options.Ignore = append(options.Ignore, item)
+				}
+			}
+		}
+
+		events, err := file.Watch(ctx, path, options)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for batch := range events {
+				notify(mcp.ResourceUpdatedParams{URI: uri, Events: batch})
+			}
+		}()
+		return nil
+	}
+}
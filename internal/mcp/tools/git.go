@@ -0,0 +1,319 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/git"
+	"open-sandbox/internal/mcp"
+)
+
+type gitRepoParams struct {
+	Path string `json:"path"`
+}
+
+type gitDiffParams struct {
+	Path    string   `json:"path"`
+	Staged  bool     `json:"staged"`
+	Ref     string   `json:"ref"`
+	Paths   []string `json:"paths"`
+	Context int      `json:"context"`
+}
+
+type gitLogParams struct {
+	Path  string `json:"path"`
+	Ref   string `json:"ref"`
+	File  string `json:"file"`
+	Limit int    `json:"limit"`
+}
+
+type gitBranchParams struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	StartPoint string `json:"start_point"`
+	Delete     bool   `json:"delete"`
+	Force      bool   `json:"force"`
+}
+
+type gitCheckoutParams struct {
+	Path   string `json:"path"`
+	Ref    string `json:"ref"`
+	Create bool   `json:"create"`
+}
+
+type gitAddParams struct {
+	Path  string   `json:"path"`
+	Paths []string `json:"paths"`
+	All   bool     `json:"all"`
+}
+
+type gitCommitParams struct {
+	Path       string     `json:"path"`
+	Message    string     `json:"message"`
+	Author     git.Author `json:"author"`
+	All        bool       `json:"all"`
+	AllowEmpty bool       `json:"allow_empty"`
+}
+
+type gitStashParams struct {
+	Path             string `json:"path"`
+	Action           string `json:"action"`
+	Message          string `json:"message"`
+	IncludeUntracked bool   `json:"include_untracked"`
+	Index            int    `json:"index"`
+}
+
+type gitCloneParams struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	Depth  int    `json:"depth"`
+}
+
+func openGitRepo(raw string) (*git.Repo, *mcp.ErrorDetail) {
+	dir, errDetail := resolveWorkspaceDir(raw)
+	if errDetail != nil {
+		return nil, errDetail
+	}
+	return git.OpenIn(dir, config.WorkspacePath()), nil
+}
+
+func gitFailure(err error) *mcp.ErrorDetail {
+	if errors.Is(err, git.ErrInvalidInput) || errors.Is(err, git.ErrNotRepository) {
+		return invalidParams(err.Error())
+	}
+	return toolFailure(err.Error())
+}
+
+// decodeGitParams treats missing params as an empty object so read-only
+// tools can be called without arguments.
+func decodeGitParams(params json.RawMessage, target any) *mcp.ErrorDetail {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, target); err != nil {
+		return invalidParams("invalid params")
+	}
+	return nil
+}
+
+func GitStatus() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitRepoParams
+		if errDetail := decodeGitParams(params, &payload); errDetail != nil {
+			return nil, errDetail
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		status, err := repo.Status(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return status, nil
+	}
+}
+
+func GitDiff() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitDiffParams
+		if errDetail := decodeGitParams(params, &payload); errDetail != nil {
+			return nil, errDetail
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		files, err := repo.Diff(ctx, git.DiffOptions{
+			Staged:  payload.Staged,
+			Ref:     payload.Ref,
+			Paths:   payload.Paths,
+			Context: payload.Context,
+		})
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"files": files}, nil
+	}
+}
+
+func GitLog() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitLogParams
+		if errDetail := decodeGitParams(params, &payload); errDetail != nil {
+			return nil, errDetail
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		commits, err := repo.Log(ctx, git.LogOptions{Ref: payload.Ref, Path: payload.File, Limit: payload.Limit})
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"commits": commits}, nil
+	}
+}
+
+func GitBranches() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitRepoParams
+		if errDetail := decodeGitParams(params, &payload); errDetail != nil {
+			return nil, errDetail
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		branches, err := repo.Branches(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"branches": branches}, nil
+	}
+}
+
+func GitBranch() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitBranchParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		var err error
+		if payload.Delete {
+			err = repo.DeleteBranch(ctx, payload.Name, payload.Force)
+		} else {
+			err = repo.CreateBranch(ctx, payload.Name, payload.StartPoint)
+		}
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		branches, err := repo.Branches(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"branches": branches}, nil
+	}
+}
+
+func GitCheckout() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitCheckoutParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		if err := repo.Checkout(ctx, payload.Ref, payload.Create); err != nil {
+			return nil, gitFailure(err)
+		}
+		status, err := repo.Status(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return status, nil
+	}
+}
+
+func GitAdd() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitAddParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		if err := repo.Add(ctx, payload.Paths, payload.All); err != nil {
+			return nil, gitFailure(err)
+		}
+		status, err := repo.Status(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return status, nil
+	}
+}
+
+func GitCommit() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitCommitParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		commit, err := repo.Commit(ctx, git.CommitOptions{
+			Message:    payload.Message,
+			Author:     payload.Author,
+			All:        payload.All,
+			AllowEmpty: payload.AllowEmpty,
+		})
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return commit, nil
+	}
+}
+
+func GitStash() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitStashParams
+		if errDetail := decodeGitParams(params, &payload); errDetail != nil {
+			return nil, errDetail
+		}
+		repo, errDetail := openGitRepo(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		entries, err := repo.Stash(ctx, git.StashOptions{
+			Action:           payload.Action,
+			Message:          payload.Message,
+			IncludeUntracked: payload.IncludeUntracked,
+			Index:            payload.Index,
+		})
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"stashes": entries}, nil
+	}
+}
+
+func GitClone() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload gitCloneParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		dir, errDetail := resolveWorkspacePath(payload.Path)
+		if errDetail != nil {
+			return nil, errDetail
+		}
+		repo, err := git.Clone(ctx, git.CloneOptions{
+			URL:    payload.URL,
+			Dir:    dir,
+			Branch: payload.Branch,
+			Depth:  payload.Depth,
+		})
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		status, err := repo.Status(ctx)
+		if err != nil {
+			return nil, gitFailure(err)
+		}
+		return map[string]any{"path": dir, "status": status}, nil
+	}
+}
@@ -32,6 +32,10 @@ func resolveWorkspaceDir(raw string) (string, *mcp.ErrorDetail) {
 	return resolveWorkspacePath(raw)
 }
 
+func fileHistory() *file.History {
+	return file.NewHistory(config.FileHistoryPath(), file.DefaultHistoryLimit)
+}
+
 func invalidParams(message string) *mcp.ErrorDetail {
 	detail := mcp.NewErrorDetail("invalid_params", message, mcp.KindInvalidParams)
 	return &detail
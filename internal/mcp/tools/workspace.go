@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/mcp"
+	"open-sandbox/internal/snapshot"
+)
+
+type snapshotCreateParams struct {
+	Label string `json:"label"`
+}
+
+type snapshotIDParams struct {
+	ID string `json:"id"`
+}
+
+type snapshotDiffParams struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func snapshotStore() *snapshot.Store {
+	return snapshot.NewStore(config.SnapshotsPath())
+}
+
+func snapshotFailure(err error) *mcp.ErrorDetail {
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return invalidParams(err.Error())
+	}
+	return toolFailure(err.Error())
+}
+
+func WorkspaceSnapshotCreate() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload snapshotCreateParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &payload); err != nil {
+				return nil, invalidParams("invalid params")
+			}
+		}
+		summary, err := snapshotStore().Create(config.WorkspacePath(), payload.Label)
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return summary, nil
+	}
+}
+
+func WorkspaceSnapshotList() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		snapshots, err := snapshotStore().List()
+		if err != nil {
+			return nil, toolFailure(err.Error())
+		}
+		return map[string]any{"snapshots": snapshots}, nil
+	}
+}
+
+func WorkspaceSnapshotDiff() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload snapshotDiffParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if payload.From == "" {
+			return nil, invalidParams("from is required")
+		}
+		diff, err := snapshotStore().Diff(config.WorkspacePath(), payload.From, payload.To)
+		if err != nil {
+			return nil, snapshotFailure(err)
+		}
+		return diff, nil
+	}
+}
+
+func WorkspaceSnapshotRestore() mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, *mcp.ErrorDetail) {
+		var payload snapshotIDParams
+		if err := json.Unmarshal(params, &payload); err != nil {
+			return nil, invalidParams("invalid params")
+		}
+		if payload.ID == "" {
+			return nil, invalidParams("id is required")
+		}
+		summary, err := snapshotStore().Restore(config.WorkspacePath(), payload.ID)
+		if err != nil {
+			return nil, snapshotFailure(err)
+		}
+		return map[string]any{"restored": summary}, nil
+	}
+}
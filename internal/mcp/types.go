@@ -78,13 +78,18 @@ type InitializeResult struct {
 }
 
 type InitializeCapabilities struct {
-	Tools *InitializeToolsCapabilities `json:"tools,omitempty"`
+	Tools     *InitializeToolsCapabilities     `json:"tools,omitempty"`
+	Resources *InitializeResourcesCapabilities `json:"resources,omitempty"`
 }
 
 type InitializeToolsCapabilities struct {
 	ListChanged bool `json:"listChanged"`
 }
 
+type InitializeResourcesCapabilities struct {
+	Subscribe bool `json:"subscribe"`
+}
+
 type InitializeServerInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -94,9 +99,13 @@ type ToolsListResult struct {
 	Tools []ToolInfo `json:"tools"`
 }
 
+// ContentBlock is a text block, or an image block carrying base64 Data of
+// MimeType.
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
 }
 
 type ToolCallParams struct {
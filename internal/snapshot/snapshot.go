@@ -0,0 +1,486 @@
+package snapshot
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	EntryFile    = "file"
+	EntryDir     = "dir"
+	EntrySymlink = "symlink"
+)
+
+var ErrNotFound = errors.New("snapshot not found")
+
+// storeMu serializes store mutations; a Store is a handle over shared
+// on-disk state and may be created per request.
+var storeMu sync.Mutex
+
+type Store struct {
+	root string
+}
+
+type Entry struct {
+	Path   string      `json:"path"`
+	Type   string      `json:"type"`
+	Mode   fs.FileMode `json:"mode"`
+	Size   int64       `json:"size,omitempty"`
+	SHA256 string      `json:"sha256,omitempty"`
+	Target string      `json:"target,omitempty"`
+}
+
+type Summary struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+	TotalSize int64     `json:"total_size"`
+}
+
+type Manifest struct {
+	Summary
+	Entries []Entry `json:"entries"`
+}
+
+type Diff struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Create records workspace as a new snapshot. File contents are stored once
+// per SHA-256 digest, so unchanged files cost nothing in later snapshots.
+func (store *Store) Create(workspace string, label string) (Summary, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	id, err := newSnapshotID()
+	if err != nil {
+		return Summary{}, err
+	}
+	entries, err := store.scan(workspace, true)
+	if err != nil {
+		return Summary{}, err
+	}
+	manifest := Manifest{
+		Summary: Summary{ID: id, Label: strings.TrimSpace(label), CreatedAt: time.Now().UTC()},
+		Entries: entries,
+	}
+	for _, entry := range entries {
+		if entry.Type == EntryFile {
+			manifest.Files++
+			manifest.TotalSize += entry.Size
+		}
+	}
+	if err := store.saveManifest(manifest); err != nil {
+		return Summary{}, err
+	}
+	return manifest.Summary, nil
+}
+
+func (store *Store) List() ([]Summary, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	entries, err := os.ReadDir(store.manifestDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []Summary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Summary, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		manifest, err := store.loadManifest(id)
+		if err != nil {
+			continue
+		}
+		results = append(results, manifest.Summary)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.Before(results[j].CreatedAt)
+	})
+	return results, nil
+}
+
+func (store *Store) Get(id string) (Manifest, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return store.loadManifest(id)
+}
+
+// Diff compares two snapshots. An empty to compares against the live
+// workspace instead.
+func (store *Store) Diff(workspace string, from string, to string) (Diff, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	base, err := store.loadManifest(from)
+	if err != nil {
+		return Diff{}, err
+	}
+	var target []Entry
+	if to == "" {
+		target, err = store.scan(workspace, false)
+	} else {
+		var manifest Manifest
+		manifest, err = store.loadManifest(to)
+		target = manifest.Entries
+	}
+	if err != nil {
+		return Diff{}, err
+	}
+
+	diff := Diff{From: from, To: to, Added: []string{}, Removed: []string{}, Modified: []string{}}
+	before := indexEntries(base.Entries)
+	after := indexEntries(target)
+	for path, entry := range after {
+		old, ok := before[path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, path)
+		case old.Type != entry.Type || old.SHA256 != entry.SHA256 || old.Target != entry.Target:
+			diff.Modified = append(diff.Modified, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff, nil
+}
+
+// Restore makes workspace match the snapshot exactly: extra entries are
+// removed and changed files are rewritten from the object store.
+func (store *Store) Restore(workspace string, id string) (Summary, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	manifest, err := store.loadManifest(id)
+	if err != nil {
+		return Summary{}, err
+	}
+	current, err := store.scan(workspace, false)
+	if err != nil {
+		return Summary{}, err
+	}
+	wanted := indexEntries(manifest.Entries)
+	existing := indexEntries(current)
+
+	// Remove deepest paths first so directories are empty when reached.
+	sort.Slice(current, func(i, j int) bool { return current[i].Path > current[j].Path })
+	for _, entry := range current {
+		target, ok := wanted[entry.Path]
+		if ok && target.Type == entry.Type {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(workspace, filepath.FromSlash(entry.Path))); err != nil {
+			return Summary{}, err
+		}
+		delete(existing, entry.Path)
+	}
+
+	for _, entry := range manifest.Entries {
+		dest := filepath.Join(workspace, filepath.FromSlash(entry.Path))
+		old, ok := existing[entry.Path]
+		switch entry.Type {
+		case EntryDir:
+			if err := os.MkdirAll(dest, entry.Mode.Perm()|0700); err != nil {
+				return Summary{}, err
+			}
+		case EntrySymlink:
+			if ok && old.Target == entry.Target {
+				continue
+			}
+			_ = os.Remove(dest)
+			if err := os.Symlink(entry.Target, dest); err != nil {
+				return Summary{}, err
+			}
+		case EntryFile:
+			if ok && old.SHA256 == entry.SHA256 {
+				continue
+			}
+			if err := store.restoreObject(entry, dest); err != nil {
+				return Summary{}, err
+			}
+		}
+	}
+	return manifest.Summary, nil
+}
+
+func (store *Store) Delete(id string) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if _, err := store.loadManifest(id); err != nil {
+		return err
+	}
+	if err := os.Remove(store.manifestPath(id)); err != nil {
+		return err
+	}
+	return store.collectGarbage()
+}
+
+func (store *Store) scan(workspace string, persist bool) ([]Entry, error) {
+	storeRoot, _ := filepath.Abs(store.root)
+	var entries []Entry
+	err := filepath.WalkDir(workspace, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if current == workspace {
+			return nil
+		}
+		if abs, _ := filepath.Abs(current); abs == storeRoot {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(workspace, current)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry := Entry{Path: filepath.ToSlash(rel), Mode: info.Mode().Perm()}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(current)
+			if err != nil {
+				return err
+			}
+			entry.Type = EntrySymlink
+			entry.Target = target
+		case d.IsDir():
+			entry.Type = EntryDir
+		case info.Mode().IsRegular():
+			digest, err := store.hashFile(current, persist)
+			if err != nil {
+				return err
+			}
+			entry.Type = EntryFile
+			entry.Size = info.Size()
+			entry.SHA256 = digest
+		default:
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// hashFile digests path and, when persist is set, copies it into the object
+// store unless an identical object already exists.
+func (store *Store) hashFile(path string, persist bool) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	hasher := sha256.New()
+	if !persist {
+		if _, err := io.Copy(hasher, source); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hasher.Sum(nil)), nil
+	}
+
+	if err := os.MkdirAll(store.objectDir(), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(store.objectDir(), "incoming-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(io.MultiWriter(hasher, tmp), source); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(hasher.Sum(nil))
+	objectPath := store.objectPath(digest)
+	if _, err := os.Stat(objectPath); err == nil {
+		return digest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), objectPath); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+func (store *Store) restoreObject(entry Entry, dest string) error {
+	source, err := os.Open(store.objectPath(entry.SHA256))
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, source); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), entry.Mode.Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func (store *Store) collectGarbage() error {
+	referenced := make(map[string]bool)
+	manifests, err := os.ReadDir(store.manifestDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, item := range manifests {
+		id, ok := strings.CutSuffix(item.Name(), ".json")
+		if !ok {
+			continue
+		}
+		manifest, err := store.loadManifest(id)
+		if err != nil {
+			return err
+		}
+		for _, entry := range manifest.Entries {
+			if entry.SHA256 != "" {
+				referenced[entry.SHA256] = true
+			}
+		}
+	}
+	return filepath.WalkDir(store.objectDir(), func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || referenced[d.Name()] {
+			return nil
+		}
+		return os.Remove(current)
+	})
+}
+
+func (store *Store) loadManifest(id string) (Manifest, error) {
+	if !validSnapshotID(id) {
+		return Manifest{}, ErrNotFound
+	}
+	payload, err := os.ReadFile(store.manifestPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Manifest{}, ErrNotFound
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func (store *Store) saveManifest(manifest Manifest) error {
+	if err := os.MkdirAll(store.manifestDir(), 0755); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	tmp := store.manifestPath(manifest.ID) + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, store.manifestPath(manifest.ID))
+}
+
+func (store *Store) manifestDir() string {
+	return filepath.Join(store.root, "manifests")
+}
+
+func (store *Store) manifestPath(id string) string {
+	return filepath.Join(store.manifestDir(), id+".json")
+}
+
+func (store *Store) objectDir() string {
+	return filepath.Join(store.root, "objects")
+}
+
+func (store *Store) objectPath(digest string) string {
+	return filepath.Join(store.objectDir(), digest[:2], digest)
+}
+
+func indexEntries(entries []Entry) map[string]Entry {
+	index := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		index[entry.Path] = entry
+	}
+	return index
+}
+
+func newSnapshotID() (string, error) {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(buf), nil
+}
+
+func validSnapshotID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
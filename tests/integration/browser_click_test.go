@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/api/handlers"
+	"open-sandbox/internal/browser"
+)
+
+// Without a browser binary every click fails, but how it fails shows whether
+// the locator reached the element path or was dropped for a click at (0,0).
+func TestBrowserClickHandlerUsesLocator(t *testing.T) {
+	config := browser.DefaultConfig()
+	config.BinaryPath = filepath.Join(t.TempDir(), "missing-chrome")
+	router := api.NewRouter()
+	handlers.RegisterBrowserRoutes(router, browser.NewService(config))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	cases := []struct {
+		body   map[string]any
+		status int
+		code   string
+	}{
+		{map[string]any{"element": -1}, http.StatusBadRequest, "bad_request"},
+		{map[string]any{"text": "Save", "name": "Save", "button": "right", "click_count": 2}, http.StatusBadRequest, "bad_request"},
+		{map[string]any{"x": 10, "y": 20, "button": "middle"}, http.StatusInternalServerError, "click_failed"},
+	}
+	for _, tc := range cases {
+		payload, _ := json.Marshal(tc.body)
+		resp, err := http.Post(server.URL+"/v1/browser/click", "application/json", bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("click request failed: %v", err)
+		}
+		var envelope struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&envelope)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || envelope.Error.Code != tc.code {
+			t.Fatalf("%v: expected %d %s, got %d %s", tc.body, tc.status, tc.code, resp.StatusCode, envelope.Error.Code)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+const consoleTestPage = `<html><body><script>
+console.log('hello', 42);
+console.warn('careful');
+setTimeout(() => { throw new Error('boom'); }, 0);
+</script></body></html>`
+
+func TestBrowserConsoleLogs(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(consoleTestPage))
+	}))
+	defer page.Close()
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	var logs *browser.ConsoleLogs
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		logs, err = service.ConsoleLogs(browser.ConsoleQuery{Level: browser.ConsoleLevelWarning})
+		if err != nil {
+			t.Fatalf("console logs: %v", err)
+		}
+		if len(logs.Entries) >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(logs.Entries) != 2 {
+		t.Fatalf("expected warning and exception, got %+v", logs.Entries)
+	}
+	if logs.Entries[0].Text != "careful" || logs.Entries[1].Source != "exception" || !strings.Contains(logs.Entries[1].Text, "boom") {
+		t.Fatalf("unexpected entries: %+v", logs.Entries)
+	}
+
+	all, err := service.ConsoleLogs(browser.ConsoleQuery{})
+	if err != nil {
+		t.Fatalf("console logs: %v", err)
+	}
+	if len(all.Entries) < 3 || all.Entries[0].Text != "hello 42" {
+		t.Fatalf("unexpected unfiltered entries: %+v", all.Entries)
+	}
+	newer, err := service.ConsoleLogs(browser.ConsoleQuery{Since: all.Cursor})
+	if err != nil {
+		t.Fatalf("console logs since cursor: %v", err)
+	}
+	if len(newer.Entries) != 0 || newer.Cursor != all.Cursor {
+		t.Fatalf("expected no entries after cursor, got %+v", newer)
+	}
+}
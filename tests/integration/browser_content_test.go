@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+const contentTestPage = `<html><head><title>Docs</title></head><body>
+<nav><a href="/home">Home</a></nav>
+<main>
+<h1>Getting started</h1>
+<p>Install the <strong>CLI</strong> and read the <a href="/guide">guide</a>.</p>
+<ul><li>Fast</li><li>Small<ul><li>Nested</li></ul></li></ul>
+<pre>line one
+  indented</pre>
+<form action="/search" method="post"><label for="q">Query</label><input id="q" name="q" required></form>
+<svg><text>Vector label</text></svg>
+<p id="nul"></p>
+<script>document.getElementById('nul').textContent = 'before\u0000after';</script>
+</main>
+</body></html>`
+
+func TestBrowserContentExtraction(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(contentTestPage))
+	}))
+	defer page.Close()
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	content, err := service.Content(browser.ContentOptions{})
+	if err != nil {
+		t.Fatalf("content: %v", err)
+	}
+	for _, want := range []string{
+		"# Getting started",
+		"**CLI**",
+		"[guide](" + page.URL + "/guide)",
+		"- Small\n  - Nested",
+		"line one\n  indented",
+	} {
+		if !strings.Contains(content.Content, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, content.Content)
+		}
+	}
+	if strings.Contains(content.Content, "Vector label") || !strings.Contains(content.Content, "before\x00after") {
+		t.Fatalf("svg should be skipped and NULs in page text kept:\n%q", content.Content)
+	}
+	if strings.Contains(content.Content, "Home") || len(content.Links) != 1 {
+		t.Fatalf("navigation should be excluded: %+v", content.Links)
+	}
+	if len(content.Forms) != 1 || content.Forms[0].Method != "post" || content.Forms[0].Fields[0].Label != "Query" {
+		t.Fatalf("unexpected forms: %+v", content.Forms)
+	}
+
+	text, err := service.Content(browser.ContentOptions{Format: browser.ContentFormatText, MaxChars: 10})
+	if err != nil {
+		t.Fatalf("text content: %v", err)
+	}
+	if !text.Truncated || text.Content != "Getting st" {
+		t.Fatalf("unexpected truncated text: %+v", text)
+	}
+
+	html, err := service.Content(browser.ContentOptions{Format: browser.ContentFormatHTML, MaxChars: 20})
+	if err != nil {
+		t.Fatalf("html content: %v", err)
+	}
+	if !html.Truncated || html.Length != 20 {
+		t.Fatalf("unexpected truncated html: %+v", html)
+	}
+
+	if _, err := service.Content(browser.ContentOptions{Selector: "#missing"}); !errors.Is(err, browser.ErrInvalidLocator) {
+		t.Fatalf("expected unmatched selector to fail, got %v", err)
+	}
+	if _, err := service.Content(browser.ContentOptions{Selector: "[[bad"}); !errors.Is(err, browser.ErrInvalidLocator) {
+		t.Fatalf("expected invalid selector to fail, got %v", err)
+	}
+}
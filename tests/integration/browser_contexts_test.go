@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserContextIsolation(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>contexts</body></html>`))
+	}))
+	defer page.Close()
+
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "agent-a"}); err != nil {
+		t.Fatalf("create context: %v", err)
+	}
+	agent, err := service.Context("agent-a")
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	t.Cleanup(func() { _ = service.CloseContext("agent-a") })
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate default: %v", err)
+	}
+	if err := agent.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate agent: %v", err)
+	}
+	if err := agent.SetCookies([]browser.Cookie{{Name: "sid", Value: "agent", URL: page.URL}}); err != nil {
+		t.Fatalf("set cookies: %v", err)
+	}
+
+	if cookies, _ := service.Cookies([]string{page.URL}); len(cookies) != 0 {
+		t.Fatalf("default context sees agent cookies: %+v", cookies)
+	}
+	cookies, err := agent.Cookies([]string{page.URL})
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "agent" {
+		t.Fatalf("unexpected agent cookies: %+v, %v", cookies, err)
+	}
+
+	if err := service.CloseContext("agent-a"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := agent.Navigate(page.URL); err == nil {
+		t.Fatalf("closed context should reject navigation")
+	}
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserDialogs(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+<button id="alert" onclick="alert('hello'); document.title='alerted'">Alert</button>
+<button id="prompt" onclick="document.title = 'name:' + prompt('Name?', 'guest')">Prompt</button>
+</body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	events, cancel := service.Subscribe()
+	defer cancel()
+
+	if err := service.ClickElement(browser.Locator{Selector: "#alert"}, browser.ClickOptions{}); err != nil {
+		t.Fatalf("click with accept policy: %v", err)
+	}
+	title, err := service.Evaluate(`document.title`)
+	if err != nil || title != "alerted" {
+		t.Fatalf("alert was not accepted: %v, %v", title, err)
+	}
+	for event := range events {
+		if event.Type != browser.EventDialogOpened {
+			continue
+		}
+		if event.Dialog == nil || event.Dialog.Type != "alert" || event.Dialog.Message != "hello" {
+			t.Fatalf("unexpected dialog event: %+v", event)
+		}
+		break
+	}
+
+	if err := service.SetDialogPolicy(browser.DialogPolicyWait); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	err = service.ClickElement(browser.Locator{Selector: "#prompt"}, browser.ClickOptions{})
+	if !errors.Is(err, browser.ErrDialogOpen) {
+		t.Fatalf("expected dialog open error, got %v", err)
+	}
+	dialogs := service.Dialogs()
+	if len(dialogs) != 1 || dialogs[0].Type != "prompt" || dialogs[0].DefaultPrompt != "guest" {
+		t.Fatalf("unexpected dialogs: %+v", dialogs)
+	}
+	if _, err := service.Evaluate(`1`); !errors.Is(err, browser.ErrDialogOpen) {
+		t.Fatalf("expected actions to fail while the dialog is open, got %v", err)
+	}
+
+	dialog, err := service.HandleDialog("", true, "ada")
+	if err != nil {
+		t.Fatalf("handle dialog: %v", err)
+	}
+	if dialog.Accepted == nil || !*dialog.Accepted || dialog.UserInput != "ada" {
+		t.Fatalf("unexpected handled dialog: %+v", dialog)
+	}
+	if err := service.WaitForLoadState(browser.WaitUntilLoad, 0); err != nil {
+		t.Fatalf("page unusable after dialog: %v", err)
+	}
+	title, err = service.Evaluate(`document.title`)
+	if err != nil || title != "name:ada" {
+		t.Fatalf("prompt answer not applied: %v, %v", title, err)
+	}
+}
@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/api/handlers"
+	"open-sandbox/internal/browser"
+)
+
+const elementTestPage = `<html><body>
+<input id="name" aria-label="Name" value="old">
+<label><input type="checkbox" id="agree"> Agree</label>
+<button onclick="document.body.dataset.clicked = 'yes'">Submit order</button>
+</body></html>`
+
+func TestBrowserElementActions(t *testing.T) {
+	service := startBrowserService(t)
+	router := api.NewRouter()
+	handlers.RegisterBrowserRoutes(router, service)
+
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(elementTestPage))
+	}))
+	defer page.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(path string, payload any) int {
+		t.Helper()
+		body, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", path, err)
+		}
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("%s request failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode
+	}
+
+	if status := post("/v1/browser/navigate", map[string]string{"url": page.URL}); status != http.StatusOK {
+		t.Fatalf("navigate status %d", status)
+	}
+	if status := post("/v1/browser/type", map[string]any{"role": "textbox", "name": "Name", "value": "Ada", "clear": true}); status != http.StatusOK {
+		t.Fatalf("type status %d", status)
+	}
+	if status := post("/v1/browser/check", map[string]any{"selector": "#agree"}); status != http.StatusOK {
+		t.Fatalf("check status %d", status)
+	}
+	if status := post("/v1/browser/click", map[string]any{"text": "Submit order"}); status != http.StatusOK {
+		t.Fatalf("click status %d", status)
+	}
+	if status := post("/v1/browser/click", map[string]any{"selector": "#missing", "timeout_ms": 200}); status != http.StatusRequestTimeout {
+		t.Fatalf("expected wait timeout, got %d", status)
+	}
+	if status := post("/v1/browser/hover", map[string]any{}); status != http.StatusBadRequest {
+		t.Fatalf("expected empty locator to be rejected, got %d", status)
+	}
+
+	assertEval(t, service, `document.querySelector('#name').value`, "Ada")
+	assertEval(t, service, `String(document.querySelector('#agree').checked)`, "true")
+	assertEval(t, service, `document.body.dataset.clicked`, "yes")
+}
+
+func assertEval(t *testing.T, service *browser.Service, expression string, want string) {
+	t.Helper()
+	got, err := service.Evaluate(expression)
+	if err != nil {
+		t.Fatalf("evaluate %q: %v", expression, err)
+	}
+	if got != want {
+		t.Fatalf("evaluate %q = %v, want %q", expression, got, want)
+	}
+}
+
+func TestBrowserSnapshotRefClick(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(elementTestPage))
+	}))
+	defer page.Close()
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	snapshot, err := service.Snapshot(browser.SnapshotOptions{InteractiveOnly: true})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	ref := ""
+	for _, node := range snapshot.Tree.Children {
+		if node.Role == "button" && node.Name == "Submit order" {
+			ref = node.Ref
+		}
+	}
+	if ref == "" {
+		t.Fatalf("submit button missing from snapshot:\n%s", snapshot.Text)
+	}
+	if err := service.ClickElement(browser.Locator{Ref: ref}, browser.ClickOptions{}); err != nil {
+		t.Fatalf("click ref: %v", err)
+	}
+	assertEval(t, service, `document.body.dataset.clicked`, "yes")
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if err := service.ClickElement(browser.Locator{Ref: ref}, browser.ClickOptions{}); !errors.Is(err, browser.ErrStaleRef) {
+		t.Fatalf("expected stale ref after navigation, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserEmulation(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>` + r.Header.Get("Accept-Language") + `</body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	emulation, err := service.Emulate("", browser.EmulationOptions{
+		Device:      "pixel-7",
+		Locale:      "de-DE",
+		Timezone:    "Asia/Tokyo",
+		ColorScheme: browser.ColorSchemeDark,
+	})
+	if err != nil {
+		t.Fatalf("emulate: %v", err)
+	}
+	if emulation.Device != "pixel-7" || emulation.Locale != "de-DE" {
+		t.Fatalf("unexpected emulation: %+v", emulation)
+	}
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	checks := map[string]any{
+		`window.innerWidth`:                                        float64(412),
+		`navigator.userAgent.includes('Android')`:                  true,
+		`navigator.language`:                                       "de-DE",
+		`Intl.DateTimeFormat().resolvedOptions().timeZone`:         "Asia/Tokyo",
+		`matchMedia('(prefers-color-scheme: dark)').matches`:       true,
+		`document.body.textContent.startsWith('de-DE')`:            true,
+		`'ontouchstart' in window || navigator.maxTouchPoints > 0`: true,
+	}
+	for expression, want := range checks {
+		got, err := service.Evaluate(expression)
+		if err != nil || got != want {
+			t.Fatalf("%s = %v (%v), want %v", expression, got, err, want)
+		}
+	}
+
+	tab, err := service.NewTab(page.URL)
+	if err != nil {
+		t.Fatalf("new tab: %v", err)
+	}
+	width, err := service.Evaluate(`window.innerWidth`)
+	if err != nil || width != float64(412) {
+		t.Fatalf("new tab did not inherit the context emulation: %v, %v", width, err)
+	}
+	if _, err := service.Emulate(browser.TabRef(tab.ID), browser.EmulationOptions{Viewport: &browser.Viewport{Width: 800, Height: 600}}); err != nil {
+		t.Fatalf("emulate tab: %v", err)
+	}
+	width, err = service.Evaluate(`window.innerWidth`)
+	if err != nil || width != float64(800) {
+		t.Fatalf("tab viewport not applied: %v, %v", width, err)
+	}
+
+	if err := service.ResetEmulation(""); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	userAgent, err := service.Evaluate(`navigator.userAgent`)
+	if err != nil || strings.Contains(userAgent.(string), "Android") {
+		t.Fatalf("user agent not reset: %v, %v", userAgent, err)
+	}
+	emulation, err = service.Emulation("")
+	if err != nil || emulation.Device != "" || emulation.Viewport != nil {
+		t.Fatalf("emulation not cleared: %+v, %v", emulation, err)
+	}
+}
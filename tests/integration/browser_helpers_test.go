@@ -9,7 +9,7 @@ import (
 	"open-sandbox/internal/config"
 )
 
-func startBrowserService(t *testing.T) *browser.Service {
+func startBrowserService(t *testing.T, configure ...func(*browser.Config)) *browser.Service {
 	t.Helper()
 
 	cfg := browser.DefaultConfig()
@@ -18,6 +18,9 @@ func startBrowserService(t *testing.T) *browser.Service {
 	cfg.UserDataDir = filepath.Join(config.CachePath(), "chrome-profile-test")
 	cfg.RemoteDebuggingPort = 0
 	cfg.Headless = true
+	for _, apply := range configure {
+		apply(&cfg)
+	}
 
 	if err := os.MkdirAll(config.CachePath(), 0755); err != nil {
 		t.Fatalf("cache dir setup failed: %v", err)
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserMarkedScreenshot(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+<p>Not interactive</p>
+<input id="query" aria-label="Query">
+<button onclick="document.title='clicked'">Go</button>
+<button style="display:none">Hidden</button>
+</body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	result, err := service.CaptureScreenshot(browser.ScreenshotOptions{Marks: true})
+	if err != nil {
+		t.Fatalf("marked screenshot: %v", err)
+	}
+	if len(result.Marks) != 2 {
+		t.Fatalf("expected two marks, got %+v", result.Marks)
+	}
+	if result.Marks[0].Selector != "#query" || result.Marks[0].Role != "textbox" || result.Marks[0].Name != "Query" {
+		t.Fatalf("unexpected first mark: %+v", result.Marks[0])
+	}
+	button := result.Marks[1]
+	if button.Number != 2 || button.Role != "button" || button.Name != "Go" || button.Width <= 0 {
+		t.Fatalf("unexpected second mark: %+v", button)
+	}
+
+	overlays, err := service.Evaluate(`document.querySelectorAll('#__open_sandbox_marks').length`)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if overlays != float64(0) {
+		t.Fatalf("overlay was left on the page: %v", overlays)
+	}
+
+	if err := service.ClickElement(browser.Locator{Element: button.Number}, browser.ClickOptions{}); err != nil {
+		t.Fatalf("click element: %v", err)
+	}
+	title, err := service.Evaluate(`document.title`)
+	if err != nil || title != "clicked" {
+		t.Fatalf("button was not clicked: %v, %v", title, err)
+	}
+	if err := service.ClickElement(browser.Locator{Element: 9}, browser.ClickOptions{}); err == nil {
+		t.Fatalf("expected unknown element number to fail")
+	}
+}
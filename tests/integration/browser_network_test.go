@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+)
+
+func TestBrowserNetworkLog(t *testing.T) {
+	if err := config.EnsureWorkspace(); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		case "/broken":
+			http.Error(w, "nope", http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body><script>fetch('/api'); fetch('/broken');</script></body></html>`))
+		}
+	}))
+	defer page.Close()
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	var log *browser.NetworkLog
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		log, err = service.NetworkRequests(browser.NetworkQuery{URL: "/api"})
+		if err != nil {
+			t.Fatalf("network requests: %v", err)
+		}
+		if (len(log.Entries) == 1 && log.Entries[0].Finished) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(log.Entries) != 1 || log.Entries[0].Status != 200 || log.Entries[0].Method != "GET" {
+		t.Fatalf("unexpected api entries: %+v", log.Entries)
+	}
+
+	body, err := service.ResponseBody(log.Entries[0].RequestID, "")
+	if err != nil {
+		t.Fatalf("response body: %v", err)
+	}
+	if body.Body != `{"ok":true}` || body.Base64 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+
+	failed, err := service.NetworkRequests(browser.NetworkQuery{Failed: true})
+	if err != nil {
+		t.Fatalf("failed requests: %v", err)
+	}
+	if len(failed.Entries) != 1 || failed.Entries[0].Status != http.StatusInternalServerError {
+		t.Fatalf("unexpected failed entries: %+v", failed.Entries)
+	}
+
+	harPath := filepath.Join(config.WorkspacePath(), "network", "session.har")
+	t.Cleanup(func() { _ = os.RemoveAll(filepath.Dir(harPath)) })
+	result, err := service.ExportHAR(browser.HARExportOptions{Path: harPath, IncludeBodies: true})
+	if err != nil {
+		t.Fatalf("export har: %v", err)
+	}
+	data, err := os.ReadFile(harPath)
+	if err != nil {
+		t.Fatalf("read har: %v", err)
+	}
+	var har browser.HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatalf("decode har: %v", err)
+	}
+	if result.Entries < 3 || len(har.Log.Entries) != result.Entries {
+		t.Fatalf("unexpected har entries: %d vs %d", result.Entries, len(har.Log.Entries))
+	}
+}
@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserPrintToPDF(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>report</title></head><body><h1>Report</h1></body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "out", "report.pdf")
+	result, err := service.PrintToPDF(browser.PDFOptions{
+		Path:           path,
+		Format:         "a4",
+		Landscape:      true,
+		Margin:         &browser.PDFMargin{Top: 0.5, Bottom: 0.5},
+		FooterTemplate: `<div style="font-size:8px"><span class="pageNumber"></span>/<span class="totalPages"></span></div>`,
+	})
+	if err != nil {
+		t.Fatalf("print to pdf: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || result.Bytes != len(data) {
+		t.Fatalf("unexpected pdf output: %d bytes, result %+v", len(data), result)
+	}
+}
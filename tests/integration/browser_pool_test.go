@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserPoolIsolation(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>pool</body></html>`))
+	}))
+	defer page.Close()
+
+	info, err := service.LeaseBrowser(browser.LeaseOptions{Session: "agent-a"})
+	if errors.Is(err, browser.ErrBrowserUnavailable) {
+		t.Skip("no browser binary to launch a pooled browser")
+	}
+	if err != nil {
+		t.Fatalf("lease: %v", err)
+	}
+	t.Cleanup(func() { _ = service.ReleaseBrowser(info.ID) })
+	if again, err := service.LeaseBrowser(browser.LeaseOptions{Session: "agent-a"}); err != nil || again.ID != info.ID {
+		t.Fatalf("session should get its browser back: %+v, %v", again, err)
+	}
+
+	leased, err := service.Browser(info.ID)
+	if err != nil {
+		t.Fatalf("browser: %v", err)
+	}
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate default: %v", err)
+	}
+	if err := leased.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate leased: %v", err)
+	}
+	if leased.Status().PID == 0 || leased.Status().PID == service.Status().PID {
+		t.Fatalf("leased browser should run its own process: %+v", leased.Status())
+	}
+	if err := leased.SetCookies([]browser.Cookie{{Name: "sid", Value: "leased", URL: page.URL}}); err != nil {
+		t.Fatalf("set cookies: %v", err)
+	}
+	if cookies, _ := service.Cookies([]string{page.URL}); len(cookies) != 0 {
+		t.Fatalf("default browser sees leased cookies: %+v", cookies)
+	}
+	if browsers := service.Browsers(); len(browsers) != 2 || browsers[1].ID != info.ID {
+		t.Fatalf("unexpected browsers: %+v", browsers)
+	}
+
+	if err := service.ReleaseBrowser(info.ID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if leased.Status().Running {
+		t.Fatalf("released browser still running")
+	}
+	if _, err := service.Browser(info.ID); !errors.Is(err, browser.ErrBrowserNotFound) {
+		t.Fatalf("expected not found after release, got %v", err)
+	}
+}
+
+func TestBrowserPoolReleasesWithoutLookups(t *testing.T) {
+	service := startBrowserService(t)
+
+	info, err := service.LeaseBrowser(browser.LeaseOptions{IdleTimeout: 200 * time.Millisecond})
+	if errors.Is(err, browser.ErrBrowserUnavailable) {
+		t.Skip("no browser binary to launch a pooled browser")
+	}
+	if err != nil {
+		t.Fatalf("lease: %v", err)
+	}
+	idle, err := service.Browser(info.ID)
+	if err != nil {
+		t.Fatalf("browser: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for idle.Status().Running {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle browser was not reclaimed without a lookup")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := idle.Navigate("about:blank"); !errors.Is(err, browser.ErrBrowserNotFound) {
+		t.Fatalf("reclaimed browser should not relaunch, got %v", err)
+	}
+
+	info, err = service.LeaseBrowser(browser.LeaseOptions{})
+	if err != nil {
+		t.Fatalf("lease: %v", err)
+	}
+	leased, err := service.Browser(info.ID)
+	if err != nil {
+		t.Fatalf("browser: %v", err)
+	}
+	service.Close()
+	if leased.Status().Running {
+		t.Fatalf("closing the default browser should release leased ones")
+	}
+	if browsers := service.Browsers(); len(browsers) != 1 {
+		t.Fatalf("unexpected browsers after close: %+v", browsers)
+	}
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserRecording(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><div id="n">0</div>
+<script>let n = 0; setInterval(() => { document.getElementById('n').textContent = ++n; }, 50);</script>
+</body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "session.gif")
+	started, err := service.StartRecording(browser.RecordingOptions{Path: path, Format: browser.RecordingFormatGIF, MaxWidth: 320, MaxHeight: 240})
+	if err != nil {
+		t.Fatalf("start recording: %v", err)
+	}
+	if started.State != browser.RecordingStateRecording {
+		t.Fatalf("unexpected state %q", started.State)
+	}
+	if _, err := service.StartRecording(browser.RecordingOptions{}); err == nil {
+		t.Fatalf("expected a second recording to be rejected")
+	}
+	time.Sleep(time.Second)
+
+	stopped, err := service.StopRecording()
+	if err != nil {
+		t.Fatalf("stop recording: %v", err)
+	}
+	if stopped.State != browser.RecordingStateFinished || stopped.Frames == 0 || stopped.Path != path {
+		t.Fatalf("unexpected recording: %+v", stopped)
+	}
+	if stat, err := os.Stat(path); err != nil || stat.Size() == 0 {
+		t.Fatalf("recording not written: %v", err)
+	}
+	recordings := service.Recordings()
+	if len(recordings) != 1 || recordings[0].ID != started.ID {
+		t.Fatalf("unexpected recording list: %+v", recordings)
+	}
+}
+
+func TestBrowserRecordingKeepsFramesWhenEncodingFails(t *testing.T) {
+	dir := t.TempDir()
+	service := startBrowserService(t, func(cfg *browser.Config) {
+		cfg.RecordingDir = dir
+		cfg.FFmpegPath = "/bin/false"
+	})
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><h1>frames</h1></body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	if _, err := service.StartRecording(browser.RecordingOptions{Format: browser.RecordingFormatWebM}); err != nil {
+		t.Fatalf("start recording: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	stopped, err := service.StopRecording()
+	if err != nil {
+		t.Fatalf("stop recording: %v", err)
+	}
+	if stopped.State != browser.RecordingStateFailed || stopped.Format != browser.RecordingFormatFrames || filepath.Dir(stopped.Path) != dir {
+		t.Fatalf("expected the frames to be kept next to the target: %+v", stopped)
+	}
+	if _, err := os.Stat(filepath.Join(stopped.Path, "frames.json")); err != nil {
+		t.Fatalf("frame index not written: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("staging directory left behind: %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+)
+
+func TestBrowserRouteRules(t *testing.T) {
+	if err := config.EnsureWorkspace(); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	service := startBrowserService(t)
+	var sawAuth atomic.Value
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			sawAuth.Store(r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte("real"))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body></body></html>`))
+		}
+	}))
+	defer page.Close()
+
+	mockPath := filepath.Join(config.WorkspacePath(), "route-mock.json")
+	if err := os.WriteFile(mockPath, []byte(`{"mocked":true}`), 0644); err != nil {
+		t.Fatalf("write mock: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(mockPath) })
+
+	rules := []browser.RouteRule{
+		{URL: "*/api/users", BodyFile: mockPath},
+		{URL: "*/blocked", Action: browser.RouteActionAbort},
+		{URL: "*/echo", Action: browser.RouteActionContinue, Headers: map[string]string{"Authorization": "Bearer test"}},
+	}
+	for _, rule := range rules {
+		if _, err := service.AddRoute(rule); err != nil {
+			t.Fatalf("add route: %v", err)
+		}
+	}
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	get := func(path string) string {
+		return `(() => { try { const x = new XMLHttpRequest(); x.open('GET', '` + path + `', false); x.send(); return x.responseText; } catch (e) { return 'failed'; } })()`
+	}
+	assertEval(t, service, get("/api/users"), `{"mocked":true}`)
+	assertEval(t, service, get("/blocked"), "failed")
+	assertEval(t, service, get("/echo"), "real")
+	if got, _ := sawAuth.Load().(string); got != "Bearer test" {
+		t.Fatalf("continue rule did not set header, got %q", got)
+	}
+	if routes := service.Routes(); routes[0].Hits != 1 {
+		t.Fatalf("unexpected hit count: %+v", routes[0])
+	}
+}
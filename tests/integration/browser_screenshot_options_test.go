@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserScreenshotOptions(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body style="margin:0"><div id="box" style="width:120px;height:80px;background:red"></div><div style="height:4000px"></div></body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	viewport, err := service.Viewport()
+	if err != nil {
+		t.Fatalf("viewport: %v", err)
+	}
+
+	full, err := service.CaptureScreenshot(browser.ScreenshotOptions{FullPage: true, Format: "jpeg", Quality: 60})
+	if err != nil {
+		t.Fatalf("full page: %v", err)
+	}
+	if full.MimeType != "image/jpeg" {
+		t.Fatalf("unexpected mime type %q", full.MimeType)
+	}
+	if config, _, err := image.DecodeConfig(bytes.NewReader(full.Data)); err != nil || config.Height <= viewport.Height {
+		t.Fatalf("full page should be taller than the viewport: %+v, %v", config, err)
+	}
+
+	element, err := service.CaptureScreenshot(browser.ScreenshotOptions{Element: browser.Locator{Selector: "#box"}, Scale: 2})
+	if err != nil {
+		t.Fatalf("element: %v", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(element.Data))
+	if err != nil || config.Width != 240 || config.Height != 160 {
+		t.Fatalf("unexpected element screenshot size: %+v, %v", config, err)
+	}
+}
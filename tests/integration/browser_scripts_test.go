@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/api/handlers"
+	"open-sandbox/internal/browser"
+)
+
+const scriptTestPage = `<html><body>
+<input id="q">
+<button id="go" onclick="document.getElementById('out').textContent = document.getElementById('q').value">Go</button>
+<button id="reset" onclick="document.getElementById('out').textContent = ''">Reset</button>
+<p id="out"></p>
+</body></html>`
+
+func TestBrowserScriptRecordAndRun(t *testing.T) {
+	service := startBrowserService(t)
+	router := api.NewRouter()
+	handlers.RegisterBrowserRoutes(router, service)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(scriptTestPage))
+	}))
+	defer page.Close()
+
+	name := "search-" + time.Now().Format("150405.000000")
+	name = strings.ReplaceAll(name, ".", "-")
+	t.Cleanup(func() { _ = service.DeleteScript(name) })
+
+	post := func(path string, payload any, target any) int {
+		t.Helper()
+		body, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", path, err)
+		}
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("%s request failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if target != nil {
+			var envelope struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&envelope); err == nil {
+				_ = json.Unmarshal(envelope.Data, target)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	if status := post("/v1/browser/navigate", map[string]string{"url": page.URL, "wait_until": "load"}, nil); status != http.StatusOK {
+		t.Fatalf("navigate status %d", status)
+	}
+	if status := post("/v1/browser/scripts/record/start", map[string]string{"name": name}, nil); status != http.StatusOK {
+		t.Fatalf("record start status %d", status)
+	}
+	actions := []map[string]any{
+		{"action_type": "TYPING", "selector": "#q", "text": "hello", "clear": true},
+		{"action_type": "CLICK", "selector": "#go"},
+	}
+	if status := post("/v1/browser/actions", actions, nil); status != http.StatusOK {
+		t.Fatalf("actions status %d", status)
+	}
+	// Input that does not come through the API stands in for a person on
+	// VNC and is recorded from the page.
+	time.Sleep(500 * time.Millisecond)
+	if err := service.ClickElement(browser.Locator{Selector: "#reset"}, browser.ClickOptions{}); err != nil {
+		t.Fatalf("human click: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	var script browser.Script
+	if status := post("/v1/browser/scripts/record/stop", nil, &script); status != http.StatusOK {
+		t.Fatalf("record stop status %d", status)
+	}
+	if len(script.Steps) != 4 {
+		t.Fatalf("expected navigate, two agent steps and one human step, got %+v", script.Steps)
+	}
+	if source := script.Steps[1].Source; source != browser.ScriptSourceAgent {
+		t.Fatalf("expected agent step, got %q", source)
+	}
+	if last := script.Steps[3]; last.Source != browser.ScriptSourceHuman || !strings.Contains(string(last.Action), `"#reset"`) {
+		t.Fatalf("expected recorded human click on #reset, got %+v", last)
+	}
+
+	// Parameterize the typed text and check it shows up after the click.
+	script.Steps[1].Action = json.RawMessage(`{"action_type":"TYPING","selector":"#q","text":"{{query}}","clear":true}`)
+	script.Steps[2].Assert = []browser.ScriptAssertion{{Locator: browser.Locator{Selector: "#out"}, TextContains: "{{query}}"}}
+	script.Steps = script.Steps[:3]
+	if status := post("/v1/browser/scripts", script, nil); status != http.StatusOK {
+		t.Fatalf("save status %d", status)
+	}
+
+	var run browser.ScriptRun
+	if status := post("/v1/browser/scripts/"+name+"/run", map[string]any{"variables": map[string]string{"query": "world"}}, &run); status != http.StatusOK {
+		t.Fatalf("run status %d", status)
+	}
+	if run.Status != browser.ScriptRunPassed || len(run.Steps) != 3 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	if status := post("/v1/browser/scripts/"+name+"/run", nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("expected missing variable to be rejected, got %d", status)
+	}
+
+	script.Steps[2].Assert[0].TimeoutMS = 300
+	script.Steps[2].Assert[0].TextContains = "nope"
+	if status := post("/v1/browser/scripts", script, nil); status != http.StatusOK {
+		t.Fatalf("save status %d", status)
+	}
+	run = browser.ScriptRun{}
+	if status := post("/v1/browser/scripts/"+name+"/run", map[string]any{"variables": map[string]string{"query": "world"}}, &run); status != http.StatusOK {
+		t.Fatalf("run status %d", status)
+	}
+	if run.Status != browser.ScriptRunFailed || len(run.Steps) != 3 || run.Steps[2].Screenshot == "" {
+		t.Fatalf("expected failed assertion with screenshot, got %+v", run)
+	}
+	defer os.Remove(run.Steps[2].Screenshot)
+	if _, err := os.Stat(run.Steps[2].Screenshot); err != nil {
+		t.Fatalf("failure screenshot missing: %v", err)
+	}
+}
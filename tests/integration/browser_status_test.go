@@ -0,0 +1,25 @@
+package integration
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBrowserStatus(t *testing.T) {
+	service := startBrowserService(t)
+	if err := service.Navigate("about:blank"); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	status := service.Status()
+	if !status.Running || status.Version == "" || status.StartedAt == nil {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if os.Getenv("SANDBOX_BROWSER_CDP") == "" && (status.PID == 0 || status.Binary == "") {
+		t.Fatalf("launched browser without pid or binary: %+v", status)
+	}
+
+	service.Close()
+	if status := service.Status(); status.Running {
+		t.Fatalf("browser still running after close: %+v", status)
+	}
+}
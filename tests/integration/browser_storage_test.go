@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/browser"
+	"open-sandbox/internal/config"
+)
+
+func TestBrowserStorageState(t *testing.T) {
+	if err := config.EnsureWorkspace(); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>storage</body></html>`))
+	}))
+	defer page.Close()
+
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	if err := service.SetCookies([]browser.Cookie{{Name: "sid", Value: "abc", URL: page.URL}}); err != nil {
+		t.Fatalf("set cookies: %v", err)
+	}
+	storage, err := service.UpdateStorage(browser.StorageUpdate{Items: map[string]string{"token": "t1"}})
+	if err != nil {
+		t.Fatalf("set storage: %v", err)
+	}
+	if storage.Items["token"] != "t1" || storage.Origin != page.URL {
+		t.Fatalf("unexpected storage: %+v", storage)
+	}
+
+	statePath := filepath.Join(config.WorkspacePath(), "auth", "state.json")
+	t.Cleanup(func() { _ = os.RemoveAll(filepath.Dir(statePath)) })
+	saved, err := service.SaveStorageState(statePath)
+	if err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+	if saved.Cookies < 1 || saved.Origins != 1 {
+		t.Fatalf("unexpected save result: %+v", saved)
+	}
+
+	if _, err := service.ClearCookies(browser.CookieFilter{}); err != nil {
+		t.Fatalf("clear cookies: %v", err)
+	}
+	if _, err := service.UpdateStorage(browser.StorageUpdate{Clear: true}); err != nil {
+		t.Fatalf("clear storage: %v", err)
+	}
+	if cookies, _ := service.Cookies([]string{page.URL}); len(cookies) != 0 {
+		t.Fatalf("cookies not cleared: %+v", cookies)
+	}
+
+	if _, err := service.LoadStorageStateFile(statePath); err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	cookies, err := service.Cookies([]string{page.URL})
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Fatalf("cookies not restored: %+v (%v)", cookies, err)
+	}
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	storage, err = service.Storage(browser.StorageKindLocal, "")
+	if err != nil || storage.Items["token"] != "t1" {
+		t.Fatalf("storage not restored: %+v (%v)", storage, err)
+	}
+}
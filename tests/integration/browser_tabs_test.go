@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserTabIDsAndPopups(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>tabs</title></head><body></body></html>`))
+	}))
+	defer page.Close()
+
+	events, cancel := service.Subscribe()
+	defer cancel()
+
+	first, err := service.NewTab(page.URL)
+	if err != nil {
+		t.Fatalf("new tab: %v", err)
+	}
+	second, err := service.NewTab("")
+	if err != nil {
+		t.Fatalf("new tab: %v", err)
+	}
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("expected distinct tab ids, got %q and %q", first.ID, second.ID)
+	}
+	waitForBrowserEvent(t, events, browser.EventTabCreated, second.ID)
+
+	// Closing an earlier tab must not change what an id refers to.
+	if err := service.CloseTab(browser.TabAt(0)); err != nil {
+		t.Fatalf("close first tab: %v", err)
+	}
+	switched, err := service.SwitchTab(browser.TabRef(first.ID))
+	if err != nil || switched.ID != first.ID {
+		t.Fatalf("switch by id: %+v, %v", switched, err)
+	}
+
+	if _, err := service.Evaluate(`window.open("` + page.URL + `/popup")`); err != nil {
+		t.Fatalf("open popup: %v", err)
+	}
+	popup := waitForBrowserEvent(t, events, browser.EventTabCreated, "")
+	for popup.OpenerID != first.ID {
+		popup = waitForBrowserEvent(t, events, browser.EventTabCreated, "")
+	}
+	tabs, err := service.TabList()
+	if err != nil {
+		t.Fatalf("tab list: %v", err)
+	}
+	found := false
+	for _, tab := range tabs {
+		if tab.ID == popup.TabID && tab.OpenerID == first.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("popup not tracked: %+v", tabs)
+	}
+
+	if err := service.CloseTab(browser.TabRef(popup.TabID)); err != nil {
+		t.Fatalf("close popup: %v", err)
+	}
+	waitForBrowserEvent(t, events, browser.EventTabClosed, popup.TabID)
+}
+
+func waitForBrowserEvent(t *testing.T, events <-chan browser.Event, kind, tabID string) browser.Event {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == kind && (tabID == "" || event.TabID == tabID) {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event", kind)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserUploadFiles(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+<input id="single" type="file">
+<input id="hidden" type="file" multiple style="display:none">
+<button id="pick" onclick="document.getElementById('hidden').click()">Attach</button>
+<button id="noop">Nothing</button>
+</body></html>`))
+	}))
+	defer page.Close()
+	if err := service.Navigate(page.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	if err := service.UploadFiles(browser.Locator{Selector: "#single"}, []string{first}); err != nil {
+		t.Fatalf("upload into input: %v", err)
+	}
+	names, err := service.Evaluate(`Array.from(document.getElementById('single').files).map(f => f.name).join(',')`)
+	if err != nil || names != "first.txt" {
+		t.Fatalf("unexpected input files: %v, %v", names, err)
+	}
+	if err := service.UploadFiles(browser.Locator{Selector: "#single"}, []string{first, second}); err == nil {
+		t.Fatalf("expected two files on a single file input to fail")
+	}
+
+	if err := service.UploadFiles(browser.Locator{Selector: "#pick"}, []string{first, second}); err != nil {
+		t.Fatalf("upload through file chooser: %v", err)
+	}
+	names, err = service.Evaluate(`Array.from(document.getElementById('hidden').files).map(f => f.name).join(',')`)
+	if err != nil || names != "first.txt,second.txt" {
+		t.Fatalf("unexpected chooser files: %v, %v", names, err)
+	}
+
+	if err := service.UploadFiles(browser.Locator{Selector: "#noop"}, []string{first}); err == nil {
+		t.Fatalf("expected a click that opens no chooser to fail")
+	}
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserWaits(t *testing.T) {
+	service := startBrowserService(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.js" {
+			time.Sleep(300 * time.Millisecond)
+			w.Header().Set("Content-Type", "text/javascript")
+			_, _ = w.Write([]byte(`window.slowLoaded = true;`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+<div id="spinner">loading</div>
+<script src="/slow.js"></script>
+<script>
+setTimeout(() => {
+  document.getElementById('spinner').remove();
+  const done = document.createElement('p');
+  done.id = 'done';
+  done.textContent = 'ready';
+  document.body.appendChild(done);
+  history.pushState({}, '', '/finished');
+}, 300);
+</script>
+</body></html>`))
+	}))
+	defer page.Close()
+
+	if err := service.NavigateWithOptions(page.URL, browser.NavigateOptions{WaitUntil: browser.WaitUntilLoad}); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	loaded, err := service.Evaluate(`window.slowLoaded === true`)
+	if err != nil || loaded != true {
+		t.Fatalf("load did not wait for scripts: %v, %v", loaded, err)
+	}
+
+	if err := service.WaitForSelector(browser.Locator{Selector: "#done"}, browser.ElementStateVisible); err != nil {
+		t.Fatalf("wait for visible: %v", err)
+	}
+	if err := service.WaitForSelector(browser.Locator{Selector: "#spinner"}, browser.ElementStateDetached); err != nil {
+		t.Fatalf("wait for detached: %v", err)
+	}
+	url, err := service.WaitForURL("*/finished", time.Second)
+	if err != nil || url != page.URL+"/finished" {
+		t.Fatalf("wait for url: %q, %v", url, err)
+	}
+	value, err := service.WaitForFunction(`() => document.getElementById('done').textContent`, time.Second)
+	if err != nil || value != "ready" {
+		t.Fatalf("wait for function: %v, %v", value, err)
+	}
+	if err := service.WaitForLoadState(browser.WaitUntilNetworkIdle, 5*time.Second); err != nil {
+		t.Fatalf("wait for network idle: %v", err)
+	}
+
+	_, err = service.WaitForFunction(`false`, 200*time.Millisecond)
+	if !errors.Is(err, browser.ErrWaitTimeout) {
+		t.Fatalf("expected wait timeout, got %v", err)
+	}
+
+	// A promise that never settles times out with the wait and leaves the
+	// browser running.
+	pid := service.Status().PID
+	started := time.Now()
+	_, err = service.WaitForFunction(`() => new Promise(() => {})`, 300*time.Millisecond)
+	if !errors.Is(err, browser.ErrWaitTimeout) || time.Since(started) > 2*time.Second {
+		t.Fatalf("expected wait timeout after %v, got %v", time.Since(started), err)
+	}
+	if service.Status().PID != pid {
+		t.Fatalf("browser was restarted by the wait")
+	}
+}
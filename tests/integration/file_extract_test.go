@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/api/handlers"
+	"open-sandbox/internal/config"
+)
+
+func TestFileExtractRejectsOversizedUpload(t *testing.T) {
+	if err := config.EnsureWorkspace(); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	t.Setenv("SANDBOX_UPLOAD_MAX_BYTES", "1024")
+
+	router := api.NewRouter()
+	handlers.RegisterFileRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("target", filepath.Join(config.WorkspacePath(), "extract-too-large"))
+	part, err := writer.CreateFormFile("file", "big.zip")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	_, _ = part.Write(bytes.Repeat([]byte("x"), 4096))
+	_ = writer.Close()
+
+	resp, err := http.Post(server.URL+"/v1/file/extract", writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("extract request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", resp.StatusCode)
+	}
+}
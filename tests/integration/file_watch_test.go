@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/api"
+	"open-sandbox/internal/api/handlers"
+	"open-sandbox/internal/config"
+	"open-sandbox/internal/file"
+)
+
+func TestFileWatchStreamsEvents(t *testing.T) {
+	if err := config.EnsureWorkspace(); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	watchDir := filepath.Join(config.WorkspacePath(), "watch-test")
+	_ = os.RemoveAll(watchDir)
+	if err := os.MkdirAll(watchDir, 0755); err != nil {
+		t.Fatalf("create watch dir: %v", err)
+	}
+	defer os.RemoveAll(watchDir)
+
+	router := api.NewRouter()
+	handlers.RegisterFileRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	query := url.Values{}
+	query.Set("path", watchDir)
+	query.Set("recursive", "true")
+	query.Set("debounce_ms", "50")
+	resp, err := http.Get(server.URL + "/v1/file/watch?" + query.Encode())
+	if err != nil {
+		t.Fatalf("watch request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("watch status %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	waitForSSEEvent(t, lines, "ready")
+	target := filepath.Join(watchDir, "created.txt")
+	if err := os.WriteFile(target, []byte("data"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	data := waitForSSEEvent(t, lines, file.WatchCreate)
+	var event file.WatchEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.Path != target {
+		t.Fatalf("expected path %q, got %q", target, event.Path)
+	}
+}
+
+func waitForSSEEvent(t *testing.T, lines <-chan string, name string) string {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	current := ""
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("stream closed before %q event", name)
+			}
+			if strings.HasPrefix(line, "event: ") {
+				current = strings.TrimPrefix(line, "event: ")
+				continue
+			}
+			if current == name && strings.HasPrefix(line, "data: ") {
+				return strings.TrimPrefix(line, "data: ")
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q event", name)
+		}
+	}
+}
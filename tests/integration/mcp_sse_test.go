@@ -157,6 +157,8 @@ func TestMCPSSENotificationNoResponse(t *testing.T) {
 func parseSSEPayload(t *testing.T, resp *http.Response) mcp.Response {
 	t.Helper()
 	scanner := bufio.NewScanner(resp.Body)
+	// The capabilities payload lists every tool schema on one data line.
+	scanner.Buffer(nil, 1<<20)
 	var dataLine string
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserContexts(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+
+	main, err := service.Context("")
+	if err != nil || main.Name() != browser.DefaultContextName {
+		t.Fatalf("empty name should select the default context, got %v, %v", main, err)
+	}
+	if alias, _ := service.Context(browser.DefaultContextName); alias != main {
+		t.Fatalf("default name should select the default context")
+	}
+	if _, err := service.Context("bad/name"); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected invalid option for bad name, got %v", err)
+	}
+
+	if _, err := service.Context("agent-1"); !errors.Is(err, browser.ErrContextNotFound) {
+		t.Fatalf("unknown names should not be created on lookup, got %v", err)
+	}
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "agent-1"}); err != nil {
+		t.Fatalf("create agent-1: %v", err)
+	}
+	agent, err := service.Context("agent-1")
+	if err != nil || agent.Name() != "agent-1" {
+		t.Fatalf("lookup after create: %v, %v", agent, err)
+	}
+	if again, _ := service.Context("agent-1"); again != agent {
+		t.Fatalf("lookup should return the existing context")
+	}
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "agent-1"}); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected duplicate create to fail, got %v", err)
+	}
+	info, err := service.CreateContext(browser.ContextOptions{Name: "agent-2", IdleTimeout: time.Hour})
+	if err != nil || info.ExpiresAt == nil {
+		t.Fatalf("create agent-2: %+v, %v", info, err)
+	}
+
+	contexts := service.Contexts()
+	if len(contexts) != 3 || contexts[0].Name != browser.DefaultContextName || contexts[1].Name != "agent-1" || contexts[2].Name != "agent-2" {
+		t.Fatalf("unexpected contexts: %+v", contexts)
+	}
+	if contexts[0].ExpiresAt != nil {
+		t.Fatalf("default context should not expire")
+	}
+
+	if err := service.CloseContext(browser.DefaultContextName); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected default close to fail, got %v", err)
+	}
+	if err := service.CloseContext("agent-1"); err != nil {
+		t.Fatalf("close agent-1: %v", err)
+	}
+	if err := service.CloseContext("agent-1"); !errors.Is(err, browser.ErrContextNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := agent.Snapshot(browser.SnapshotOptions{}); !errors.Is(err, browser.ErrContextNotFound) {
+		t.Fatalf("closed context should reject use, got %v", err)
+	}
+}
+
+func TestBrowserContextExpiry(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "short", IdleTimeout: time.Millisecond}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	for _, info := range service.Contexts() {
+		if info.Name == "short" {
+			t.Fatalf("idle context should have expired")
+		}
+	}
+}
+
+func TestBrowserContextReaper(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "short", IdleTimeout: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	short, err := service.Context("short")
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		// Snapshot does not look contexts up, so only the reaper can close it.
+		if _, err := short.Snapshot(browser.SnapshotOptions{}); errors.Is(err, browser.ErrContextNotFound) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("idle context was not closed without a lookup")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
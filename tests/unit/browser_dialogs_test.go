@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestDialogPolicy(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	if policy := service.DialogPolicy(); policy != browser.DialogPolicyAccept {
+		t.Fatalf("expected accept by default, got %q", policy)
+	}
+	if err := service.SetDialogPolicy("ignore"); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected invalid option, got %v", err)
+	}
+	if err := service.SetDialogPolicy(browser.DialogPolicyWait); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	if policy := service.DialogPolicy(); policy != browser.DialogPolicyWait {
+		t.Fatalf("expected wait, got %q", policy)
+	}
+
+	config := browser.DefaultConfig()
+	config.DialogPolicy = browser.DialogPolicyDismiss
+	if policy := browser.NewService(config).DialogPolicy(); policy != browser.DialogPolicyDismiss {
+		t.Fatalf("expected configured dismiss, got %q", policy)
+	}
+}
+
+func TestHandleDialogWithoutDialog(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	if _, err := service.HandleDialog("", true, ""); !errors.Is(err, browser.ErrNoDialog) {
+		t.Fatalf("expected no dialog, got %v", err)
+	}
+	if dialogs := service.Dialogs(); len(dialogs) != 0 {
+		t.Fatalf("expected no dialogs, got %+v", dialogs)
+	}
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestEmulationOptionsValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	cases := []struct {
+		name    string
+		options browser.EmulationOptions
+	}{
+		{"unknown device", browser.EmulationOptions{Device: "nokia-3310"}},
+		{"empty viewport", browser.EmulationOptions{Viewport: &browser.Viewport{Width: 0, Height: 600}}},
+		{"negative scale", browser.EmulationOptions{DeviceScaleFactor: -1}},
+		{"latitude range", browser.EmulationOptions{Geolocation: &browser.Geolocation{Latitude: 91}}},
+		{"longitude range", browser.EmulationOptions{Geolocation: &browser.Geolocation{Longitude: -181}}},
+		{"color scheme", browser.EmulationOptions{ColorScheme: "sepia"}},
+		{"reduced motion", browser.EmulationOptions{ReducedMotion: "none"}},
+		{"network profile", browser.EmulationOptions{Network: "5g"}},
+	}
+	for _, tc := range cases {
+		if _, err := service.Emulate("", tc.options); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("%s: expected invalid option, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestDevicePresets(t *testing.T) {
+	devices := browser.Devices()
+	for _, name := range []string{"iphone-14", "pixel-7", "ipad-mini", "desktop-fhd"} {
+		device, ok := devices[name]
+		if !ok {
+			t.Fatalf("missing preset %q", name)
+		}
+		if device.Width <= 0 || device.Height <= 0 || device.DeviceScaleFactor <= 0 || device.UserAgent == "" {
+			t.Fatalf("incomplete preset %q: %+v", name, device)
+		}
+	}
+	if !devices["pixel-7"].Mobile || !devices["pixel-7"].Touch || devices["desktop-fhd"].Mobile {
+		t.Fatalf("unexpected mobile flags: %+v", devices)
+	}
+	delete(devices, "pixel-7")
+	if _, ok := browser.Devices()["pixel-7"]; !ok {
+		t.Fatalf("Devices must return a copy")
+	}
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBuildHAR(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []browser.NetworkEntry{
+		{
+			RequestID:       "1",
+			URL:             "https://example.com/api?q=go&page=2",
+			Method:          "GET",
+			Status:          200,
+			StatusText:      "OK",
+			MimeType:        "application/json",
+			Protocol:        "h2",
+			RequestHeaders:  map[string]string{"Accept": "application/json"},
+			ResponseHeaders: map[string]string{"Set-Cookie": "a=1\nb=2"},
+			StartedAt:       started,
+			DurationMS:      42.5,
+			EncodedSize:     120,
+			Size:            11,
+			Finished:        true,
+			Timing:          &browser.NetworkTiming{Blocked: 1, DNS: -1, Connect: -1, SSL: -1, Send: 0.5, Wait: 30, Receive: 11},
+		},
+		{
+			RequestID: "2",
+			URL:       "https://example.com/missing.js",
+			Method:    "GET",
+			StartedAt: started,
+			Finished:  true,
+			Failed:    true,
+			ErrorText: "net::ERR_NAME_NOT_RESOLVED",
+		},
+	}
+	bodies := map[string]browser.ResponseBody{"1": {RequestID: "1", Body: `{"ok":true}`, Size: 11}}
+
+	har := browser.BuildHAR(entries, bodies)
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 2 {
+		t.Fatalf("unexpected har log: %+v", har.Log)
+	}
+	first := har.Log.Entries[0]
+	if first.Request.HTTPVersion != "HTTP/2" || first.StartedDateTime != "2024-05-01T12:00:00Z" {
+		t.Fatalf("unexpected request: %+v", first)
+	}
+	if len(first.Request.QueryString) != 2 || first.Request.QueryString[0].Name != "page" {
+		t.Fatalf("unexpected query string: %+v", first.Request.QueryString)
+	}
+	if len(first.Response.Headers) != 2 || first.Response.Content.Text != `{"ok":true}` || first.Timings.Wait != 30 {
+		t.Fatalf("unexpected response: %+v", first.Response)
+	}
+	second := har.Log.Entries[1]
+	if second.Comment != "net::ERR_NAME_NOT_RESOLVED" || second.Response.BodySize != -1 || second.Timings.DNS != -1 {
+		t.Fatalf("unexpected failed entry: %+v", second)
+	}
+	// HAR 1.2 allows -1 for header and body sizes, but content.size must be set.
+	if first.Response.Content.Size != 11 || second.Response.Content.Size != 0 {
+		t.Fatalf("unexpected content sizes: %d, %d", first.Response.Content.Size, second.Response.Content.Size)
+	}
+
+	data, err := json.Marshal(har)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := decoded["log"].(map[string]any)["creator"]; !ok {
+		t.Fatalf("har is missing creator: %s", data)
+	}
+}
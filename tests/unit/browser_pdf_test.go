@@ -0,0 +1,28 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestPDFOptionsValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	cases := []struct {
+		name    string
+		options browser.PDFOptions
+	}{
+		{"missing path", browser.PDFOptions{}},
+		{"unknown format", browser.PDFOptions{Path: "/tmp/a.pdf", Format: "b5"}},
+		{"negative width", browser.PDFOptions{Path: "/tmp/a.pdf", Width: -1}},
+		{"negative margin", browser.PDFOptions{Path: "/tmp/a.pdf", Margin: &browser.PDFMargin{Top: -0.5}}},
+		{"bad page ranges", browser.PDFOptions{Path: "/tmp/a.pdf", PageRanges: "first"}},
+		{"scale range", browser.PDFOptions{Path: "/tmp/a.pdf", Scale: 3}},
+	}
+	for _, tc := range cases {
+		if _, err := service.PrintToPDF(tc.options); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("%s: expected invalid option, got %v", tc.name, err)
+		}
+	}
+}
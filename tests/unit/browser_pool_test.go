@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserPoolLookup(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+
+	for _, id := range []string{"", browser.DefaultBrowserID} {
+		if got, err := service.Browser(id); err != nil || got != service {
+			t.Fatalf("%q should select the default browser, got %v, %v", id, got, err)
+		}
+	}
+	if _, err := service.Browser("browser-9"); !errors.Is(err, browser.ErrBrowserNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := service.CreateContext(browser.ContextOptions{Name: "agent-1"}); err != nil {
+		t.Fatalf("create context: %v", err)
+	}
+	scoped, err := service.Context("agent-1")
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	if got, _ := scoped.Browser(""); got != service {
+		t.Fatalf("named context should resolve the default browser")
+	}
+
+	browsers := service.Browsers()
+	if len(browsers) != 1 || browsers[0].ID != browser.DefaultBrowserID || browsers[0].LeasedAt != nil {
+		t.Fatalf("unexpected browsers: %+v", browsers)
+	}
+	if err := service.ReleaseBrowser(browser.DefaultBrowserID); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected default release to fail, got %v", err)
+	}
+	if err := service.ReleaseBrowser("browser-9"); !errors.Is(err, browser.ErrBrowserNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestBrowserPoolLease(t *testing.T) {
+	config := browser.DefaultConfig()
+	config.BinaryPath = filepath.Join(t.TempDir(), "missing-chrome")
+	profiles := t.TempDir()
+	config.UserDataDir = filepath.Join(profiles, "profile")
+	config.PoolMaxInstances = 2
+	service := browser.NewService(config)
+
+	if _, err := service.LeaseBrowser(browser.LeaseOptions{Session: "bad/session"}); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected invalid session, got %v", err)
+	}
+	if _, err := service.LeaseBrowser(browser.LeaseOptions{Session: "agent-1"}); err == nil {
+		t.Fatalf("lease should fail when the browser cannot start")
+	}
+	if browsers := service.Browsers(); len(browsers) != 1 {
+		t.Fatalf("failed lease should not stay in the pool: %+v", browsers)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(profiles, "profile-browser-*")); len(leftover) != 0 {
+		t.Fatalf("failed lease should delete its profile: %v", leftover)
+	}
+
+	config.PoolMaxInstances = 1
+	capped := browser.NewService(config)
+	if _, err := capped.LeaseBrowser(browser.LeaseOptions{}); !errors.Is(err, browser.ErrPoolExhausted) {
+		t.Fatalf("expected pool exhausted, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestDetectBinaryOnPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stand-in needs a unix PATH")
+	}
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "chrome-headless-shell")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("write fake browser: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "chromium"), []byte("not executable"), 0644); err != nil {
+		t.Fatalf("write non-executable: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("PLAYWRIGHT_BROWSERS_PATH", "")
+
+	if got := browser.DetectBinary(); got != fake {
+		t.Fatalf("expected %s, got %q", fake, got)
+	}
+}
+
+func TestDetectBinaryPlaywrightCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("playwright layout differs on windows")
+	}
+	root := t.TempDir()
+	for _, revision := range []string{"chromium-1100", "chromium-1200"} {
+		dir := filepath.Join(root, revision, "chrome-linux")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "chrome"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("write fake browser: %v", err)
+		}
+	}
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("PLAYWRIGHT_BROWSERS_PATH", root)
+
+	want := filepath.Join(root, "chromium-1200", "chrome-linux", "chrome")
+	if got := browser.DetectBinary(); got != want && !isSystemBrowser(got) {
+		t.Fatalf("expected newest revision %s, got %q", want, got)
+	}
+}
+
+// isSystemBrowser accepts a browser installed in a standard location,
+// which is found before the Playwright cache.
+func isSystemBrowser(path string) bool {
+	return path != "" && (filepath.Dir(path) == "/usr/bin" || filepath.Dir(path) == "/snap/bin" || strings.HasPrefix(path, "/opt/"))
+}
+
+func TestStatusBeforeStart(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	status := service.Status()
+	if status.Running || status.PID != 0 || status.Restarts != 0 || status.Crashes != 0 {
+		t.Fatalf("unexpected status before start: %+v", status)
+	}
+}
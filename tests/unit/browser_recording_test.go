@@ -0,0 +1,31 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestRecordingOptionsValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	cases := []struct {
+		name    string
+		options browser.RecordingOptions
+	}{
+		{"unknown format", browser.RecordingOptions{Format: "avi"}},
+		{"quality range", browser.RecordingOptions{Quality: 120}},
+		{"negative size", browser.RecordingOptions{MaxWidth: -1}},
+	}
+	for _, tc := range cases {
+		if _, err := service.StartRecording(tc.options); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("%s: expected invalid option, got %v", tc.name, err)
+		}
+	}
+	if _, err := service.StopRecording(); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Errorf("stop without recording: expected invalid option, got %v", err)
+	}
+	if recordings := service.Recordings(); len(recordings) != 0 {
+		t.Errorf("expected no recordings, got %+v", recordings)
+	}
+}
@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestMatchURLGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*", "https://example.com/", true},
+		{"https://example.com/api/*", "https://example.com/api/users/1", true},
+		{"https://example.com/api/*", "https://example.com/static/app.js", false},
+		{"*.png", "https://cdn.example.com/img/logo.png", true},
+		{"*.png", "https://cdn.example.com/img/logo.png?v=2", false},
+		{"*/item?", "https://example.com/item7", true},
+		{"*/item?", "https://example.com/item", false},
+		{"https://example.com/a+b(c)", "https://example.com/a+b(c)", true},
+		{"https://example.com/a.b", "https://example.com/aXb", false},
+	}
+	for _, tc := range cases {
+		if got := browser.MatchURLGlob(tc.pattern, tc.url); got != tc.want {
+			t.Errorf("MatchURLGlob(%q, %q) = %v, want %v", tc.pattern, tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestRouteRules(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+
+	first, err := service.AddRoute(browser.RouteRule{URL: "*/api/*", Body: `{"mock":true}`})
+	if err != nil {
+		t.Fatalf("add fulfill route: %v", err)
+	}
+	if first.ID == "" || first.Action != browser.RouteActionFulfill || first.Status != 200 {
+		t.Fatalf("unexpected defaults: %+v", first)
+	}
+	blocked, err := service.AddRoute(browser.RouteRule{URL: "*.png", Action: browser.RouteActionAbort, Method: "get"})
+	if err != nil {
+		t.Fatalf("add abort route: %v", err)
+	}
+	if blocked.ErrorReason != "BlockedByClient" || blocked.Method != "GET" {
+		t.Fatalf("unexpected abort defaults: %+v", blocked)
+	}
+
+	for _, rule := range []browser.RouteRule{
+		{},
+		{URL: "*", Action: "redirect"},
+		{URL: "*", Status: 42},
+		{URL: "*", Action: browser.RouteActionAbort, ErrorReason: "NoSuchReason"},
+		{URL: "*", BodyFile: "/does/not/exist"},
+	} {
+		if _, err := service.AddRoute(rule); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("AddRoute(%+v) error = %v, want ErrInvalidOption", rule, err)
+		}
+	}
+
+	if routes := service.Routes(); len(routes) != 2 || routes[0].ID != first.ID {
+		t.Fatalf("unexpected routes: %+v", routes)
+	}
+	if err := service.RemoveRoute(first.ID); err != nil {
+		t.Fatalf("remove route: %v", err)
+	}
+	if err := service.RemoveRoute(first.ID); !errors.Is(err, browser.ErrRouteNotFound) {
+		t.Fatalf("second remove error = %v", err)
+	}
+	service.ClearRoutes()
+	if routes := service.Routes(); len(routes) != 0 {
+		t.Fatalf("routes not cleared: %+v", routes)
+	}
+}
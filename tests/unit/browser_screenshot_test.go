@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestScreenshotOptionsValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	cases := []struct {
+		name    string
+		options browser.ScreenshotOptions
+	}{
+		{"unknown format", browser.ScreenshotOptions{Format: "gif"}},
+		{"png quality", browser.ScreenshotOptions{Quality: 80}},
+		{"quality range", browser.ScreenshotOptions{Format: "jpeg", Quality: 101}},
+		{"scale range", browser.ScreenshotOptions{Scale: 10}},
+		{"empty clip", browser.ScreenshotOptions{Clip: &browser.ClipRect{Width: 0, Height: 10}}},
+		{"full page and clip", browser.ScreenshotOptions{FullPage: true, Clip: &browser.ClipRect{Width: 10, Height: 10}}},
+		{"full page and element", browser.ScreenshotOptions{FullPage: true, Element: browser.Locator{Selector: "h1"}}},
+		{"marks and element", browser.ScreenshotOptions{Marks: true, Element: browser.Locator{Selector: "h1"}}},
+	}
+	for _, tc := range cases {
+		if _, err := service.CaptureScreenshot(tc.options); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("%s: expected invalid option, got %v", tc.name, err)
+		}
+	}
+}
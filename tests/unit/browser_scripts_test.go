@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func newScriptService(t *testing.T) *browser.Service {
+	t.Helper()
+	config := browser.DefaultConfig()
+	config.ScriptDir = t.TempDir()
+	config.BinaryPath = filepath.Join(t.TempDir(), "missing-chrome")
+	return browser.NewService(config)
+}
+
+func TestScriptExpand(t *testing.T) {
+	script := browser.Script{
+		Name:      "login",
+		Variables: map[string]string{"user": "alice"},
+		Steps: []browser.ScriptStep{
+			{Action: json.RawMessage(`{"action_type":"NAVIGATE","url":"{{ base }}/login"}`)},
+			{
+				Action: json.RawMessage(`{"action_type":"TYPING","selector":"#user","text":"{{user}}"}`),
+				Assert: []browser.ScriptAssertion{{Locator: browser.Locator{Selector: "#user"}, TextContains: "{{user}}"}},
+			},
+		},
+	}
+	if names := script.VariableNames(); !reflect.DeepEqual(names, []string{"base", "user"}) {
+		t.Fatalf("unexpected variables: %v", names)
+	}
+	if _, err := script.Expand(nil); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+
+	steps, err := script.Expand(map[string]string{"base": `https://example.com/"q"`, "user": "bob"})
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	var navigate map[string]string
+	if err := json.Unmarshal(steps[0].Action, &navigate); err != nil || navigate["url"] != `https://example.com/"q"/login` {
+		t.Fatalf("unexpected navigate step: %s, %v", steps[0].Action, err)
+	}
+	var typing map[string]string
+	if err := json.Unmarshal(steps[1].Action, &typing); err != nil || typing["text"] != "bob" {
+		t.Fatalf("passed variable should win over the default: %s", steps[1].Action)
+	}
+	if steps[1].Assert[0].TextContains != "bob" || steps[1].Assert[0].Selector != "#user" {
+		t.Fatalf("assertions should be expanded: %+v", steps[1].Assert[0])
+	}
+	if string(script.Steps[1].Action) != `{"action_type":"TYPING","selector":"#user","text":"{{user}}"}` {
+		t.Fatalf("expand should not change the script: %s", script.Steps[1].Action)
+	}
+}
+
+func TestScriptStorage(t *testing.T) {
+	service := newScriptService(t)
+
+	if err := service.SaveScript(&browser.Script{Name: "bad/name"}); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected invalid name, got %v", err)
+	}
+	noType := &browser.Script{Name: "broken", Steps: []browser.ScriptStep{{Action: json.RawMessage(`{"x":1}`)}}}
+	if err := service.SaveScript(noType); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected missing action_type to fail, got %v", err)
+	}
+	byRef := &browser.Script{Name: "broken", Steps: []browser.ScriptStep{{
+		Action: json.RawMessage(`{"action_type":"WAIT","duration":1}`),
+		Assert: []browser.ScriptAssertion{{Locator: browser.Locator{Ref: "e1"}}},
+	}}}
+	if err := service.SaveScript(byRef); !errors.Is(err, browser.ErrInvalidLocator) {
+		t.Fatalf("expected ref assertion to fail, got %v", err)
+	}
+	for _, action := range []string{
+		`{"action_type":"CLICK","ref":"e1"}`,
+		`{"action_type":"TYPING","element":3,"text":"hi"}`,
+	} {
+		byElement := &browser.Script{Name: "broken", Steps: []browser.ScriptStep{{Action: json.RawMessage(action)}}}
+		if err := service.SaveScript(byElement); !errors.Is(err, browser.ErrInvalidLocator) {
+			t.Fatalf("expected %s to fail, got %v", action, err)
+		}
+	}
+
+	script := &browser.Script{
+		Name:  "search",
+		Steps: []browser.ScriptStep{{Action: json.RawMessage(`{"action_type":"TYPING","selector":"#q","text":"{{query}}"}`)}},
+	}
+	if err := service.SaveScript(script); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := service.LoadScript("search")
+	if err != nil || len(loaded.Steps) != 1 || loaded.CreatedAt.IsZero() {
+		t.Fatalf("load: %+v, %v", loaded, err)
+	}
+	scripts, err := service.Scripts()
+	if err != nil || len(scripts) != 1 || scripts[0].Name != "search" || !reflect.DeepEqual(scripts[0].Variables, []string{"query"}) {
+		t.Fatalf("unexpected scripts: %+v, %v", scripts, err)
+	}
+
+	if err := service.DeleteScript("search"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.LoadScript("search"); !errors.Is(err, browser.ErrScriptNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := service.DeleteScript("search"); !errors.Is(err, browser.ErrScriptNotFound) {
+		t.Fatalf("expected not found on second delete, got %v", err)
+	}
+}
+
+func TestScriptRunStopsAtFailure(t *testing.T) {
+	service := newScriptService(t)
+	script := &browser.Script{
+		Name:      "steps",
+		Variables: map[string]string{"key": "Enter"},
+		Steps: []browser.ScriptStep{
+			{Action: json.RawMessage(`{"action_type":"PRESS","key":"{{key}}"}`)},
+			{Action: json.RawMessage(`{"action_type":"FAIL"}`)},
+			{Action: json.RawMessage(`{"action_type":"PRESS","key":"Tab"}`)},
+		},
+	}
+	if err := service.SaveScript(script); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	var performed []string
+	execute := func(_ *browser.Service, action json.RawMessage) (string, error) {
+		var payload map[string]string
+		_ = json.Unmarshal(action, &payload)
+		if payload["action_type"] == "FAIL" {
+			return "", errors.New("boom")
+		}
+		performed = append(performed, payload["key"])
+		return payload["action_type"], nil
+	}
+	run, err := service.RunScript("steps", browser.ScriptRunOptions{}, execute)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if run.Status != browser.ScriptRunFailed || len(run.Steps) != 2 || run.Error == "" {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	if run.Steps[0].Status != browser.ScriptRunPassed || run.Steps[1].Action != "FAIL" || run.Steps[1].Error != "boom" {
+		t.Fatalf("unexpected steps: %+v", run.Steps)
+	}
+	if !reflect.DeepEqual(performed, []string{"Enter"}) {
+		t.Fatalf("steps after the failure should not run: %v", performed)
+	}
+
+	if _, err := service.RunScript("missing", browser.ScriptRunOptions{}, execute); !errors.Is(err, browser.ErrScriptNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := service.StopScriptRecording(); !errors.Is(err, browser.ErrInvalidOption) {
+		t.Fatalf("expected stop without recording to fail, got %v", err)
+	}
+}
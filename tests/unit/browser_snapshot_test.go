@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/cdp"
+
+	"open-sandbox/internal/browser"
+)
+
+func axNode(id string, role string, name string, backendID int, children ...string) *accessibility.Node {
+	node := &accessibility.Node{
+		NodeID:           accessibility.NodeID(id),
+		Role:             &accessibility.Value{Type: accessibility.ValueTypeRole, Value: []byte(strconv.Quote(role))},
+		BackendDOMNodeID: cdp.BackendNodeID(backendID),
+	}
+	if name != "" {
+		node.Name = &accessibility.Value{Type: accessibility.ValueTypeComputedString, Value: []byte(strconv.Quote(name))}
+	}
+	for _, child := range children {
+		node.ChildIDs = append(node.ChildIDs, accessibility.NodeID(child))
+	}
+	return node
+}
+
+func withParent(parent string, nodes ...*accessibility.Node) []*accessibility.Node {
+	for _, node := range nodes {
+		node.ParentID = accessibility.NodeID(parent)
+	}
+	return nodes
+}
+
+func TestBuildSnapshotPrunesAndAssignsRefs(t *testing.T) {
+	root := axNode("1", "RootWebArea", "Checkout", 1, "2", "7")
+	checkbox := axNode("6", "checkbox", "Agree", 6)
+	checkbox.Properties = []*accessibility.Property{{
+		Name:  accessibility.PropertyNameChecked,
+		Value: &accessibility.Value{Type: accessibility.ValueTypeTristate, Value: []byte(`"true"`)},
+	}}
+	nodes := []*accessibility.Node{root}
+	nodes = append(nodes, withParent("1",
+		axNode("2", "generic", "", 2, "3", "4", "5", "6"),
+		axNode("7", "button", "Submit order", 7, "8"),
+	)...)
+	nodes = append(nodes, withParent("2",
+		axNode("3", "heading", "Your cart", 3),
+		axNode("4", "StaticText", "Two items", 4),
+		axNode("5", "link", "Edit cart", 5),
+		checkbox,
+	)...)
+	nodes = append(nodes, withParent("7", axNode("8", "StaticText", "Submit order", 8))...)
+
+	snapshot := browser.BuildSnapshot(nodes, browser.SnapshotOptions{})
+	if snapshot.Refs != 3 {
+		t.Fatalf("expected 3 refs, got %d:\n%s", snapshot.Refs, snapshot.Text)
+	}
+	want := strings.Join([]string{
+		`- document "Checkout"`,
+		`  - heading "Your cart"`,
+		`  - text "Two items"`,
+		`  - link "Edit cart" [ref=e1]`,
+		`  - checkbox "Agree" [ref=e2] [checked=true]`,
+		`  - button "Submit order" [ref=e3]`,
+		``,
+	}, "\n")
+	if snapshot.Text != want {
+		t.Fatalf("unexpected outline:\n%s\nwant:\n%s", snapshot.Text, want)
+	}
+
+	interactive := browser.BuildSnapshot(nodes, browser.SnapshotOptions{InteractiveOnly: true})
+	if len(interactive.Tree.Children) != 3 || interactive.Tree.Children[2].Ref != "e3" {
+		t.Fatalf("unexpected interactive tree:\n%s", interactive.Text)
+	}
+}
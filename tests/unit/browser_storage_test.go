@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestParseStorageState(t *testing.T) {
+	// Shape written by Playwright's context.storageState().
+	data := []byte(`{
+  "cookies": [
+    {"name": "sid", "value": "abc", "domain": ".example.com", "path": "/", "expires": 1893456000, "httpOnly": true, "secure": true, "sameSite": "Lax"},
+    {"name": "pref", "value": "dark", "domain": "example.com", "path": "/", "expires": -1, "httpOnly": false, "secure": false, "sameSite": "None"}
+  ],
+  "origins": [
+    {"origin": "https://example.com", "localStorage": [{"name": "token", "value": "t1"}]}
+  ]
+}`)
+	state, err := browser.ParseStorageState(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(state.Cookies) != 2 || state.Cookies[0].Name != "sid" || !state.Cookies[0].HTTPOnly || state.Cookies[1].Expires != -1 {
+		t.Fatalf("unexpected cookies: %+v", state.Cookies)
+	}
+	if len(state.Origins) != 1 || state.Origins[0].LocalStorage[0].Value != "t1" {
+		t.Fatalf("unexpected origins: %+v", state.Origins)
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"cookies": [{"value": "x", "domain": "example.com"}]}`,
+		`{"cookies": [{"name": "x", "value": "y"}]}`,
+		`{"cookies": [{"name": "x", "value": "y", "domain": "example.com", "sameSite": "Sometimes"}]}`,
+		`{"origins": [{"origin": "file:///tmp", "localStorage": []}]}`,
+	}
+	for _, raw := range invalid {
+		if _, err := browser.ParseStorageState([]byte(raw)); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("ParseStorageState(%s) error = %v, want ErrInvalidOption", raw, err)
+		}
+	}
+}
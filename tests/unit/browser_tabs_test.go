@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestTabRefUnmarshal(t *testing.T) {
+	cases := []struct {
+		input string
+		want  browser.TabRef
+	}{
+		{`"8F3A1C"`, "8F3A1C"},
+		{`2`, "2"},
+		{`""`, ""},
+	}
+	for _, tc := range cases {
+		var payload struct {
+			Tab browser.TabRef `json:"tab"`
+		}
+		if err := json.Unmarshal([]byte(`{"tab":`+tc.input+`}`), &payload); err != nil {
+			t.Fatalf("unmarshal %s: %v", tc.input, err)
+		}
+		if payload.Tab != tc.want {
+			t.Errorf("unmarshal %s = %q, want %q", tc.input, payload.Tab, tc.want)
+		}
+	}
+	var ref browser.TabRef
+	if err := json.Unmarshal([]byte(`true`), &ref); err == nil {
+		t.Fatalf("expected error for boolean tab")
+	}
+	if browser.TabAt(3) != "3" {
+		t.Fatalf("unexpected TabAt result %q", browser.TabAt(3))
+	}
+}
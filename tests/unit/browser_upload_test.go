@@ -0,0 +1,35 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestUploadFilesValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(existing, []byte("report"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	locator := browser.Locator{Selector: "input[type=file]"}
+	cases := []struct {
+		name  string
+		paths []string
+	}{
+		{"no paths", nil},
+		{"relative path", []string{"report.txt"}},
+		{"missing file", []string{filepath.Join(dir, "missing.txt")}},
+		{"directory", []string{dir}},
+		{"one bad path", []string{existing, filepath.Join(dir, "missing.txt")}},
+	}
+	for _, tc := range cases {
+		if err := service.UploadFiles(locator, tc.paths); !errors.Is(err, browser.ErrInvalidOption) {
+			t.Errorf("%s: expected invalid option, got %v", tc.name, err)
+		}
+	}
+}
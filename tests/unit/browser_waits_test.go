@@ -0,0 +1,25 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"open-sandbox/internal/browser"
+)
+
+func TestBrowserWaitValidation(t *testing.T) {
+	service := browser.NewService(browser.DefaultConfig())
+	checks := map[string]error{
+		"navigate wait_until": service.NavigateWithOptions("about:blank", browser.NavigateOptions{WaitUntil: "idle"}),
+		"load state commit":   service.WaitForLoadState(browser.WaitUntilCommit, 0),
+		"selector state":      service.WaitForSelector(browser.Locator{Selector: "h1"}, "present"),
+		"selector locator":    service.WaitForSelector(browser.Locator{}, browser.ElementStateVisible),
+	}
+	_, checks["empty url"] = service.WaitForURL(" ", 0)
+	_, checks["empty expression"] = service.WaitForFunction("", 0)
+	for name, err := range checks {
+		if !errors.Is(err, browser.ErrInvalidOption) && !errors.Is(err, browser.ErrInvalidLocator) {
+			t.Errorf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
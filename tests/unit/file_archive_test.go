@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"open-sandbox/internal/file"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, format := range []string{file.FormatZip, file.FormatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			root := t.TempDir()
+			workspace := filepath.Join(root, "workspace")
+			writeTestFile(t, filepath.Join(workspace, "src", "main.go"), "package main")
+			writeTestFile(t, filepath.Join(workspace, "src", "util", "util.go"), "package util")
+			writeTestFile(t, filepath.Join(workspace, "docs", "readme.md"), "docs")
+			writeTestFile(t, filepath.Join(workspace, "notes.txt"), "skip")
+
+			output := filepath.Join(workspace, "out", "bundle."+format)
+			result, err := file.ArchiveToFile(output, file.ArchiveOptions{
+				Format: format,
+				Root:   workspace,
+				Paths:  []string{filepath.Join(workspace, "docs")},
+				Globs:  []string{"src/**/*.go"},
+			})
+			if err != nil {
+				t.Fatalf("archive: %v", err)
+			}
+			if result.Entries != 3 {
+				t.Fatalf("expected 3 entries, got %+v", result)
+			}
+
+			target := filepath.Join(root, "extracted")
+			extracted, err := file.Extract(output, target, file.ExtractOptions{})
+			if err != nil {
+				t.Fatalf("extract: %v", err)
+			}
+			if extracted.Entries != 3 {
+				t.Fatalf("expected 3 extracted entries, got %+v", extracted)
+			}
+			assertFileContent(t, filepath.Join(target, "src", "main.go"), "package main")
+			assertFileContent(t, filepath.Join(target, "src", "util", "util.go"), "package util")
+			assertFileContent(t, filepath.Join(target, "docs", "readme.md"), "docs")
+			if _, err := os.Stat(filepath.Join(target, "notes.txt")); !os.IsNotExist(err) {
+				t.Fatalf("expected notes.txt to be excluded, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractRejectsZipSlipAndLimits(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+
+	slip := filepath.Join(root, "slip.zip")
+	writeTestZip(t, slip, map[string]string{"../escape.txt": "owned"})
+	if _, err := file.Extract(slip, target, file.ExtractOptions{}); !errors.Is(err, file.ErrUnsafeEntry) {
+		t.Fatalf("expected unsafe entry error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); !os.IsNotExist(err) {
+		t.Fatalf("zip-slip entry was written: %v", err)
+	}
+
+	large := filepath.Join(root, "large.zip")
+	writeTestZip(t, large, map[string]string{"a.txt": "0123456789"})
+	if _, err := file.Extract(large, target, file.ExtractOptions{MaxBytes: 5}); !errors.Is(err, file.ErrArchiveTooLarge) {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+
+	many := filepath.Join(root, "many.zip")
+	writeTestZip(t, many, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+	if _, err := file.Extract(many, filepath.Join(root, "many"), file.ExtractOptions{MaxEntries: 2}); !errors.Is(err, file.ErrArchiveTooMany) {
+		t.Fatalf("expected entry limit error, got %v", err)
+	}
+}
+
+func writeTestZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	handle, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	defer handle.Close()
+	writer := zip.NewWriter(handle)
+	for name, content := range entries {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("write entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+}
+
+func TestExtractRefusesExistingSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+	outside := filepath.Join(root, "outside")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(target, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	archive := filepath.Join(root, "link.zip")
+	writeTestZip(t, archive, map[string]string{"link/owned.txt": "owned"})
+	if _, err := file.Extract(archive, target, file.ExtractOptions{}); !errors.Is(err, file.ErrUnsafeEntry) {
+		t.Fatalf("expected unsafe entry error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "owned.txt")); !os.IsNotExist(err) {
+		t.Fatalf("entry was written through the symlink: %v", err)
+	}
+}
+
+func TestExtractCountsSkippedEntries(t *testing.T) {
+	root := t.TempDir()
+	archive := filepath.Join(root, "links.tar")
+	writeSymlinkTar(t, archive, 150)
+
+	if _, err := file.Extract(archive, filepath.Join(root, "capped"), file.ExtractOptions{MaxEntries: 10}); !errors.Is(err, file.ErrArchiveTooMany) {
+		t.Fatalf("expected skipped entries to count towards the limit, got %v", err)
+	}
+	result, err := file.Extract(archive, filepath.Join(root, "target"), file.ExtractOptions{})
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if result.Entries != 150 || len(result.Skipped) != 100 || !result.SkippedTruncated {
+		t.Fatalf("unexpected result: entries %d, skipped %d, truncated %v", result.Entries, len(result.Skipped), result.SkippedTruncated)
+	}
+
+	oversized := filepath.Join(root, "oversized.tar")
+	writeSymlinkTar(t, oversized, file.DefaultExtractMaxEntries+1)
+	if _, err := file.Extract(oversized, filepath.Join(root, "raised"), file.ExtractOptions{MaxEntries: 1 << 30}); !errors.Is(err, file.ErrArchiveTooMany) {
+		t.Fatalf("expected the default entry limit to cap a raised one, got %v", err)
+	}
+}
+
+func writeSymlinkTar(t *testing.T, path string, count int) {
+	t.Helper()
+	handle, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create tar: %v", err)
+	}
+	defer handle.Close()
+	writer := tar.NewWriter(handle)
+	for i := 0; i < count; i++ {
+		header := &tar.Header{Name: "link-" + strconv.Itoa(i), Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("write header: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+}
+
+func TestExtractOverwritesOnlyWhenAsked(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+	existing := filepath.Join(target, "a.txt")
+	writeTestFile(t, existing, "original")
+	archive := filepath.Join(root, "a.zip")
+	writeTestZip(t, archive, map[string]string{"a.txt": "replaced"})
+
+	if _, err := file.Extract(archive, target, file.ExtractOptions{}); !errors.Is(err, file.ErrArchiveExists) {
+		t.Fatalf("expected existing file to be refused, got %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "original" {
+		t.Fatalf("existing file was changed: %q", data)
+	}
+
+	history := file.NewHistory(filepath.Join(root, "history"), 0)
+	if _, err := file.Extract(archive, target, file.ExtractOptions{Overwrite: true, History: history}); err != nil {
+		t.Fatalf("extract with overwrite: %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "replaced" {
+		t.Fatalf("file was not overwritten: %q", data)
+	}
+	versions, err := history.List(existing)
+	if err != nil || len(versions) != 1 || versions[0].Operation != "extract" {
+		t.Fatalf("expected the overwrite to be recorded: %+v, %v", versions, err)
+	}
+}
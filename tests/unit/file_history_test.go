@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/file"
+)
+
+func TestHistoryRecordsAndRestoresVersions(t *testing.T) {
+	root := t.TempDir()
+	history := file.NewHistory(filepath.Join(root, "history"), 3)
+	target := filepath.Join(root, "workspace", "notes.txt")
+
+	if err := history.Apply(target, "write", func() error { return file.Write(target, "first") }); err != nil {
+		t.Fatalf("write first: %v", err)
+	}
+	if err := history.Apply(target, "write", func() error { return file.Write(target, "second") }); err != nil {
+		t.Fatalf("write second: %v", err)
+	}
+
+	versions, err := history.List(target)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Version != 2 || !versions[0].Exists {
+		t.Fatalf("expected newest version first, got %+v", versions[0])
+	}
+
+	if _, err := history.Restore(target, 2); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	content, err := file.Read(target)
+	if err != nil {
+		t.Fatalf("read restored: %v", err)
+	}
+	if content != "first" {
+		t.Fatalf("expected restored content %q, got %q", "first", content)
+	}
+
+	if _, err := history.Restore(target, 1); err != nil {
+		t.Fatalf("restore missing: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("expected restoring an absent version to remove the file")
+	}
+
+	versions, err = history.List(target)
+	if err != nil {
+		t.Fatalf("list after restores: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected history to be capped at 3, got %d", len(versions))
+	}
+	if _, err := history.Restore(target, 1); err != file.ErrVersionNotFound {
+		t.Fatalf("expected pruned version to be gone, got %v", err)
+	}
+}
+
+func TestHistoryApplyRecordsOnlyChanges(t *testing.T) {
+	root := t.TempDir()
+	history := file.NewHistory(filepath.Join(root, "history"), 0)
+	target := filepath.Join(root, "run.sh")
+	if err := os.WriteFile(target, []byte("echo one"), 0755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := history.Apply(target, "write", func() error { return errors.New("disk full") }); err == nil {
+		t.Fatalf("expected the mutate error")
+	}
+	noop := history.Apply(target, "replace", func() error {
+		_, err := file.Replace(target, "missing", "x")
+		return err
+	})
+	if noop != nil {
+		t.Fatalf("apply: %v", noop)
+	}
+	if versions, _ := history.List(target); len(versions) != 0 {
+		t.Fatalf("failed or unchanged writes should not be recorded: %+v", versions)
+	}
+
+	if err := history.Apply(target, "write", func() error { return file.Write(target, "echo two") }); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	versions, _ := history.List(target)
+	if len(versions) != 1 || versions[0].Size != int64(len("echo one")) {
+		t.Fatalf("expected the prior content to be recorded: %+v", versions)
+	}
+	if err := os.Chmod(target, 0600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if _, err := history.Restore(target, versions[0].Version); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Fatalf("restore should bring back the original mode, got %v, %v", info.Mode(), err)
+	}
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"open-sandbox/internal/file"
+)
+
+func TestWatchReportsCreateRenameAndIgnoresGlobs(t *testing.T) {
+	root := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := file.Watch(ctx, root, file.WatchOptions{
+		Recursive: true,
+		Interval:  20 * time.Millisecond,
+		Debounce:  60 * time.Millisecond,
+		Ignore:    []string{"*.tmp"},
+	})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	target := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(target, []byte("hello"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "scratch.tmp"), []byte("x"), 0644); err != nil {
+		t.Fatalf("write ignored file: %v", err)
+	}
+	batch := nextWatchBatch(t, events)
+	if len(batch) != 1 || batch[0].Type != file.WatchCreate || batch[0].Path != target {
+		t.Fatalf("expected single create for %s, got %+v", target, batch)
+	}
+
+	renamed := filepath.Join(root, "renamed.txt")
+	if err := os.Rename(target, renamed); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	batch = nextWatchBatch(t, events)
+	if len(batch) != 1 || batch[0].Type != file.WatchRename {
+		t.Fatalf("expected rename event, got %+v", batch)
+	}
+	if batch[0].Path != renamed || batch[0].OldPath != target {
+		t.Fatalf("unexpected rename paths: %+v", batch[0])
+	}
+}
+
+func TestWatchRejectsInvalidIgnorePattern(t *testing.T) {
+	if _, err := file.Watch(context.Background(), t.TempDir(), file.WatchOptions{Ignore: []string{"["}}); err == nil {
+		t.Fatalf("expected invalid pattern to be rejected")
+	}
+}
+
+func nextWatchBatch(t *testing.T, events <-chan []file.WatchEvent) []file.WatchEvent {
+	t.Helper()
+	select {
+	case batch := <-events:
+		return batch
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for watch events")
+	}
+	return nil
+}
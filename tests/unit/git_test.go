@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/git"
+)
+
+func TestGitRepoWorkflow(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("SANDBOX_GIT_AUTHOR_NAME", "Sandbox Default")
+	t.Setenv("SANDBOX_GIT_AUTHOR_EMAIL", "default@sandbox.local")
+	ctx := context.Background()
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", "-b", "main", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	repo := git.Open(dir)
+
+	writeTestFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {}\n")
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Branch != "main" || len(status.Files) != 1 || !status.Files[0].Untracked {
+		t.Fatalf("unexpected initial status: %+v", status)
+	}
+
+	if err := repo.Add(ctx, []string{"main.go"}, false); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	commit, err := repo.Commit(ctx, git.CommitOptions{
+		Message: "initial",
+		Author:  git.Author{Name: "Agent One", Email: "agent1@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if commit.Subject != "initial" || commit.Author.Email != "agent1@example.com" {
+		t.Fatalf("unexpected commit: %+v", commit)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")
+	files, err := repo.Diff(ctx, git.DiffOptions{})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "main.go" || files[0].Status != "modified" {
+		t.Fatalf("unexpected diff files: %+v", files)
+	}
+	if len(files[0].Hunks) != 1 || files[0].Additions != 3 || files[0].Deletions != 1 {
+		t.Fatalf("unexpected hunks: %+v", files[0])
+	}
+	if hunk := files[0].Hunks[0]; hunk.OldStart != 1 || hunk.NewStart != 1 || hunk.NewLines != 5 {
+		t.Fatalf("unexpected hunk header: %+v", hunk)
+	}
+
+	stashes, err := repo.Stash(ctx, git.StashOptions{Message: "wip"})
+	if err != nil {
+		t.Fatalf("stash: %v", err)
+	}
+	if len(stashes) != 1 {
+		t.Fatalf("expected one stash, got %+v", stashes)
+	}
+	if status, err = repo.Status(ctx); err != nil || !status.Clean {
+		t.Fatalf("expected clean tree after stash: %+v %v", status, err)
+	}
+	if stashes, err = repo.Stash(ctx, git.StashOptions{Action: "pop"}); err != nil || len(stashes) != 0 {
+		t.Fatalf("stash pop: %+v %v", stashes, err)
+	}
+
+	if err := repo.Checkout(ctx, "feature", true); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	second, err := repo.Commit(ctx, git.CommitOptions{Message: "print", All: true})
+	if err != nil {
+		t.Fatalf("second commit: %v", err)
+	}
+	if second.Author.Name != "Sandbox Default" || len(second.Parents) != 1 || second.Parents[0] != commit.Hash {
+		t.Fatalf("unexpected second commit: %+v", second)
+	}
+
+	branches, err := repo.Branches(ctx)
+	if err != nil {
+		t.Fatalf("branches: %v", err)
+	}
+	if len(branches) != 2 {
+		t.Fatalf("expected two branches, got %+v", branches)
+	}
+	for _, branch := range branches {
+		if branch.Current != (branch.Name == "feature") {
+			t.Fatalf("unexpected current branch: %+v", branches)
+		}
+	}
+
+	commits, err := repo.Log(ctx, git.LogOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != second.Hash || commits[1].Hash != commit.Hash {
+		t.Fatalf("unexpected log: %+v", commits)
+	}
+
+	if err := repo.Checkout(ctx, "--orphan", false); err == nil {
+		t.Fatalf("expected option-like ref to be rejected")
+	}
+}
+
+func TestGitStaysInsideRoot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	outer := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", outer).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	workspace := filepath.Join(outer, "workspace")
+	writeTestFile(t, filepath.Join(workspace, "notes", "a.txt"), "a\n")
+
+	// The enclosing checkout must not be found from inside the workspace.
+	if _, err := git.OpenIn(filepath.Join(workspace, "notes"), workspace).Status(ctx); !errors.Is(err, git.ErrNotRepository) {
+		t.Fatalf("expected not a repository, got %v", err)
+	}
+	inner := filepath.Join(workspace, "project")
+	if output, err := exec.Command("git", "init", "-q", inner).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	writeTestFile(t, filepath.Join(inner, "src", "main.go"), "package main\n")
+	if _, err := git.OpenIn(filepath.Join(inner, "src"), workspace).Status(ctx); err != nil {
+		t.Fatalf("repository inside the root should open: %v", err)
+	}
+	if _, err := git.OpenIn(inner, filepath.Join(inner, "src")).Status(ctx); !errors.Is(err, git.ErrNotRepository) {
+		t.Fatalf("expected a repository above root to be refused, got %v", err)
+	}
+
+	for _, url := range []string{outer, "./project", "file://" + outer, "ext::sh -c id", "C:/repo"} {
+		_, err := git.Clone(ctx, git.CloneOptions{URL: url, Dir: filepath.Join(workspace, "clone")})
+		if !errors.Is(err, git.ErrInvalidInput) {
+			t.Fatalf("expected %q to be refused, got %v", url, err)
+		}
+	}
+}
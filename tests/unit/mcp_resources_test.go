@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"open-sandbox/internal/mcp"
+)
+
+func TestResourcesSubscribeUsesWatcher(t *testing.T) {
+	server := mcp.NewServer(mcp.NewRegistry(), nil, nil)
+
+	unsupported := server.HandleRequest(context.Background(), mcp.Request{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      json.RawMessage("1"),
+		Method:  mcp.MethodResourcesSubscribe,
+		Params:  json.RawMessage(`{"uri":"file:///tmp"}`),
+	})
+	if unsupported.Error == nil || unsupported.Error.Code != mcp.ErrMethodNotFound {
+		t.Fatalf("expected method not found without watcher, got %+v", unsupported.Error)
+	}
+
+	var watched []string
+	watches := map[string]context.Context{}
+	notifiers := map[string]func(mcp.ResourceUpdatedParams){}
+	server.SetResourceWatcher(func(ctx context.Context, uri string, notify func(mcp.ResourceUpdatedParams)) error {
+		if uri == "file:///bad" {
+			return errors.New("bad uri")
+		}
+		watched = append(watched, uri)
+		watches[uri] = ctx
+		notifiers[uri] = notify
+		notify(mcp.ResourceUpdatedParams{URI: uri})
+		return nil
+	})
+
+	initResp := server.HandleRequest(context.Background(), mcp.Request{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      json.RawMessage("2"),
+		Method:  mcp.MethodInitialize,
+	})
+	initResult, ok := initResp.Result.(mcp.InitializeResult)
+	if !ok || initResult.Capabilities.Resources == nil || !initResult.Capabilities.Resources.Subscribe {
+		t.Fatalf("expected resource subscribe capability, got %+v", initResp.Result)
+	}
+
+	// A one-shot request has nowhere to deliver updates.
+	oneShot := server.HandleRequest(context.Background(), mcp.Request{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      json.RawMessage("3"),
+		Method:  mcp.MethodResourcesSubscribe,
+		Params:  json.RawMessage(`{"uri":"file:///workspace/src"}`),
+	})
+	if oneShot.Error == nil || oneShot.Error.Code != mcp.ErrInvalidRequest || len(watched) != 0 {
+		t.Fatalf("expected subscribe without a session to be rejected, got %+v", oneShot.Error)
+	}
+
+	first := &bytes.Buffer{}
+	input := strings.NewReader(`{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{"uri":"file:///workspace/src"}}
+{"jsonrpc":"2.0","id":5,"method":"resources/subscribe","params":{"uri":"file:///bad"}}
+`)
+	if err := server.ServeStdio(input, first); err != nil {
+		t.Fatalf("serve stdio: %v", err)
+	}
+	if len(watched) != 1 || watched[0] != "file:///workspace/src" {
+		t.Fatalf("expected watcher to be invoked once, got %v", watched)
+	}
+	messages := decodeMessages(t, first)
+	if len(messages) != 3 || messages[0]["method"] != mcp.MethodResourceUpdated {
+		t.Fatalf("expected an update then two responses, got %v", messages)
+	}
+	if messages[1]["error"] != nil {
+		t.Fatalf("subscribe error: %v", messages[1]["error"])
+	}
+	if detail, _ := messages[2]["error"].(map[string]any); detail == nil || detail["code"] != float64(mcp.ErrInvalidParams) {
+		t.Fatalf("expected invalid params for rejected uri, got %v", messages[2])
+	}
+	if watches["file:///workspace/src"].Err() == nil {
+		t.Fatalf("subscription should end with its session")
+	}
+
+	// Another session subscribing to the same uri gets its own watch, and the
+	// closed session no longer receives updates.
+	second := &bytes.Buffer{}
+	input = strings.NewReader(`{"jsonrpc":"2.0","id":6,"method":"resources/subscribe","params":{"uri":"file:///workspace/src"}}
+`)
+	if err := server.ServeStdio(input, second); err != nil {
+		t.Fatalf("serve stdio: %v", err)
+	}
+	if len(watched) != 2 || len(decodeMessages(t, second)) != 2 {
+		t.Fatalf("expected a fresh watch for the second session, got %v", watched)
+	}
+	written := first.Len()
+	notifiers["file:///workspace/src"](mcp.ResourceUpdatedParams{URI: "file:///workspace/src"})
+	if first.Len() != written {
+		t.Fatalf("closed session received an update")
+	}
+}
+
+func decodeMessages(t *testing.T, output *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(output.Bytes()))
+	for decoder.More() {
+		var message map[string]any
+		if err := decoder.Decode(&message); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"open-sandbox/internal/snapshot"
+)
+
+func TestSnapshotCreateDiffRestoreDelete(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	store := snapshot.NewStore(filepath.Join(root, "snapshots"))
+
+	writeTestFile(t, filepath.Join(workspace, "keep.txt"), "keep")
+	writeTestFile(t, filepath.Join(workspace, "src", "main.go"), "package main")
+
+	first, err := store.Create(workspace, "before")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if first.Files != 2 || first.Label != "before" {
+		t.Fatalf("unexpected summary: %+v", first)
+	}
+
+	writeTestFile(t, filepath.Join(workspace, "src", "main.go"), "package broken")
+	writeTestFile(t, filepath.Join(workspace, "extra.txt"), "extra")
+	if err := os.Remove(filepath.Join(workspace, "keep.txt")); err != nil {
+		t.Fatalf("remove file: %v", err)
+	}
+
+	diff, err := store.Diff(workspace, first.ID, "")
+	if err != nil {
+		t.Fatalf("diff against workspace: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != "extra.txt" {
+		t.Fatalf("unexpected added: %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "keep.txt" {
+		t.Fatalf("unexpected removed: %v", diff.Removed)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0] != "src/main.go" {
+		t.Fatalf("unexpected modified: %v", diff.Modified)
+	}
+
+	second, err := store.Create(workspace, "after")
+	if err != nil {
+		t.Fatalf("create second snapshot: %v", err)
+	}
+	if _, err := store.Restore(workspace, first.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	assertFileContent(t, filepath.Join(workspace, "keep.txt"), "keep")
+	assertFileContent(t, filepath.Join(workspace, "src", "main.go"), "package main")
+	if _, err := os.Stat(filepath.Join(workspace, "extra.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected extra file to be removed by restore")
+	}
+
+	if err := store.Delete(second.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	snapshots, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != first.ID {
+		t.Fatalf("unexpected snapshots after delete: %+v", snapshots)
+	}
+	if _, err := store.Get(second.ID); err != snapshot.ErrNotFound {
+		t.Fatalf("expected deleted snapshot to be missing, got %v", err)
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(content) != want {
+		t.Fatalf("expected %s to contain %q, got %q", path, want, string(content))
+	}
+}